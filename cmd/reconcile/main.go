@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"wallets/internal/config"
+	"wallets/internal/fees"
+	"wallets/internal/lib/sl"
+	"wallets/internal/reconcile"
+	"wallets/internal/storage/postgres"
+	"wallets/internal/storage/redis_client"
+)
+
+const (
+	exitFailed   = 1
+	exitMismatch = 2
+)
+
+// reconcile сверяет балансы кошельков с транзакциями и кэшем Redis.
+// Код выхода: 0 - расхождений с транзакциями нет, 1 - сверка не выполнена или флаги заданы неверно,
+// 2 - найдены расхождения.
+func main() {
+	reportPath := flag.String("report", "", "путь к JSON отчету, по умолчанию stdout")
+	repairCache := flag.Bool("repair-cache", false, "удалить из Redis записи, расходящиеся с базой")
+	batchSize := flag.Int("batch", 500, "сколько кошельков пересчитывать за один запрос")
+	flag.Parse()
+
+	if *batchSize <= 0 {
+		fmt.Fprintf(flag.CommandLine.Output(), "invalid value %d for flag -batch: must be positive\n", *batchSize)
+		flag.Usage()
+		os.Exit(exitFailed)
+	}
+
+	cfg := config.MustLoad()
+
+	log := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	postgres, err := postgres.New(cfg.Storage, cfg.Limits, cfg.Owners, fees.Schedule{})
+	if err != nil {
+		log.Error("storage initialization failed", sl.Err(err))
+		os.Exit(exitFailed)
+	}
+
+	redisClient, err := redis_client.New(cfg.Redis)
+	if err != nil {
+		log.Error("redis initilization failed", sl.Err(err))
+		os.Exit(exitFailed)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	report, err := reconcile.Run(ctx, log, postgres, redisClient, reconcile.Options{
+		BatchSize:   *batchSize,
+		RepairCache: *repairCache,
+	})
+	if err != nil {
+		log.Error("reconciliation failed", sl.Err(err))
+		os.Exit(exitFailed)
+	}
+
+	if err := writeReport(*reportPath, report); err != nil {
+		log.Error("failed to write report", sl.Err(err))
+		os.Exit(exitFailed)
+	}
+
+	log.Info("reconciliation finished",
+		slog.Int("wallets", report.Wallets),
+		slog.Int("ledger_mismatches", report.LedgerMismatches),
+		slog.Int("cache_drifts", report.CacheDrifts),
+		slog.Int("cache_repaired", report.CacheRepaired),
+	)
+
+	if report.LedgerMismatches > 0 {
+		os.Exit(exitMismatch)
+	}
+}
+
+func writeReport(path string, report any) error {
+	out := os.Stdout
+
+	if path != "" {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		out = file
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "\t")
+
+	return encoder.Encode(report)
+}
@@ -6,18 +6,68 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync/atomic"
 	"syscall"
 	"time"
 	"wallets/internal/config"
+	"wallets/internal/fees"
+	"wallets/internal/http-server/handlers/admin/createkey"
+	"wallets/internal/http-server/handlers/admin/getlimits"
+	"wallets/internal/http-server/handlers/admin/ledgercheck"
+	"wallets/internal/http-server/handlers/admin/revokekey"
+	"wallets/internal/http-server/handlers/admin/rotatekey"
+	"wallets/internal/http-server/handlers/admin/setlimits"
+	"wallets/internal/http-server/handlers/admin/setoverdraft"
+	"wallets/internal/http-server/handlers/admin/setstatus"
+	"wallets/internal/http-server/handlers/admin/statushistory"
+	"wallets/internal/http-server/handlers/health/liveness"
+	"wallets/internal/http-server/handlers/health/readiness"
+	"wallets/internal/http-server/handlers/holds/capturehold"
+	"wallets/internal/http-server/handlers/holds/createhold"
+	"wallets/internal/http-server/handlers/holds/voidhold"
+	"wallets/internal/http-server/handlers/interest/listaccruals"
+	"wallets/internal/http-server/handlers/interest/listpayouts"
+	"wallets/internal/http-server/handlers/owners/listwallets"
+	"wallets/internal/http-server/handlers/schedules/createschedule"
+	"wallets/internal/http-server/handlers/schedules/listruns"
+	"wallets/internal/http-server/handlers/schedules/listschedules"
+	"wallets/internal/http-server/handlers/schedules/setschedulestatus"
+	"wallets/internal/http-server/handlers/transactions/reverse"
+	"wallets/internal/http-server/handlers/wallets/batch"
 	"wallets/internal/http-server/handlers/wallets/create"
 	"wallets/internal/http-server/handlers/wallets/getbalance"
+	"wallets/internal/http-server/handlers/wallets/listtransactions"
+	"wallets/internal/http-server/handlers/wallets/statement"
+	"wallets/internal/http-server/handlers/wallets/transfer"
 	"wallets/internal/http-server/handlers/wallets/updatebalance"
+	"wallets/internal/http-server/handlers/webhooks/createwebhook"
+	"wallets/internal/http-server/handlers/webhooks/deletewebhook"
+	"wallets/internal/http-server/handlers/webhooks/listdeliveries"
+	"wallets/internal/http-server/handlers/webhooks/redeliver"
+	"wallets/internal/http-server/middleware/auth"
+	"wallets/internal/http-server/middleware/metrics"
+	"wallets/internal/jobs/holds"
+	"wallets/internal/jobs/idempotency"
+	"wallets/internal/jobs/interest"
+	"wallets/internal/jobs/outbox"
+	"wallets/internal/jobs/schedules"
+	"wallets/internal/jobs/snapshots"
+	"wallets/internal/jobs/webhooks"
 	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/outbox/filesink"
+	"wallets/internal/outbox/redisstream"
+	"wallets/internal/outbox/webhooksink"
 	"wallets/internal/storage"
 	"wallets/internal/storage/postgres"
 	"wallets/internal/storage/redis_client"
+	"wallets/internal/tracing"
+	webhookclient "wallets/internal/webhooks"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 const (
@@ -35,7 +85,24 @@ func main() {
 
 	log.Debug("debug messages are enabled")
 
-	postgres, err := postgres.New(cfg.Storage)
+	shutdownTracing, err := tracing.New(context.Background(), cfg.Tracing)
+	if err != nil {
+		log.Error("tracing initialization failed", sl.Err(err))
+		os.Exit(1)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.Error("tracing shutdown failed", sl.Err(err))
+		}
+	}()
+
+	feeSchedule, err := fees.New(cfg.Fees)
+	if err != nil {
+		log.Error("invalid fee configuration", sl.Err(err))
+		os.Exit(1)
+	}
+
+	postgres, err := postgres.New(cfg.Storage, cfg.Limits, cfg.Owners, feeSchedule)
 	if err != nil {
 		log.Error("storage initialization failed", sl.Err(err))
 		os.Exit(1)
@@ -50,20 +117,152 @@ func main() {
 	storage := storage.NewStorage(postgres, redisClient)
 
 	ctx := context.Background()
+
+	jobsCtx, stopJobs := context.WithCancel(ctx)
+	defer stopJobs()
+
+	go idempotency.Run(jobsCtx, log, postgres, cfg.Idempotency)
+	go holds.Run(jobsCtx, log, storage, cfg.Holds.ExpireInterval)
+	go snapshots.Run(jobsCtx, log, postgres, cfg.Snapshots)
+	go schedules.Run(jobsCtx, log, postgres, storage, cfg.Schedules)
+	go interest.Run(jobsCtx, log, postgres, storage, cfg.Interest)
+
+	sinks := []outbox.Sink{webhooksink.New(postgres)}
+
+	if cfg.Outbox.RedisStream.Enabled {
+		sinks = append(sinks, redisstream.New(redisClient, cfg.Outbox.RedisStream))
+	}
+
+	if cfg.Outbox.File.Enabled {
+		fileSink, err := filesink.New(cfg.Outbox.File.Path)
+		if err != nil {
+			log.Error("outbox file sink initialization failed", sl.Err(err))
+			os.Exit(1)
+		}
+		defer fileSink.Close()
+
+		sinks = append(sinks, fileSink)
+	}
+
+	go outbox.Run(jobsCtx, log, postgres, sinks, cfg.Outbox)
+	go webhooks.Run(jobsCtx, log, postgres, webhookclient.NewClient(cfg.Webhooks.Timeout), cfg.Webhooks)
+
 	router := gin.New()
+	router.Use(otelgin.Middleware(cfg.Tracing.ServiceName, otelgin.WithFilter(func(r *http.Request) bool {
+		switch r.URL.Path {
+		case "/metrics", "/healthz", "/readyz":
+			return false
+		}
+		return true
+	})))
+	router.Use(metrics.New())
+
+	// Метрики отдаются без ключа API, как и принято для Prometheus: закрывайте порт сетью, а не auth
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	// shuttingDown переводит /readyz в not ready с начала остановки, до srv.Shutdown
+	var shuttingDown atomic.Bool
+
+	router.GET("/healthz", liveness.New())
+	router.GET("/readyz", readiness.New(ctx, log, &shuttingDown, cfg.Health.CheckTimeout,
+		readiness.Dependency{Name: "postgres", Pinger: postgres},
+		readiness.Dependency{Name: "redis", Pinger: redisClient},
+	))
+
+	holdWallet := func(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+		hold, err := postgres.GetHold(ctx, id)
+		return hold.WalletID, err
+	}
+
+	transactionWallet := func(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+		transaction, err := postgres.GetTransaction(ctx, id)
+		return transaction.WalletID, err
+	}
 
-	api := router.Group("/api/v1")
+	scheduleWallet := func(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+		schedule, err := postgres.GetSchedule(ctx, id)
+		return schedule.WalletID, err
+	}
+
+	// У глобальной подписки кошелька нет: uuid.Nil не пропустит ключ с ограничением по кошелькам
+	webhookWallet := func(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+		subscription, err := postgres.GetWebhook(ctx, id)
+		return subscription.WalletID.UUID, err
+	}
+
+	read := auth.RequireScope(models.SCOPE_WALLET_READ)
+	write := auth.RequireScope(models.SCOPE_WALLET_WRITE)
+
+	api := router.Group("/api/v1", auth.New(ctx, log, postgres, cfg.Auth))
 	{
 		wallet := api.Group("/wallet")
 		{
-			wallet.POST("", updatebalance.New(ctx, log, storage))
-			wallet.POST("/create", create.New(ctx, log, storage.DB))
+			wallet.POST("", write, updatebalance.New(ctx, log, storage))
+			wallet.POST("/create", auth.RequireScope(models.SCOPE_WALLET_CREATE), create.New(ctx, log, storage.DB))
+			wallet.POST("/transfer", write, transfer.New(ctx, log, storage))
+			wallet.POST("/batch", write, batch.New(ctx, log, storage, cfg.Batch.MaxItems))
 
 		}
 
 		wallets := api.Group("/wallets")
 		{
-			wallets.GET("/:uuid", getbalance.New(ctx, log, storage))
+			wallets.GET("/:uuid", read, getbalance.New(ctx, log, storage))
+			wallets.GET("/:uuid/transactions", read, listtransactions.New(ctx, log, storage.DB))
+			wallets.GET("/:uuid/statement", read, statement.New(ctx, log, storage.DB, cfg.HTTPServer.StatementTimeout))
+			wallets.POST("/:uuid/holds", write, createhold.New(ctx, log, storage, cfg.Holds.DefaultTTL))
+			wallets.GET("/:uuid/schedules", read, listschedules.New(ctx, log, storage.DB))
+			wallets.GET("/:uuid/interest/payouts", read, listpayouts.New(ctx, log, storage.DB))
+			wallets.GET("/:uuid/interest/accruals", read, listaccruals.New(ctx, log, storage.DB))
+		}
+
+		owners := api.Group("/owners")
+		{
+			owners.GET("/:owner_id/wallets", read, listwallets.New(ctx, log, storage.DB))
+		}
+
+		hold := api.Group("/holds", write, auth.RequireWallet(log, holdWallet))
+		{
+			hold.POST("/:id/capture", capturehold.New(ctx, log, storage))
+			hold.POST("/:id/void", voidhold.New(ctx, log, storage))
+		}
+
+		transactions := api.Group("/transactions", write, auth.RequireWallet(log, transactionWallet))
+		{
+			transactions.POST("/:id/reverse", reverse.New(ctx, log, storage))
+		}
+
+		schedule := api.Group("/schedules")
+		{
+			scheduleOwner := auth.RequireWallet(log, scheduleWallet)
+
+			schedule.POST("", write, createschedule.New(ctx, log, storage.DB))
+			schedule.GET("/:id/runs", read, scheduleOwner, listruns.New(ctx, log, storage.DB))
+			schedule.POST("/:id/pause", write, scheduleOwner, setschedulestatus.New(ctx, log, storage.DB, models.SCHEDULE_PAUSED))
+			schedule.POST("/:id/resume", write, scheduleOwner, setschedulestatus.New(ctx, log, storage.DB, models.SCHEDULE_ACTIVE))
+			schedule.DELETE("/:id", write, scheduleOwner, setschedulestatus.New(ctx, log, storage.DB, models.SCHEDULE_CANCELLED))
+		}
+
+		webhook := api.Group("/webhooks", auth.RequireScope(models.SCOPE_WEBHOOKS))
+		{
+			webhookOwner := auth.RequireWallet(log, webhookWallet)
+
+			webhook.POST("", createwebhook.New(ctx, log, storage.DB))
+			webhook.DELETE("/:id", webhookOwner, deletewebhook.New(ctx, log, storage.DB))
+			webhook.GET("/:id/deliveries", webhookOwner, listdeliveries.New(ctx, log, storage.DB))
+			webhook.POST("/:id/deliveries/:delivery_id/redeliver", webhookOwner, redeliver.New(ctx, log, storage.DB))
+		}
+
+		admin := api.Group("/admin", auth.RequireScope(models.SCOPE_ADMIN))
+		{
+			admin.POST("/wallets/:uuid/status", setstatus.New(ctx, log, storage))
+			admin.GET("/wallets/:uuid/status", statushistory.New(ctx, log, storage.DB))
+			admin.GET("/wallets/:uuid/limits", getlimits.New(ctx, log, storage.DB))
+			admin.PUT("/wallets/:uuid/limits", setlimits.New(ctx, log, storage.DB))
+			admin.PUT("/wallets/:uuid/overdraft", setoverdraft.New(ctx, log, storage))
+			admin.GET("/ledger", ledgercheck.New(ctx, log, storage.DB))
+			admin.POST("/keys", createkey.New(ctx, log, storage.DB))
+			admin.POST("/keys/:id/rotate", rotatekey.New(ctx, log, storage.DB))
+			admin.DELETE("/keys/:id", revokekey.New(ctx, log, storage.DB))
 		}
 	}
 
@@ -92,6 +291,13 @@ func main() {
 	<-done
 	log.Info("server is shutting down...")
 
+	shuttingDown.Store(true)
+
+	stopJobs()
+
+	// оркестратор должен увидеть not ready и снять трафик, пока сервер еще принимает запросы
+	time.Sleep(cfg.Health.ShutdownDelay)
+
 	shutdownCtx, shutdownCancel := context.WithTimeout(ctx, 30*time.Second)
 	defer shutdownCancel()
 
@@ -109,16 +315,16 @@ func initLogger(env string) *slog.Logger {
 	switch env {
 	case envLocal:
 		log = slog.New(
-			slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+			tracing.NewLogHandler(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
 				Level: slog.LevelDebug,
-			}),
+			})),
 		)
 
 	case envProd:
 		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+			tracing.NewLogHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 				Level: slog.LevelInfo,
-			}),
+			})),
 		)
 
 	}
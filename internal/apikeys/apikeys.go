@@ -0,0 +1,29 @@
+// Package apikeys выпускает ключи доступа к API и считает их хэши для хранения
+package apikeys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+const (
+	keyPrefix = "wk_"
+	keyBytes  = 32
+)
+
+// Generate создает новый ключ. В открытом виде ключ отдается клиенту один раз, в базе хранится Hash.
+func Generate() (string, error) {
+	buf := make([]byte, keyBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return keyPrefix + hex.EncodeToString(buf), nil
+}
+
+// Hash возвращает SHA-256 ключа в hex. У ключа 256 бит случайности, поэтому медленный хэш с солью не нужен.
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -13,10 +13,24 @@ import (
 const envFile = "./config.env"
 
 type Config struct {
-	Env        string `yaml:"env" env-required:"true"`
-	Storage    `yaml:"db"`
-	HTTPServer `yaml:"http_server"`
-	Redis      `yaml:"redis"`
+	Env         string `yaml:"env" env-required:"true"`
+	Storage     `yaml:"db"`
+	HTTPServer  `yaml:"http_server"`
+	Redis       `yaml:"redis"`
+	Idempotency `yaml:"idempotency"`
+	Holds       `yaml:"holds"`
+	Limits      `yaml:"limits"`
+	Outbox      `yaml:"outbox"`
+	Webhooks    `yaml:"webhooks"`
+	Snapshots   `yaml:"snapshots"`
+	Auth        `yaml:"auth"`
+	Owners      `yaml:"owners"`
+	Batch       `yaml:"batch"`
+	Schedules   `yaml:"schedules"`
+	Fees        `yaml:"fees"`
+	Interest    `yaml:"interest"`
+	Tracing     `yaml:"tracing"`
+	Health      `yaml:"health"`
 }
 
 type Storage struct {
@@ -35,10 +49,151 @@ type Redis struct {
 	DB       int    `yaml:"db" env-default:"0"`
 }
 
+type Idempotency struct {
+	TTL             time.Duration `yaml:"ttl" env-default:"24h"`
+	CleanupInterval time.Duration `yaml:"cleanup_interval" env-default:"1h"`
+}
+
+type Holds struct {
+	DefaultTTL     time.Duration `yaml:"default_ttl" env-default:"168h"`
+	ExpireInterval time.Duration `yaml:"expire_interval" env-default:"1m"`
+}
+
+// Limits - лимиты кошелька по умолчанию в минорных единицах, 0 - без ограничения
+type Limits struct {
+	MaxWithdrawal     int64 `yaml:"max_withdrawal" env-default:"0"`
+	DailyWithdrawal   int64 `yaml:"daily_withdrawal" env-default:"0"`
+	MonthlyWithdrawal int64 `yaml:"monthly_withdrawal" env-default:"0"`
+	MaxBalance        int64 `yaml:"max_balance" env-default:"0"`
+}
+
+// Outbox - доставка событий из таблицы outbox во внешние приемники
+type Outbox struct {
+	PollInterval time.Duration     `yaml:"poll_interval" env-default:"1s"`
+	BatchSize    int               `yaml:"batch_size" env-default:"100"`
+	RedisStream  OutboxRedisStream `yaml:"redis_stream"`
+	File         OutboxFile        `yaml:"file"`
+}
+
+type OutboxRedisStream struct {
+	Enabled bool   `yaml:"enabled" env-default:"true"`
+	Stream  string `yaml:"stream" env-default:"wallet-events"`
+	// MaxLen - приблизительная длина стрима, старые записи вытесняются
+	MaxLen int64 `yaml:"max_len" env-default:"100000"`
+}
+
+type OutboxFile struct {
+	Enabled bool   `yaml:"enabled" env-default:"false"`
+	Path    string `yaml:"path" env-default:"./events.jsonl"`
+}
+
+// Webhooks - отправка событий подписчикам вебхуков
+type Webhooks struct {
+	PollInterval time.Duration `yaml:"poll_interval" env-default:"1s"`
+	BatchSize    int           `yaml:"batch_size" env-default:"20"`
+	// Timeout - таймаут одного запроса к подписчику
+	Timeout     time.Duration `yaml:"timeout" env-default:"5s"`
+	MaxAttempts int           `yaml:"max_attempts" env-default:"10"`
+	BaseBackoff time.Duration `yaml:"base_backoff" env-default:"10s"`
+	MaxBackoff  time.Duration `yaml:"max_backoff" env-default:"1h"`
+}
+
+// Snapshots - суточные снимки балансов для запросов баланса на дату
+type Snapshots struct {
+	Interval time.Duration `yaml:"interval" env-default:"1h"`
+	// Lag - сколько ждать после полуночи, прежде чем снимать баланс на ее момент
+	Lag time.Duration `yaml:"lag" env-default:"5m"`
+}
+
+// Auth - проверка ключей API. BootstrapKey дает права admin и нужен, чтобы выпустить первые ключи.
+type Auth struct {
+	Enabled      bool   `yaml:"enabled" env-default:"true"`
+	BootstrapKey string `env:"AUTH_BOOTSTRAP_KEY" env-default:""`
+}
+
+// Owners - правила для кошельков владельцев
+type Owners struct {
+	OneWalletPerCurrency bool `yaml:"one_wallet_per_currency" env-default:"false"`
+}
+
+// Batch - пакетные операции над балансами
+type Batch struct {
+	MaxItems int `yaml:"max_items" env-default:"1000"`
+}
+
+// Schedules - выполнение отложенных и регулярных операций
+type Schedules struct {
+	PollInterval time.Duration `yaml:"poll_interval" env-default:"10s"`
+	BatchSize    int           `yaml:"batch_size" env-default:"20"`
+	// Lease - на сколько запуск закрепляется за экземпляром сервиса, после чего его может забрать другой
+	Lease time.Duration `yaml:"lease" env-default:"1m"`
+}
+
+// Fees - комиссии за списания и переводы по типу кошелька, суммы в минорных единицах.
+// Комиссия зачисляется на кошелек доходов в валюте операции.
+type Fees struct {
+	RevenueWallets map[string]string   `yaml:"revenue_wallets"`
+	Rules          map[string]FeeRules `yaml:"rules"`
+}
+
+type FeeRules struct {
+	Withdraw FeeRule `yaml:"withdraw"`
+	Transfer FeeRule `yaml:"transfer"`
+}
+
+// FeeRule - фиксированная часть плюс basis_points сотых долей процента, либо ступени tiers.
+// max = 0 - без верхнего ограничения.
+type FeeRule struct {
+	Fixed       int64     `yaml:"fixed"`
+	BasisPoints int64     `yaml:"basis_points"`
+	Tiers       []FeeTier `yaml:"tiers"`
+	Min         int64     `yaml:"min"`
+	Max         int64     `yaml:"max"`
+}
+
+// FeeTier - ступень тарифа для сумм до up_to включительно, up_to = 0 - без верхней границы
+type FeeTier struct {
+	UpTo        int64 `yaml:"up_to"`
+	Fixed       int64 `yaml:"fixed"`
+	BasisPoints int64 `yaml:"basis_points"`
+}
+
+// Interest - проценты на остаток: начисляются за каждые сутки по остатку на их конец, выплачиваются раз в месяц.
+// AnnualRates - годовые ставки в сотых долях процента по типу кошелька, кошельки других типов процентов не получают.
+type Interest struct {
+	AnnualRates  map[string]int64 `yaml:"annual_rates"`
+	PollInterval time.Duration    `yaml:"poll_interval" env-default:"1h"`
+	// Lag - сколько ждать после полуночи, прежде чем начислять проценты за закончившиеся сутки
+	Lag time.Duration `yaml:"lag" env-default:"5m"`
+	// LookbackDays - за сколько последних суток догонять начисления после простоя
+	LookbackDays int `yaml:"lookback_days" env-default:"3"`
+	BatchSize    int `yaml:"batch_size" env-default:"100"`
+}
+
+// Tracing - экспорт спанов OpenTelemetry. Exporter: otlp (OTLP/HTTP на Endpoint), stdout или none.
+// SampleRatio - доля трейсов, которые начинаются в сервисе; входящий traceparent решает сам.
+type Tracing struct {
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER" env-default:"none"`
+	Endpoint    string  `yaml:"endpoint" env:"TRACING_ENDPOINT" env-default:"localhost:4318"`
+	Insecure    bool    `yaml:"insecure" env-default:"false"`
+	ServiceName string  `yaml:"service_name" env-default:"wallets"`
+	SampleRatio float64 `yaml:"sample_ratio" env-default:"1"`
+}
+
+// Health - проверки живости и готовности для оркестратора
+type Health struct {
+	// CheckTimeout - таймаут проверки одной зависимости в /readyz
+	CheckTimeout time.Duration `yaml:"check_timeout" env-default:"1s"`
+	// ShutdownDelay - сколько отвечать not ready перед остановкой сервера, чтобы оркестратор успел снять трафик
+	ShutdownDelay time.Duration `yaml:"shutdown_delay" env-default:"5s"`
+}
+
 type HTTPServer struct {
 	Address      string        `yaml:"address" env-default:"localhost:8080"`
 	Timeout      time.Duration `yaml:"timeout" env-default:"4s"`
 	Idle_timeout time.Duration `yaml:"idle_timeout" env-default:"60s"`
+	// StatementTimeout - срок записи ответа для выписки, которая отдается потоком дольше Timeout
+	StatementTimeout time.Duration `yaml:"statement_timeout" env-default:"10m"`
 }
 
 func MustLoad() *Config {
@@ -0,0 +1,126 @@
+package fees
+
+import (
+	"fmt"
+	"wallets/internal/config"
+	"wallets/internal/models"
+
+	"github.com/gofrs/uuid"
+)
+
+// Tier - ступень тарифа для сумм до UpTo включительно, UpTo = 0 - без верхней границы
+type Tier struct {
+	UpTo        int64
+	Fixed       int64
+	BasisPoints int64
+}
+
+// Rule - комиссия за операцию в минорных единицах: Fixed плюс BasisPoints сотых долей процента от суммы.
+// Если заданы Tiers, Fixed и BasisPoints берутся из первой ступени, в которую попадает сумма.
+// Результат ограничивается снизу Min и сверху Max, Max = 0 - без ограничения.
+type Rule struct {
+	Fixed       int64
+	BasisPoints int64
+	Tiers       []Tier
+	Min         int64
+	Max         int64
+}
+
+// Calculate возвращает комиссию за операцию на amount
+func (r Rule) Calculate(amount int64) int64 {
+	fixed, bps := r.Fixed, r.BasisPoints
+
+	for _, tier := range r.Tiers {
+		if tier.UpTo == 0 || amount <= tier.UpTo {
+			fixed, bps = tier.Fixed, tier.BasisPoints
+			break
+		}
+	}
+
+	fee := fixed + percentOf(amount, bps)
+
+	if fee < r.Min {
+		fee = r.Min
+	}
+
+	if r.Max > 0 && fee > r.Max {
+		fee = r.Max
+	}
+
+	return fee
+}
+
+// percentOf считает amount * bps / 10000 с округлением половины вверх, не переполняясь на больших суммах
+func percentOf(amount, bps int64) int64 {
+	return amount/10000*bps + (amount%10000*bps+5000)/10000
+}
+
+// Schedule - тарифы по типу кошелька и операции и кошельки доходов, на которые зачисляются комиссии
+type Schedule struct {
+	rules          map[string]map[models.OperationType]Rule
+	revenueWallets map[string]uuid.UUID
+}
+
+// New собирает тарифы из конфигурации
+func New(cfg config.Fees) (Schedule, error) {
+	const op = "fees.New"
+
+	schedule := Schedule{
+		rules:          make(map[string]map[models.OperationType]Rule, len(cfg.Rules)),
+		revenueWallets: make(map[string]uuid.UUID, len(cfg.RevenueWallets)),
+	}
+
+	for currency, id := range cfg.RevenueWallets {
+		walletID, err := uuid.FromString(id)
+		if err != nil {
+			return Schedule{}, fmt.Errorf("%s: revenue wallet for %s: %w", op, currency, err)
+		}
+		schedule.revenueWallets[currency] = walletID
+	}
+
+	for walletType, rules := range cfg.Rules {
+		schedule.rules[walletType] = map[models.OperationType]Rule{
+			models.WITHDRAW: ruleFrom(rules.Withdraw),
+			models.TRANSFER: ruleFrom(rules.Transfer),
+		}
+	}
+
+	return schedule, nil
+}
+
+// Fee возвращает комиссию за операцию с кошелька типа walletType. Для TRANSFER действует тариф кошелька списания.
+func (s Schedule) Fee(walletType string, operationType models.OperationType, amount int64) int64 {
+	return s.rules[walletType][operationType].Calculate(amount)
+}
+
+// RevenueWallet возвращает кошелек доходов для комиссий в валюте
+func (s Schedule) RevenueWallet(currency string) (uuid.UUID, bool) {
+	id, ok := s.revenueWallets[currency]
+	return id, ok
+}
+
+// IsRevenueWallet сообщает, что walletID - кошелек доходов для какой-либо валюты
+func (s Schedule) IsRevenueWallet(walletID uuid.UUID) bool {
+	for _, id := range s.revenueWallets {
+		if id == walletID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func ruleFrom(cfg config.FeeRule) Rule {
+	rule := Rule{
+		Fixed:       cfg.Fixed,
+		BasisPoints: cfg.BasisPoints,
+		Min:         cfg.Min,
+		Max:         cfg.Max,
+	}
+
+	for _, tier := range cfg.Tiers {
+		rule.Tiers = append(rule.Tiers, Tier(tier))
+	}
+
+	return rule
+}
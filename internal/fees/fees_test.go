@@ -0,0 +1,135 @@
+package fees
+
+import (
+	"testing"
+	"wallets/internal/config"
+	"wallets/internal/models"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleCalculate(t *testing.T) {
+	tiered := Rule{
+		Tiers: []Tier{
+			{UpTo: 10000, Fixed: 30},
+			{UpTo: 100000, Fixed: 10, BasisPoints: 100},
+			{BasisPoints: 50},
+		},
+		Max: 2000,
+	}
+
+	tests := []struct {
+		name     string
+		rule     Rule
+		amount   int64
+		expected int64
+	}{
+		{
+			name:     "no fee",
+			rule:     Rule{},
+			amount:   10000,
+			expected: 0,
+		},
+		{
+			name:     "fixed",
+			rule:     Rule{Fixed: 25},
+			amount:   10000,
+			expected: 25,
+		},
+		{
+			name:     "percentage",
+			rule:     Rule{BasisPoints: 150},
+			amount:   10000,
+			expected: 150,
+		},
+		{
+			name:     "percentage rounds half up",
+			rule:     Rule{BasisPoints: 150},
+			amount:   1001,
+			expected: 15,
+		},
+		{
+			name:     "fixed plus percentage",
+			rule:     Rule{Fixed: 10, BasisPoints: 100},
+			amount:   5000,
+			expected: 60,
+		},
+		{
+			name:     "minimum",
+			rule:     Rule{BasisPoints: 100, Min: 50},
+			amount:   1000,
+			expected: 50,
+		},
+		{
+			name:     "maximum",
+			rule:     Rule{BasisPoints: 100, Max: 500},
+			amount:   1000000,
+			expected: 500,
+		},
+		{
+			name:     "first tier",
+			rule:     tiered,
+			amount:   10000,
+			expected: 30,
+		},
+		{
+			name:     "middle tier",
+			rule:     tiered,
+			amount:   50000,
+			expected: 510,
+		},
+		{
+			name:     "open tier capped",
+			rule:     tiered,
+			amount:   1000000,
+			expected: 2000,
+		},
+		{
+			name:     "large amount does not overflow",
+			rule:     Rule{BasisPoints: 10000},
+			amount:   9_000_000_000_000_000_000,
+			expected: 9_000_000_000_000_000_000,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.rule.Calculate(tc.amount))
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	revenueUUID, _ := uuid.NewV4()
+
+	schedule, err := New(config.Fees{
+		RevenueWallets: map[string]string{"USD": revenueUUID.String()},
+		Rules: map[string]config.FeeRules{
+			"standard": {
+				Withdraw: config.FeeRule{Fixed: 100},
+				Transfer: config.FeeRule{Tiers: []config.FeeTier{{UpTo: 0, BasisPoints: 100}}},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(100), schedule.Fee("standard", models.WITHDRAW, 5000))
+	assert.Equal(t, int64(50), schedule.Fee("standard", models.TRANSFER, 5000))
+	assert.Equal(t, int64(0), schedule.Fee("standard", models.DEPOSIT, 5000))
+	assert.Equal(t, int64(0), schedule.Fee("merchant", models.WITHDRAW, 5000))
+
+	revenue, ok := schedule.RevenueWallet("USD")
+	assert.True(t, ok)
+	assert.Equal(t, revenueUUID, revenue)
+
+	_, ok = schedule.RevenueWallet("EUR")
+	assert.False(t, ok)
+
+	assert.True(t, schedule.IsRevenueWallet(revenueUUID))
+	assert.False(t, schedule.IsRevenueWallet(uuid.Nil))
+
+	_, err = New(config.Fees{RevenueWallets: map[string]string{"USD": "not-a-uuid"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,5 @@
+package herrors
+
+import "errors"
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
@@ -6,4 +6,5 @@ var (
 	ErrInsufficientFunds = errors.New("insufficient funds")
 	ErrUnknownOperation  = errors.New("unknown operation")
 	ErrLockedWallet      = errors.New("locked wallet")
+	ErrSameWallet        = errors.New("source and destination wallets are the same")
 )
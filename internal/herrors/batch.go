@@ -0,0 +1,18 @@
+package herrors
+
+import "fmt"
+
+// BatchItemError сообщает, на какой операции пакета он был отклонен.
+// errors.Is и errors.As видят исходную ошибку операции.
+type BatchItemError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchItemError) Error() string {
+	return fmt.Sprintf("batch item %d: %s", e.Index, e.Err)
+}
+
+func (e *BatchItemError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,7 @@
+package herrors
+
+import "errors"
+
+var (
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+)
@@ -0,0 +1,7 @@
+package herrors
+
+import "errors"
+
+var (
+	ErrNoRevenueWallet = errors.New("revenue wallet for fee currency is not available")
+)
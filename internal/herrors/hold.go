@@ -0,0 +1,9 @@
+package herrors
+
+import "errors"
+
+var (
+	ErrHoldNotFound       = errors.New("hold is not exist")
+	ErrHoldNotActive      = errors.New("hold is not active")
+	ErrCaptureExceedsHold = errors.New("capture amount exceeds hold amount")
+)
@@ -0,0 +1,7 @@
+package herrors
+
+import "errors"
+
+var (
+	ErrIdempotencyConflict = errors.New("idempotency key is already used with a different request")
+)
@@ -0,0 +1,7 @@
+package herrors
+
+import "errors"
+
+var (
+	ErrNoInterestDue = errors.New("no interest due for period")
+)
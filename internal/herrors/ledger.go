@@ -0,0 +1,5 @@
+package herrors
+
+import "errors"
+
+var ErrNoLedgerPosting = errors.New("operation has no ledger posting")
@@ -0,0 +1,27 @@
+package herrors
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrLimitExceeded = errors.New("limit exceeded")
+	// ErrRevenueWalletMaxBalance - кошельки доходов не ограничиваются max_balance
+	ErrRevenueWalletMaxBalance = errors.New("max_balance cannot be set for a fee revenue wallet")
+)
+
+// LimitError сообщает, какой именно лимит кошелька был превышен.
+// errors.Is(err, ErrLimitExceeded) для него возвращает true.
+type LimitError struct {
+	Limit string
+	Value int64
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s: %s (%d)", ErrLimitExceeded, e.Limit, e.Value)
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
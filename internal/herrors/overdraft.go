@@ -0,0 +1,7 @@
+package herrors
+
+import "errors"
+
+var (
+	ErrOverdraftInUse = errors.New("overdraft limit is lower than the used credit")
+)
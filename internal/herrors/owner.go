@@ -0,0 +1,5 @@
+package herrors
+
+import "errors"
+
+var ErrOwnerWalletExists = errors.New("owner already has a wallet in this currency")
@@ -0,0 +1,10 @@
+package herrors
+
+import "errors"
+
+var (
+	ErrTransactionNotFound   = errors.New("transaction is not exist")
+	ErrNotReversible         = errors.New("operation type can not be reversed")
+	ErrReversalExceedsAmount = errors.New("reversal exceeds original amount")
+	ErrFundsAlreadySpent     = errors.New("deposited funds are already spent")
+)
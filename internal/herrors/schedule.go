@@ -0,0 +1,8 @@
+package herrors
+
+import "errors"
+
+var (
+	ErrScheduleNotFound          = errors.New("schedule not found")
+	ErrInvalidScheduleTransition = errors.New("invalid schedule status transition")
+)
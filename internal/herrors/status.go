@@ -0,0 +1,10 @@
+package herrors
+
+import "errors"
+
+var (
+	ErrWalletFrozen            = errors.New("wallet is frozen")
+	ErrWalletClosed            = errors.New("wallet is closed")
+	ErrWalletNotEmpty          = errors.New("wallet balance is not zero")
+	ErrInvalidStatusTransition = errors.New("invalid wallet status transition")
+)
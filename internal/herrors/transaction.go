@@ -0,0 +1,7 @@
+package herrors
+
+import "errors"
+
+var (
+	ErrDuplicateExternalReference = errors.New("external reference is already used for this wallet")
+)
@@ -0,0 +1,8 @@
+package herrors
+
+import "errors"
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook subscription not found")
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+)
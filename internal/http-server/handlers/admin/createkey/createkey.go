@@ -0,0 +1,92 @@
+package createkey
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"wallets/internal/apikeys"
+	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/lib/errtranslate"
+	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofrs/uuid"
+)
+
+// Request - без wallet_ids ключ работает со всеми кошельками
+type Request struct {
+	Name      string         `json:"name" binding:"required"`
+	Scopes    []models.Scope `json:"scopes" binding:"required,min=1,dive,oneof=wallet:read wallet:write wallet:create webhooks:manage admin"`
+	WalletIDs []uuid.UUID    `json:"wallet_ids"`
+}
+
+type Response struct {
+	resp.Response
+	APIKey models.APIKey `json:"api_key"`
+}
+
+type keyCreator interface {
+	CreateAPIKey(ctx context.Context, name string, scopes []models.Scope, walletIDs []uuid.UUID, keyHash string) (models.APIKey, error)
+}
+
+func New(ctx context.Context, log *slog.Logger, repos keyCreator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "handlers.admin.createkey.New"
+
+		log := log.With(slog.String("op", op), tracing.TraceID(c.Request.Context()))
+
+		var req Request
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+
+			if validationErrs, ok := err.(validator.ValidationErrors); ok {
+				fieldErrors := errtranslate.TranslateValidationErrors(validationErrs)
+				msg := strings.Join(fieldErrors, ", ")
+				c.JSON(http.StatusBadRequest, resp.Error(msg))
+				return
+			}
+
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		// Администрирование не привязано к кошельку, ограничение ключа его бы не сдержало.
+		// Подписки на вебхуки ограниченного ключа проверяются по кошельку подписки.
+		if len(req.WalletIDs) > 0 {
+			for _, scope := range req.Scopes {
+				if scope == models.SCOPE_ADMIN {
+					c.JSON(http.StatusBadRequest, resp.Error("keys restricted to wallets may not have admin scope"))
+					return
+				}
+			}
+		}
+
+		key, err := apikeys.Generate()
+		if err != nil {
+			log.Error("failed to generate api key", sl.Err(err))
+			c.JSON(http.StatusInternalServerError, resp.Error("failed to create api key"))
+			return
+		}
+
+		apiKey, err := repos.CreateAPIKey(c.Request.Context(), req.Name, req.Scopes, req.WalletIDs, apikeys.Hash(key))
+		if err != nil {
+			log.Error("failed to create api key", sl.Err(err))
+			c.JSON(http.StatusInternalServerError, resp.Error("failed to create api key"))
+			return
+		}
+
+		log.Info("api key created", slog.String("key_id", apiKey.ID.String()))
+
+		apiKey.Key = key
+
+		c.JSON(http.StatusCreated, Response{
+			Response: resp.OK(),
+			APIKey:   apiKey,
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package createkey
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallets/internal/apikeys"
+	"wallets/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockKeyCreator struct {
+	mock.Mock
+}
+
+func (m *mockKeyCreator) CreateAPIKey(ctx context.Context, name string, scopes []models.Scope, walletIDs []uuid.UUID, keyHash string) (models.APIKey, error) {
+	args := m.Called(ctx, name, scopes, walletIDs, keyHash)
+	return args.Get(0).(models.APIKey), args.Error(1)
+}
+
+func TestNew(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	keyID, _ := uuid.NewV4()
+	walletID, _ := uuid.NewV4()
+
+	readWrite := []models.Scope{models.SCOPE_WALLET_READ, models.SCOPE_WALLET_WRITE}
+
+	tests := []struct {
+		name           string
+		body           string
+		callRepo       bool
+		mockScopes     []models.Scope
+		mockWalletIDs  []uuid.UUID
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Success",
+			body:           `{"name":"billing","scopes":["wallet:read","wallet:write"]}`,
+			callRepo:       true,
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"key":"wk_`,
+		},
+		{
+			name:           "restricted to wallets",
+			body:           `{"name":"billing","scopes":["wallet:read","wallet:write"],"wallet_ids":["` + walletID.String() + `"]}`,
+			callRepo:       true,
+			mockWalletIDs:  []uuid.UUID{walletID},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"wallet_ids":["` + walletID.String() + `"]`,
+		},
+		{
+			name:           "restricted webhooks key",
+			body:           `{"name":"billing","scopes":["webhooks:manage"],"wallet_ids":["` + walletID.String() + `"]}`,
+			callRepo:       true,
+			mockScopes:     []models.Scope{models.SCOPE_WEBHOOKS},
+			mockWalletIDs:  []uuid.UUID{walletID},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"scopes":["webhooks:manage"]`,
+		},
+		{
+			name:           "restricted admin key",
+			body:           `{"name":"ops","scopes":["admin"],"wallet_ids":["` + walletID.String() + `"]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "keys restricted to wallets may not have admin scope",
+		},
+		{
+			name:           "unknown scope",
+			body:           `{"name":"billing","scopes":["wallet:delete"]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Scopes[0] must be in",
+		},
+		{
+			name:           "no scopes",
+			body:           `{"name":"billing","scopes":[]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Scopes must contain at least 1 elements",
+		},
+		{
+			name:           "missing name",
+			body:           `{"scopes":["wallet:read"]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Name is required",
+		},
+		{
+			name:           "repo error",
+			body:           `{"name":"billing","scopes":["wallet:read","wallet:write"]}`,
+			callRepo:       true,
+			mockError:      errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to create api key",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := slog.New(slog.DiscardHandler)
+			mockRepo := new(mockKeyCreator)
+
+			if tc.callRepo {
+				scopes := tc.mockScopes
+				if scopes == nil {
+					scopes = readWrite
+				}
+
+				mockRepo.On("CreateAPIKey", mock.Anything, "billing", scopes, tc.mockWalletIDs, mock.AnythingOfType("string")).
+					Return(models.APIKey{ID: keyID, Name: "billing", Scopes: scopes, WalletIDs: tc.mockWalletIDs}, tc.mockError).Once()
+			}
+
+			req, _ := http.NewRequest("POST", "/admin/keys", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			r := gin.New()
+			r.POST("/admin/keys", New(context.Background(), log, mockRepo))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNewStoresHashOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	log := slog.New(slog.DiscardHandler)
+	mockRepo := new(mockKeyCreator)
+
+	var storedHash string
+	mockRepo.On("CreateAPIKey", mock.Anything, "billing", []models.Scope{models.SCOPE_WALLET_READ}, []uuid.UUID(nil), mock.AnythingOfType("string")).
+		Run(func(args mock.Arguments) { storedHash = args.String(4) }).
+		Return(models.APIKey{Name: "billing"}, nil).Once()
+
+	req, _ := http.NewRequest("POST", "/admin/keys", bytes.NewBufferString(`{"name":"billing","scopes":["wallet:read"]}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	r := gin.New()
+	r.POST("/admin/keys", New(context.Background(), log, mockRepo))
+	r.ServeHTTP(w, req)
+
+	var response struct {
+		APIKey models.APIKey `json:"api_key"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.NotEqual(t, response.APIKey.Key, storedHash)
+	assert.Equal(t, apikeys.Hash(response.APIKey.Key), storedHash)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,58 @@
+package getlimits
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"wallets/internal/herrors"
+	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+)
+
+type Response struct {
+	resp.Response
+	Limits models.WalletLimits `json:"limits"`
+}
+
+type limitsGetter interface {
+	GetLimits(ctx context.Context, walletID uuid.UUID) (models.WalletLimits, error)
+}
+
+func New(ctx context.Context, log *slog.Logger, repos limitsGetter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "handlers.admin.getlimits.New"
+
+		log := log.With(slog.String("op", op), tracing.TraceID(c.Request.Context()))
+
+		walletID := uuid.UUID{}
+		if err := walletID.Parse(c.Param("uuid")); err != nil {
+			log.Error("failed to decode request parametr", sl.Err(err))
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		limits, err := repos.GetLimits(c.Request.Context(), walletID)
+		if err != nil {
+			log.Error("failed to get limits", sl.Err(err))
+
+			if errors.Is(err, herrors.ErrNXUUID) {
+				c.JSON(http.StatusBadRequest, resp.Error("failed to find uuid"))
+				return
+			}
+
+			c.JSON(http.StatusInternalServerError, resp.Error("failed to get limits"))
+			return
+		}
+
+		c.JSON(http.StatusOK, Response{
+			Response: resp.OK(),
+			Limits:   limits,
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package getlimits
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallets/internal/herrors"
+	"wallets/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockLimitsGetter struct {
+	mock.Mock
+}
+
+func (m *mockLimitsGetter) GetLimits(ctx context.Context, walletID uuid.UUID) (models.WalletLimits, error) {
+	args := m.Called(ctx, walletID)
+	return args.Get(0).(models.WalletLimits), args.Error(1)
+}
+
+func TestNew(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	walletID, _ := uuid.NewV4()
+	daily := int64(100000)
+
+	tests := []struct {
+		name           string
+		walletID       string
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Success",
+			walletID:       walletID.String(),
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"effective":{"max_withdrawal":5000,"daily_withdrawal":100000`,
+		},
+		{
+			name:           "Incorrect UUID",
+			walletID:       "I-n-c-o-r-r-e-c-t-uuid",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to decode request",
+		},
+		{
+			name:           "wallet not found",
+			walletID:       walletID.String(),
+			mockError:      herrors.ErrNXUUID,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to find uuid",
+		},
+		{
+			name:           "repo error",
+			walletID:       walletID.String(),
+			mockError:      errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to get limits",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := slog.New(slog.DiscardHandler)
+			mockRepo := new(mockLimitsGetter)
+
+			if tc.walletID == walletID.String() {
+				mockRepo.On("GetLimits", mock.Anything, walletID).Return(models.WalletLimits{
+					WalletID:  walletID,
+					Overrides: models.LimitOverrides{DailyWithdrawal: &daily},
+					Effective: models.Limits{MaxWithdrawal: 5000, DailyWithdrawal: daily},
+				}, tc.mockError).Once()
+			}
+
+			req, _ := http.NewRequest("GET", "/admin/wallets/"+tc.walletID+"/limits", nil)
+
+			w := httptest.NewRecorder()
+			r := gin.New()
+			r.GET("/admin/wallets/:uuid/limits", New(context.Background(), log, mockRepo))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
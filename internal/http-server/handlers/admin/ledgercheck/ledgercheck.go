@@ -0,0 +1,49 @@
+package ledgercheck
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Response struct {
+	resp.Response
+	// Balanced - балансы всех кошельков совпадают с главной книгой
+	Balanced bool `json:"balanced"`
+	models.LedgerReport
+}
+
+type ledgerChecker interface {
+	CheckLedger(ctx context.Context) (models.LedgerReport, error)
+}
+
+func New(ctx context.Context, log *slog.Logger, repos ledgerChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "handlers.admin.ledgercheck.New"
+
+		log := log.With(slog.String("op", op), tracing.TraceID(c.Request.Context()))
+
+		report, err := repos.CheckLedger(c.Request.Context())
+		if err != nil {
+			log.Error("failed to check ledger", sl.Err(err))
+			c.JSON(http.StatusInternalServerError, resp.Error("failed to check ledger"))
+			return
+		}
+
+		if len(report.Mismatches) > 0 {
+			log.Warn("wallet balances differ from ledger", slog.Int("wallets", len(report.Mismatches)))
+		}
+
+		c.JSON(http.StatusOK, Response{
+			Response:     resp.OK(),
+			Balanced:     len(report.Mismatches) == 0,
+			LedgerReport: report,
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package ledgercheck
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallets/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockLedgerChecker struct {
+	mock.Mock
+}
+
+func (m *mockLedgerChecker) CheckLedger(ctx context.Context) (models.LedgerReport, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(models.LedgerReport), args.Error(1)
+}
+
+func TestNew(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	walletID, _ := uuid.NewV4()
+	accountID, _ := uuid.NewV4()
+
+	accounts := []models.LedgerAccountBalance{
+		{AccountID: accountID, Code: models.ACCOUNT_EXTERNAL_FUNDING, Currency: "RUB", Balance: -5000},
+	}
+
+	tests := []struct {
+		name           string
+		report         models.LedgerReport
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "balanced",
+			report:         models.LedgerReport{SystemAccounts: accounts, Mismatches: []models.LedgerMismatch{}},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"balanced":true,"system_accounts":[{"account_id":"` + accountID.String() + `","code":"external_funding","currency":"RUB","balance":-5000}],"mismatches":[]`,
+		},
+		{
+			name: "mismatch",
+			report: models.LedgerReport{
+				SystemAccounts: accounts,
+				Mismatches:     []models.LedgerMismatch{{WalletID: walletID, Balance: 5100, LedgerBalance: 5000}},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"balanced":false`,
+		},
+		{
+			name:           "repo error",
+			mockError:      errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to check ledger",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := slog.New(slog.DiscardHandler)
+			mockRepo := new(mockLedgerChecker)
+
+			mockRepo.On("CheckLedger", mock.Anything).Return(tc.report, tc.mockError).Once()
+
+			req, _ := http.NewRequest("GET", "/admin/ledger", nil)
+
+			w := httptest.NewRecorder()
+			r := gin.New()
+			r.GET("/admin/ledger", New(context.Background(), log, mockRepo))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package revokekey
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"wallets/internal/herrors"
+	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/lib/sl"
+	"wallets/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+)
+
+type keyRevoker interface {
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) error
+}
+
+func New(ctx context.Context, log *slog.Logger, repos keyRevoker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "handlers.admin.revokekey.New"
+
+		log := log.With(slog.String("op", op), tracing.TraceID(c.Request.Context()))
+
+		keyID := uuid.UUID{}
+		if err := keyID.Parse(c.Param("id")); err != nil {
+			log.Error("failed to decode request parametr", sl.Err(err))
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		if err := repos.RevokeAPIKey(c.Request.Context(), keyID); err != nil {
+			log.Error("failed to revoke api key", sl.Err(err))
+
+			if errors.Is(err, herrors.ErrAPIKeyNotFound) {
+				c.JSON(http.StatusNotFound, resp.Error("api key not found"))
+				return
+			}
+
+			c.JSON(http.StatusInternalServerError, resp.Error("failed to revoke api key"))
+			return
+		}
+
+		log.Info("api key revoked", slog.String("key_id", keyID.String()))
+
+		c.JSON(http.StatusOK, resp.OK())
+	}
+}
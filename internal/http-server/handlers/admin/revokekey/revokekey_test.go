@@ -0,0 +1,92 @@
+package revokekey
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallets/internal/herrors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockKeyRevoker struct {
+	mock.Mock
+}
+
+func (m *mockKeyRevoker) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestNew(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	keyID, _ := uuid.NewV4()
+
+	tests := []struct {
+		name           string
+		keyID          string
+		callRepo       bool
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Success",
+			keyID:          keyID.String(),
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"status":"OK"`,
+		},
+		{
+			name:           "Incorrect UUID",
+			keyID:          "I-n-c-o-r-r-e-c-t-uuid",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to decode request",
+		},
+		{
+			name:           "key not found",
+			keyID:          keyID.String(),
+			callRepo:       true,
+			mockError:      herrors.ErrAPIKeyNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "api key not found",
+		},
+		{
+			name:           "repo error",
+			keyID:          keyID.String(),
+			callRepo:       true,
+			mockError:      errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to revoke api key",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := slog.New(slog.DiscardHandler)
+			mockRepo := new(mockKeyRevoker)
+
+			if tc.callRepo {
+				mockRepo.On("RevokeAPIKey", mock.Anything, keyID).Return(tc.mockError).Once()
+			}
+
+			req, _ := http.NewRequest("DELETE", "/admin/keys/"+tc.keyID, nil)
+
+			w := httptest.NewRecorder()
+			r := gin.New()
+			r.DELETE("/admin/keys/:id", New(context.Background(), log, mockRepo))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
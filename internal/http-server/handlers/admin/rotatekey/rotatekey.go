@@ -0,0 +1,70 @@
+package rotatekey
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"wallets/internal/apikeys"
+	"wallets/internal/herrors"
+	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+)
+
+type Response struct {
+	resp.Response
+	APIKey models.APIKey `json:"api_key"`
+}
+
+type keyRotator interface {
+	RotateAPIKey(ctx context.Context, id uuid.UUID, keyHash string) (models.APIKey, error)
+}
+
+func New(ctx context.Context, log *slog.Logger, repos keyRotator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "handlers.admin.rotatekey.New"
+
+		log := log.With(slog.String("op", op), tracing.TraceID(c.Request.Context()))
+
+		keyID := uuid.UUID{}
+		if err := keyID.Parse(c.Param("id")); err != nil {
+			log.Error("failed to decode request parametr", sl.Err(err))
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		key, err := apikeys.Generate()
+		if err != nil {
+			log.Error("failed to generate api key", sl.Err(err))
+			c.JSON(http.StatusInternalServerError, resp.Error("failed to rotate api key"))
+			return
+		}
+
+		apiKey, err := repos.RotateAPIKey(c.Request.Context(), keyID, apikeys.Hash(key))
+		if err != nil {
+			log.Error("failed to rotate api key", sl.Err(err))
+
+			if errors.Is(err, herrors.ErrAPIKeyNotFound) {
+				c.JSON(http.StatusNotFound, resp.Error("api key not found"))
+				return
+			}
+
+			c.JSON(http.StatusInternalServerError, resp.Error("failed to rotate api key"))
+			return
+		}
+
+		log.Info("api key rotated", slog.String("key_id", apiKey.ID.String()))
+
+		apiKey.Key = key
+
+		c.JSON(http.StatusOK, Response{
+			Response: resp.OK(),
+			APIKey:   apiKey,
+		})
+	}
+}
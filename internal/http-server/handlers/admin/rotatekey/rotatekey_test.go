@@ -0,0 +1,95 @@
+package rotatekey
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallets/internal/herrors"
+	"wallets/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockKeyRotator struct {
+	mock.Mock
+}
+
+func (m *mockKeyRotator) RotateAPIKey(ctx context.Context, id uuid.UUID, keyHash string) (models.APIKey, error) {
+	args := m.Called(ctx, id, keyHash)
+	return args.Get(0).(models.APIKey), args.Error(1)
+}
+
+func TestNew(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	keyID, _ := uuid.NewV4()
+
+	tests := []struct {
+		name           string
+		keyID          string
+		callRepo       bool
+		mockKey        models.APIKey
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Success",
+			keyID:          keyID.String(),
+			callRepo:       true,
+			mockKey:        models.APIKey{ID: keyID, Name: "billing", Scopes: []models.Scope{models.SCOPE_WALLET_READ}},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"key":"wk_`,
+		},
+		{
+			name:           "Incorrect UUID",
+			keyID:          "I-n-c-o-r-r-e-c-t-uuid",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to decode request",
+		},
+		{
+			name:           "key not found",
+			keyID:          keyID.String(),
+			callRepo:       true,
+			mockError:      herrors.ErrAPIKeyNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "api key not found",
+		},
+		{
+			name:           "repo error",
+			keyID:          keyID.String(),
+			callRepo:       true,
+			mockError:      errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to rotate api key",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := slog.New(slog.DiscardHandler)
+			mockRepo := new(mockKeyRotator)
+
+			if tc.callRepo {
+				mockRepo.On("RotateAPIKey", mock.Anything, keyID, mock.AnythingOfType("string")).Return(tc.mockKey, tc.mockError).Once()
+			}
+
+			req, _ := http.NewRequest("POST", "/admin/keys/"+tc.keyID+"/rotate", nil)
+
+			w := httptest.NewRecorder()
+			r := gin.New()
+			r.POST("/admin/keys/:id/rotate", New(context.Background(), log, mockRepo))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
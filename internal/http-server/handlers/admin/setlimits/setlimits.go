@@ -0,0 +1,90 @@
+package setlimits
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"wallets/internal/herrors"
+	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/lib/errtranslate"
+	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofrs/uuid"
+)
+
+// Request - null или отсутствующее поле сбрасывает лимит к значению из конфига, 0 снимает ограничение
+type Request struct {
+	MaxWithdrawal     *int64 `json:"max_withdrawal" binding:"omitempty,gte=0"`
+	DailyWithdrawal   *int64 `json:"daily_withdrawal" binding:"omitempty,gte=0"`
+	MonthlyWithdrawal *int64 `json:"monthly_withdrawal" binding:"omitempty,gte=0"`
+	MaxBalance        *int64 `json:"max_balance" binding:"omitempty,gte=0"`
+}
+
+type Response struct {
+	resp.Response
+	Limits models.WalletLimits `json:"limits"`
+}
+
+type limitsSetter interface {
+	SetLimits(ctx context.Context, walletID uuid.UUID, overrides models.LimitOverrides) (models.WalletLimits, error)
+}
+
+func New(ctx context.Context, log *slog.Logger, repos limitsSetter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "handlers.admin.setlimits.New"
+
+		log := log.With(slog.String("op", op), tracing.TraceID(c.Request.Context()))
+
+		walletID := uuid.UUID{}
+		if err := walletID.Parse(c.Param("uuid")); err != nil {
+			log.Error("failed to decode request parametr", sl.Err(err))
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		var req Request
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+
+			if validationErrs, ok := err.(validator.ValidationErrors); ok {
+				fieldErrors := errtranslate.TranslateValidationErrors(validationErrs)
+				msg := strings.Join(fieldErrors, ", ")
+				c.JSON(http.StatusBadRequest, resp.Error(msg))
+				return
+			}
+
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		limits, err := repos.SetLimits(c.Request.Context(), walletID, models.LimitOverrides(req))
+		if err != nil {
+			log.Error("failed to set limits", sl.Err(err))
+
+			if errors.Is(err, herrors.ErrNXUUID) {
+				c.JSON(http.StatusBadRequest, resp.Error("failed to find uuid"))
+				return
+			}
+
+			if errors.Is(err, herrors.ErrRevenueWalletMaxBalance) {
+				c.JSON(http.StatusBadRequest, resp.Error("max_balance cannot be set for a fee revenue wallet"))
+				return
+			}
+
+			c.JSON(http.StatusInternalServerError, resp.Error("failed to set limits"))
+			return
+		}
+
+		c.JSON(http.StatusOK, Response{
+			Response: resp.OK(),
+			Limits:   limits,
+		})
+	}
+}
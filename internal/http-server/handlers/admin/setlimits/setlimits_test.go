@@ -0,0 +1,122 @@
+package setlimits
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallets/internal/herrors"
+	"wallets/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockLimitsSetter struct {
+	mock.Mock
+}
+
+func (m *mockLimitsSetter) SetLimits(ctx context.Context, walletID uuid.UUID, overrides models.LimitOverrides) (models.WalletLimits, error) {
+	args := m.Called(ctx, walletID, overrides)
+	return args.Get(0).(models.WalletLimits), args.Error(1)
+}
+
+func TestNew(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	walletID, _ := uuid.NewV4()
+
+	tests := []struct {
+		name           string
+		walletID       string
+		body           string
+		overrides      func(o models.LimitOverrides) bool
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:     "Success",
+			walletID: walletID.String(),
+			body:     `{"daily_withdrawal": 100000, "max_balance": 0}`,
+			overrides: func(o models.LimitOverrides) bool {
+				return o.MaxWithdrawal == nil && *o.DailyWithdrawal == 100000 && o.MonthlyWithdrawal == nil && *o.MaxBalance == 0
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"status":"OK"`,
+		},
+		{
+			name:           "Incorrect UUID",
+			walletID:       "I-n-c-o-r-r-e-c-t-uuid",
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to decode request",
+		},
+		{
+			name:           "negative limit",
+			walletID:       walletID.String(),
+			body:           `{"max_withdrawal": -1}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "MaxWithdrawal must be greater than or equal to 0",
+		},
+		{
+			name:           "wallet not found",
+			walletID:       walletID.String(),
+			body:           `{}`,
+			overrides:      func(o models.LimitOverrides) bool { return true },
+			mockError:      herrors.ErrNXUUID,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to find uuid",
+		},
+		{
+			name:     "max balance on revenue wallet",
+			walletID: walletID.String(),
+			body:     `{"max_balance": 100000}`,
+			overrides: func(o models.LimitOverrides) bool {
+				return *o.MaxBalance == 100000
+			},
+			mockError:      herrors.ErrRevenueWalletMaxBalance,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "max_balance cannot be set for a fee revenue wallet",
+		},
+		{
+			name:           "repo error",
+			walletID:       walletID.String(),
+			body:           `{}`,
+			overrides:      func(o models.LimitOverrides) bool { return true },
+			mockError:      errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to set limits",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := slog.New(slog.DiscardHandler)
+			mockRepo := new(mockLimitsSetter)
+
+			if tc.overrides != nil {
+				mockRepo.On("SetLimits", mock.Anything, walletID, mock.MatchedBy(tc.overrides)).
+					Return(models.WalletLimits{WalletID: walletID}, tc.mockError).
+					Once()
+			}
+
+			req, _ := http.NewRequest("PUT", "/admin/wallets/"+tc.walletID+"/limits", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			r := gin.New()
+			r.PUT("/admin/wallets/:uuid/limits", New(context.Background(), log, mockRepo))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
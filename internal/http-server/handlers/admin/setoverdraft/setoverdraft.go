@@ -0,0 +1,90 @@
+package setoverdraft
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"wallets/internal/herrors"
+	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/lib/errtranslate"
+	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofrs/uuid"
+)
+
+// Request - Limit 0 закрывает кредитную линию
+type Request struct {
+	Limit *int64 `json:"limit" binding:"required,gte=0"`
+}
+
+type Response struct {
+	resp.Response
+	CreditLine     int64 `json:"credit_line"`
+	CreditHeadroom int64 `json:"credit_headroom"`
+}
+
+type overdraftSetter interface {
+	SetOverdraftLimit(ctx context.Context, walletID uuid.UUID, limit int64) (models.Wallet, error)
+}
+
+func New(ctx context.Context, log *slog.Logger, repos overdraftSetter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "handlers.admin.setoverdraft.New"
+
+		log := log.With(slog.String("op", op), tracing.TraceID(c.Request.Context()))
+
+		walletID := uuid.UUID{}
+		if err := walletID.Parse(c.Param("uuid")); err != nil {
+			log.Error("failed to decode request parametr", sl.Err(err))
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		var req Request
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+
+			if validationErrs, ok := err.(validator.ValidationErrors); ok {
+				fieldErrors := errtranslate.TranslateValidationErrors(validationErrs)
+				msg := strings.Join(fieldErrors, ", ")
+				c.JSON(http.StatusBadRequest, resp.Error(msg))
+				return
+			}
+
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		wallet, err := repos.SetOverdraftLimit(c.Request.Context(), walletID, *req.Limit)
+		if err != nil {
+			log.Error("failed to set overdraft limit", sl.Err(err))
+
+			switch {
+			case errors.Is(err, herrors.ErrNXUUID):
+				c.JSON(http.StatusBadRequest, resp.Error("failed to find uuid"))
+			case errors.Is(err, herrors.ErrOverdraftInUse):
+				c.JSON(http.StatusConflict, resp.Error(herrors.ErrOverdraftInUse.Error()))
+			case errors.Is(err, herrors.ErrWalletClosed):
+				c.JSON(http.StatusForbidden, resp.Error(herrors.ErrWalletClosed.Error()))
+			case errors.Is(err, herrors.ErrLockedWallet):
+				c.JSON(http.StatusConflict, resp.Error(herrors.ErrLockedWallet.Error()))
+			default:
+				c.JSON(http.StatusInternalServerError, resp.Error("failed to set overdraft limit"))
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, Response{
+			Response:       resp.OK(),
+			CreditLine:     wallet.OverdraftLimit,
+			CreditHeadroom: wallet.CreditHeadroom(),
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package setoverdraft
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallets/internal/herrors"
+	"wallets/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockOverdraftSetter struct {
+	mock.Mock
+}
+
+func (m *mockOverdraftSetter) SetOverdraftLimit(ctx context.Context, walletID uuid.UUID, limit int64) (models.Wallet, error) {
+	args := m.Called(ctx, walletID, limit)
+	return args.Get(0).(models.Wallet), args.Error(1)
+}
+
+func TestNew(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	walletID, _ := uuid.NewV4()
+
+	tests := []struct {
+		name           string
+		walletID       string
+		body           string
+		limit          int64
+		mockWallet     models.Wallet
+		mockError      error
+		callRepo       bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Success",
+			walletID:       walletID.String(),
+			body:           `{"limit": 1000}`,
+			limit:          1000,
+			mockWallet:     models.Wallet{ID: walletID, Balance: -200, OverdraftLimit: 1000},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"credit_line":1000,"credit_headroom":800`,
+		},
+		{
+			name:           "close credit line",
+			walletID:       walletID.String(),
+			body:           `{"limit": 0}`,
+			limit:          0,
+			mockWallet:     models.Wallet{ID: walletID, Balance: 500},
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"credit_line":0`,
+		},
+		{
+			name:           "Incorrect UUID",
+			walletID:       "I-n-c-o-r-r-e-c-t-uuid",
+			body:           `{"limit": 1000}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to decode request",
+		},
+		{
+			name:           "missing limit",
+			walletID:       walletID.String(),
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Limit is required",
+		},
+		{
+			name:           "negative limit",
+			walletID:       walletID.String(),
+			body:           `{"limit": -1}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Limit must be greater than or equal to 0",
+		},
+		{
+			name:           "credit in use",
+			walletID:       walletID.String(),
+			body:           `{"limit": 100}`,
+			limit:          100,
+			mockError:      herrors.ErrOverdraftInUse,
+			callRepo:       true,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   herrors.ErrOverdraftInUse.Error(),
+		},
+		{
+			name:           "wallet not found",
+			walletID:       walletID.String(),
+			body:           `{"limit": 100}`,
+			limit:          100,
+			mockError:      herrors.ErrNXUUID,
+			callRepo:       true,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to find uuid",
+		},
+		{
+			name:           "repo error",
+			walletID:       walletID.String(),
+			body:           `{"limit": 100}`,
+			limit:          100,
+			mockError:      errors.New("db error"),
+			callRepo:       true,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to set overdraft limit",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := slog.New(slog.DiscardHandler)
+			mockRepo := new(mockOverdraftSetter)
+
+			if tc.callRepo {
+				mockRepo.On("SetOverdraftLimit", mock.Anything, walletID, tc.limit).
+					Return(tc.mockWallet, tc.mockError).
+					Once()
+			}
+
+			req, _ := http.NewRequest("PUT", "/admin/wallets/"+tc.walletID+"/overdraft", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			r := gin.New()
+			r.PUT("/admin/wallets/:uuid/overdraft", New(context.Background(), log, mockRepo))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
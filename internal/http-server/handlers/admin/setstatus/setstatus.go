@@ -0,0 +1,96 @@
+package setstatus
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"wallets/internal/herrors"
+	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/http-server/middleware/auth"
+	"wallets/internal/lib/errtranslate"
+	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofrs/uuid"
+)
+
+// Request не содержит автора изменения: он берется из ключа API запроса, см. auth.Actor
+type Request struct {
+	Status models.WalletStatus `json:"status" binding:"required,oneof=ACTIVE FROZEN CLOSED"`
+	Reason string              `json:"reason" binding:"required"`
+}
+
+type Response struct {
+	resp.Response
+	Change models.WalletStatusChange `json:"change"`
+}
+
+type statusSetter interface {
+	SetWalletStatus(ctx context.Context, walletID uuid.UUID, status models.WalletStatus, changedBy, reason string) (models.WalletStatusChange, error)
+}
+
+func New(ctx context.Context, log *slog.Logger, repos statusSetter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "handlers.admin.setstatus.New"
+
+		log := log.With(slog.String("op", op), tracing.TraceID(c.Request.Context()))
+
+		walletID := uuid.UUID{}
+		if err := walletID.Parse(c.Param("uuid")); err != nil {
+			log.Error("failed to decode request parametr", sl.Err(err))
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		var req Request
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+
+			if validationErrs, ok := err.(validator.ValidationErrors); ok {
+				fieldErrors := errtranslate.TranslateValidationErrors(validationErrs)
+				msg := strings.Join(fieldErrors, ", ")
+				c.JSON(http.StatusBadRequest, resp.Error(msg))
+				return
+			}
+
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		change, err := repos.SetWalletStatus(c.Request.Context(), walletID, req.Status, auth.Actor(c), req.Reason)
+		if err != nil {
+			log.Error("failed to set wallet status", sl.Err(err))
+
+			switch {
+			case errors.Is(err, herrors.ErrNXUUID):
+				c.JSON(http.StatusBadRequest, resp.Error("failed to find uuid"))
+			case errors.Is(err, herrors.ErrInvalidStatusTransition):
+				c.JSON(http.StatusConflict, resp.Error("invalid wallet status transition"))
+			case errors.Is(err, herrors.ErrWalletNotEmpty):
+				c.JSON(http.StatusConflict, resp.Error("failed to close wallet: balance is not zero"))
+			default:
+				c.JSON(http.StatusInternalServerError, resp.Error("failed to set wallet status"))
+			}
+
+			return
+		}
+
+		log.Info("wallet status changed",
+			slog.String("wallet_id", walletID.String()),
+			slog.String("from", string(change.FromStatus)),
+			slog.String("to", string(change.ToStatus)),
+			slog.String("changed_by", change.ChangedBy),
+		)
+
+		c.JSON(http.StatusOK, Response{
+			Response: resp.OK(),
+			Change:   change,
+		})
+	}
+}
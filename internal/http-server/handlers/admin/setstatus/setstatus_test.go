@@ -0,0 +1,159 @@
+package setstatus
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallets/internal/herrors"
+	"wallets/internal/http-server/middleware/auth"
+	"wallets/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockStatusSetter struct {
+	mock.Mock
+}
+
+func (m *mockStatusSetter) SetWalletStatus(ctx context.Context, walletID uuid.UUID, status models.WalletStatus, changedBy, reason string) (models.WalletStatusChange, error) {
+	args := m.Called(ctx, walletID, status, changedBy, reason)
+	return args.Get(0).(models.WalletStatusChange), args.Error(1)
+}
+
+func TestNew(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	walletID, _ := uuid.NewV4()
+	keyID, _ := uuid.NewV4()
+
+	adminKey := models.APIKey{ID: keyID, Name: "ops", Scopes: []models.Scope{models.SCOPE_ADMIN}}
+
+	tests := []struct {
+		name           string
+		walletID       string
+		body           string
+		key            *models.APIKey
+		expectedActor  string
+		expectRepoCall bool
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Success",
+			walletID:       walletID.String(),
+			body:           `{"status": "FROZEN", "reason": "AML check"}`,
+			expectRepoCall: true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"to_status":"FROZEN"`,
+		},
+		{
+			name:           "actor is taken from api key",
+			walletID:       walletID.String(),
+			body:           `{"status": "FROZEN", "changed_by": "compliance@bank", "reason": "AML check"}`,
+			key:            &adminKey,
+			expectedActor:  keyID.String(),
+			expectRepoCall: true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"to_status":"FROZEN"`,
+		},
+		{
+			name:           "bootstrap key is recorded by name",
+			walletID:       walletID.String(),
+			body:           `{"status": "FROZEN", "reason": "AML check"}`,
+			key:            &models.APIKey{Name: "bootstrap", Scopes: []models.Scope{models.SCOPE_ADMIN}},
+			expectedActor:  "bootstrap",
+			expectRepoCall: true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"to_status":"FROZEN"`,
+		},
+		{
+			name:           "Incorrect UUID",
+			walletID:       "I-n-c-o-r-r-e-c-t-uuid",
+			body:           `{"status": "FROZEN", "reason": "AML check"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to decode request",
+		},
+		{
+			name:           "unknown status",
+			walletID:       walletID.String(),
+			body:           `{"status": "DELETED", "reason": "AML check"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Status must be in (ACTIVE FROZEN CLOSED)",
+		},
+		{
+			name:           "reason is required",
+			walletID:       walletID.String(),
+			body:           `{"status": "FROZEN"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Reason is required",
+		},
+		{
+			name:           "invalid transition",
+			walletID:       walletID.String(),
+			body:           `{"status": "FROZEN", "reason": "AML check"}`,
+			expectRepoCall: true,
+			mockError:      herrors.ErrInvalidStatusTransition,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "invalid wallet status transition",
+		},
+		{
+			name:           "close not empty wallet",
+			walletID:       walletID.String(),
+			body:           `{"status": "CLOSED", "reason": "customer request"}`,
+			expectRepoCall: true,
+			mockError:      herrors.ErrWalletNotEmpty,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "balance is not zero",
+		},
+		{
+			name:           "repo error",
+			walletID:       walletID.String(),
+			body:           `{"status": "FROZEN", "reason": "AML check"}`,
+			expectRepoCall: true,
+			mockError:      errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to set wallet status",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := slog.New(slog.DiscardHandler)
+			mockRepo := new(mockStatusSetter)
+
+			actor := tc.expectedActor
+			if actor == "" {
+				actor = "anonymous"
+			}
+
+			if tc.expectRepoCall {
+				mockRepo.On("SetWalletStatus", mock.Anything, walletID, mock.AnythingOfType("models.WalletStatus"), actor, mock.AnythingOfType("string")).
+					Return(models.WalletStatusChange{WalletID: walletID, FromStatus: models.WALLET_ACTIVE, ToStatus: models.WALLET_FROZEN}, tc.mockError).
+					Once()
+			}
+
+			req, _ := http.NewRequest("POST", "/admin/wallets/"+tc.walletID+"/status", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			r := gin.New()
+			if tc.key != nil {
+				r.Use(func(c *gin.Context) { auth.SetAPIKey(c, *tc.key) })
+			}
+			r.POST("/admin/wallets/:uuid/status", New(context.Background(), log, mockRepo))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
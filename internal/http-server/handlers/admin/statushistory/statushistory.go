@@ -0,0 +1,50 @@
+package statushistory
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+)
+
+type Response struct {
+	resp.Response
+	Changes []models.WalletStatusChange `json:"changes"`
+}
+
+type statusChangesLister interface {
+	ListStatusChanges(ctx context.Context, walletID uuid.UUID) ([]models.WalletStatusChange, error)
+}
+
+func New(ctx context.Context, log *slog.Logger, repos statusChangesLister) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "handlers.admin.statushistory.New"
+
+		log := log.With(slog.String("op", op), tracing.TraceID(c.Request.Context()))
+
+		walletID := uuid.UUID{}
+		if err := walletID.Parse(c.Param("uuid")); err != nil {
+			log.Error("failed to decode request parametr", sl.Err(err))
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		changes, err := repos.ListStatusChanges(c.Request.Context(), walletID)
+		if err != nil {
+			log.Error("failed to list status changes", sl.Err(err))
+			c.JSON(http.StatusInternalServerError, resp.Error("failed to list status changes"))
+			return
+		}
+
+		c.JSON(http.StatusOK, Response{
+			Response: resp.OK(),
+			Changes:  changes,
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package statushistory
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallets/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockStatusChangesLister struct {
+	mock.Mock
+}
+
+func (m *mockStatusChangesLister) ListStatusChanges(ctx context.Context, walletID uuid.UUID) ([]models.WalletStatusChange, error) {
+	args := m.Called(ctx, walletID)
+	return args.Get(0).([]models.WalletStatusChange), args.Error(1)
+}
+
+func TestNew(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	walletID, _ := uuid.NewV4()
+
+	tests := []struct {
+		name           string
+		walletID       string
+		mockChanges    []models.WalletStatusChange
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:     "Success",
+			walletID: walletID.String(),
+			mockChanges: []models.WalletStatusChange{
+				{WalletID: walletID, FromStatus: models.WALLET_ACTIVE, ToStatus: models.WALLET_FROZEN, ChangedBy: "compliance@bank", Reason: "AML check"},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"changed_by":"compliance@bank"`,
+		},
+		{
+			name:           "Incorrect UUID",
+			walletID:       "I-n-c-o-r-r-e-c-t-uuid",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to decode request",
+		},
+		{
+			name:           "repo error",
+			walletID:       walletID.String(),
+			mockChanges:    []models.WalletStatusChange{},
+			mockError:      errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to list status changes",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := slog.New(slog.DiscardHandler)
+			mockRepo := new(mockStatusChangesLister)
+
+			if tc.walletID == walletID.String() {
+				mockRepo.On("ListStatusChanges", mock.Anything, walletID).Return(tc.mockChanges, tc.mockError).Once()
+			}
+
+			req, _ := http.NewRequest("GET", "/admin/wallets/"+tc.walletID+"/status", nil)
+
+			w := httptest.NewRecorder()
+			r := gin.New()
+			r.GET("/admin/wallets/:uuid/status", New(context.Background(), log, mockRepo))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package liveness
+
+import (
+	"net/http"
+	resp "wallets/internal/http-server/api/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// New отвечает OK, пока процесс обслуживает запросы. Зависимости здесь не проверяются:
+// их недоступность не повод перезапускать сервис, для этого есть /readyz.
+func New() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, resp.OK())
+	}
+}
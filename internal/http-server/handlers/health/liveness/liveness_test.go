@@ -0,0 +1,24 @@
+package liveness
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	req, _ := http.NewRequest("GET", "/healthz", nil)
+
+	w := httptest.NewRecorder()
+	r := gin.New()
+	r.GET("/healthz", New())
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"OK"}`, w.Body.String())
+}
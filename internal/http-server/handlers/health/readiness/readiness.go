@@ -0,0 +1,107 @@
+package readiness
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/lib/sl"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Dependency - внешняя зависимость, без которой сервис не может обслуживать запросы
+type Dependency struct {
+	Name   string
+	Pinger Pinger
+}
+
+// DependencyStatus - результат проверки зависимости. Текст ошибки только в логе: /readyz доступен без ключа.
+type DependencyStatus struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+}
+
+type Response struct {
+	resp.Response
+	Ready        bool                        `json:"ready"`
+	Dependencies map[string]DependencyStatus `json:"dependencies,omitempty"`
+}
+
+// New проверяет зависимости параллельно, каждую с таймаутом timeout. После начала остановки
+// (shuttingDown) сервис сразу отвечает not ready, чтобы оркестратор снял с него трафик.
+func New(ctx context.Context, log *slog.Logger, shuttingDown *atomic.Bool, timeout time.Duration, deps ...Dependency) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "handlers.health.readiness.New"
+
+		log := log.With(slog.String("op", op))
+
+		if shuttingDown.Load() {
+			c.JSON(http.StatusServiceUnavailable, Response{
+				Response: resp.Error("service is shutting down"),
+			})
+			return
+		}
+
+		statuses := make([]DependencyStatus, len(deps))
+
+		var wg sync.WaitGroup
+		for i, dep := range deps {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				pingCtx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+				defer cancel()
+
+				start := time.Now()
+				err := dep.Pinger.Ping(pingCtx)
+
+				statuses[i] = DependencyStatus{
+					Status:    StatusUp,
+					LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+				}
+
+				if err != nil {
+					log.Warn("dependency is not available", slog.String("dependency", dep.Name), sl.Err(err))
+					statuses[i].Status = StatusDown
+				}
+			}()
+		}
+		wg.Wait()
+
+		response := Response{
+			Response:     resp.OK(),
+			Ready:        true,
+			Dependencies: make(map[string]DependencyStatus, len(deps)),
+		}
+
+		for i, dep := range deps {
+			response.Dependencies[dep.Name] = statuses[i]
+
+			if statuses[i].Status == StatusDown {
+				response.Ready = false
+			}
+		}
+
+		if !response.Ready {
+			response.Response = resp.Error("dependency is not available")
+			c.JSON(http.StatusServiceUnavailable, response)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
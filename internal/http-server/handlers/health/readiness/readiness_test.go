@@ -0,0 +1,134 @@
+package readiness
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockPinger struct {
+	mock.Mock
+}
+
+func (m *mockPinger) Ping(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func TestNew(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// waitDeadline отвечает только по истечении таймаута проверки, как зависший Redis
+	waitDeadline := func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	}
+
+	tests := []struct {
+		name           string
+		shuttingDown   bool
+		postgresError  error
+		redisError     error
+		redisRun       func(mock.Arguments)
+		expectedStatus int
+		expectedReady  bool
+		expectedDeps   map[string]string
+		expectedError  string
+	}{
+		{
+			name:           "ready",
+			expectedStatus: http.StatusOK,
+			expectedReady:  true,
+			expectedDeps:   map[string]string{"postgres": StatusUp, "redis": StatusUp},
+		},
+		{
+			name:           "redis down",
+			redisError:     errors.New("connection refused"),
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedDeps:   map[string]string{"postgres": StatusUp, "redis": StatusDown},
+			expectedError:  "dependency is not available",
+		},
+		{
+			name:           "redis timeout",
+			redisError:     context.DeadlineExceeded,
+			redisRun:       waitDeadline,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedDeps:   map[string]string{"postgres": StatusUp, "redis": StatusDown},
+			expectedError:  "dependency is not available",
+		},
+		{
+			name:           "postgres down",
+			postgresError:  errors.New("connection refused"),
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedDeps:   map[string]string{"postgres": StatusDown, "redis": StatusUp},
+			expectedError:  "dependency is not available",
+		},
+		{
+			name:           "shutting down",
+			shuttingDown:   true,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedError:  "service is shutting down",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := slog.New(slog.DiscardHandler)
+			postgres := new(mockPinger)
+			redis := new(mockPinger)
+
+			if !tc.shuttingDown {
+				postgres.On("Ping", mock.Anything).Return(tc.postgresError).Once()
+
+				call := redis.On("Ping", mock.Anything).Return(tc.redisError).Once()
+				if tc.redisRun != nil {
+					call.Run(tc.redisRun)
+				}
+			}
+
+			var shuttingDown atomic.Bool
+			shuttingDown.Store(tc.shuttingDown)
+
+			req, _ := http.NewRequest("GET", "/readyz", nil)
+
+			w := httptest.NewRecorder()
+			r := gin.New()
+			r.GET("/readyz", New(context.Background(), log, &shuttingDown, 20*time.Millisecond,
+				Dependency{Name: "postgres", Pinger: postgres},
+				Dependency{Name: "redis", Pinger: redis},
+			))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+
+			var body Response
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+
+			assert.Equal(t, tc.expectedReady, body.Ready)
+			assert.Equal(t, tc.expectedError, body.Error)
+
+			deps := map[string]string{}
+			for name, status := range body.Dependencies {
+				deps[name] = status.Status
+			}
+			if tc.expectedDeps == nil {
+				assert.Empty(t, deps)
+			} else {
+				assert.Equal(t, tc.expectedDeps, deps)
+			}
+
+			postgres.AssertExpectations(t)
+			redis.AssertExpectations(t)
+		})
+	}
+}
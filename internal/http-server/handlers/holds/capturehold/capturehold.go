@@ -0,0 +1,107 @@
+package capturehold
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"wallets/internal/herrors"
+	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/lib/errtranslate"
+	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofrs/uuid"
+)
+
+// Request - без тела или без amount списывается вся сумма холда
+type Request struct {
+	Amount int64 `json:"amount" binding:"omitempty,gte=1"`
+}
+
+type Response struct {
+	resp.Response
+	Hold        models.Hold         `json:"hold"`
+	Transaction models.Transactions `json:"transaction"`
+}
+
+type holdCapturer interface {
+	CaptureHold(ctx context.Context, holdID uuid.UUID, amount int64) (models.Hold, models.Transactions, error)
+}
+
+func New(ctx context.Context, log *slog.Logger, repos holdCapturer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "handlers.holds.capturehold.New"
+
+		log := log.With(slog.String("op", op), tracing.TraceID(c.Request.Context()))
+
+		holdID := uuid.UUID{}
+		if err := holdID.Parse(c.Param("id")); err != nil {
+			log.Error("failed to decode request parametr", sl.Err(err))
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		var req Request
+
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			log.Error("failed to decode request", sl.Err(err))
+
+			if validationErrs, ok := err.(validator.ValidationErrors); ok {
+				fieldErrors := errtranslate.TranslateValidationErrors(validationErrs)
+				msg := strings.Join(fieldErrors, ", ")
+				c.JSON(http.StatusBadRequest, resp.Error(msg))
+				return
+			}
+
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		hold, tx, err := repos.CaptureHold(c.Request.Context(), holdID, req.Amount)
+		if err != nil {
+			log.Error("failed to capture hold", sl.Err(err))
+
+			if errors.Is(err, herrors.ErrHoldNotFound) {
+				c.JSON(http.StatusNotFound, resp.Error("hold not found"))
+				return
+			}
+
+			if errors.Is(err, herrors.ErrHoldNotActive) {
+				c.JSON(http.StatusConflict, resp.Error("hold is not active"))
+				return
+			}
+
+			if errors.Is(err, herrors.ErrCaptureExceedsHold) {
+				c.JSON(http.StatusBadRequest, resp.Error("capture amount exceeds hold amount"))
+				return
+			}
+
+			if errors.Is(err, herrors.ErrWalletFrozen) {
+				c.JSON(http.StatusForbidden, resp.Error("failed to capture hold: wallet is frozen"))
+				return
+			}
+
+			var limitErr *herrors.LimitError
+			if errors.As(err, &limitErr) {
+				c.JSON(http.StatusForbidden, resp.Error(fmt.Sprintf("limit exceeded: %s", limitErr.Limit)))
+				return
+			}
+
+			c.JSON(http.StatusInternalServerError, resp.Error("failed to capture hold"))
+			return
+		}
+
+		c.JSON(http.StatusAccepted, Response{
+			Response:    resp.OK(),
+			Hold:        hold,
+			Transaction: tx,
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package capturehold
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallets/internal/herrors"
+	"wallets/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockHoldCapturer struct {
+	mock.Mock
+}
+
+func (m *mockHoldCapturer) CaptureHold(ctx context.Context, holdID uuid.UUID, amount int64) (models.Hold, models.Transactions, error) {
+	args := m.Called(ctx, holdID, amount)
+	return args.Get(0).(models.Hold), args.Get(1).(models.Transactions), args.Error(2)
+}
+
+func TestNew(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	holdID, _ := uuid.NewV4()
+	txID, _ := uuid.NewV4()
+
+	tests := []struct {
+		name           string
+		holdID         string
+		body           string
+		expectedAmount int64
+		expectRepoCall bool
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "full capture without body",
+			holdID:         holdID.String(),
+			body:           "",
+			expectedAmount: 0,
+			expectRepoCall: true,
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   txID.String(),
+		},
+		{
+			name:           "partial capture",
+			holdID:         holdID.String(),
+			body:           `{"amount": 300}`,
+			expectedAmount: 300,
+			expectRepoCall: true,
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   txID.String(),
+		},
+		{
+			name:           "Incorrect UUID",
+			holdID:         "I-n-c-o-r-r-e-c-t-uuid",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to decode request",
+		},
+		{
+			name:           "negative amount",
+			holdID:         holdID.String(),
+			body:           `{"amount": -1}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Amount must be greater than or equal to 1",
+		},
+		{
+			name:           "hold not found",
+			holdID:         holdID.String(),
+			expectRepoCall: true,
+			mockError:      herrors.ErrHoldNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "hold not found",
+		},
+		{
+			name:           "hold not active",
+			holdID:         holdID.String(),
+			expectRepoCall: true,
+			mockError:      herrors.ErrHoldNotActive,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "hold is not active",
+		},
+		{
+			name:           "capture exceeds hold",
+			holdID:         holdID.String(),
+			body:           `{"amount": 5000}`,
+			expectedAmount: 5000,
+			expectRepoCall: true,
+			mockError:      herrors.ErrCaptureExceedsHold,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "capture amount exceeds hold amount",
+		},
+		{
+			name:           "max withdrawal exceeded",
+			holdID:         holdID.String(),
+			expectRepoCall: true,
+			mockError:      fmt.Errorf("storage.Postgres.CaptureHold: %w", &herrors.LimitError{Limit: models.LimitMaxWithdrawal, Value: 1000}),
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "limit exceeded: max_withdrawal",
+		},
+		{
+			name:           "daily withdrawal exceeded",
+			holdID:         holdID.String(),
+			body:           `{"amount": 300}`,
+			expectedAmount: 300,
+			expectRepoCall: true,
+			mockError:      &herrors.LimitError{Limit: models.LimitDailyWithdrawal, Value: 500},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "limit exceeded: daily_withdrawal",
+		},
+		{
+			name:           "repo error",
+			holdID:         holdID.String(),
+			expectRepoCall: true,
+			mockError:      errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to capture hold",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := slog.New(slog.DiscardHandler)
+			mockRepo := new(mockHoldCapturer)
+
+			if tc.expectRepoCall {
+				mockRepo.On("CaptureHold", mock.Anything, holdID, tc.expectedAmount).
+					Return(models.Hold{ID: holdID, Status: models.HOLD_CAPTURED}, models.Transactions{ID: txID, OperationType: models.HOLD_CAPTURE}, tc.mockError).
+					Once()
+			}
+
+			req, _ := http.NewRequest("POST", "/holds/"+tc.holdID+"/capture", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			r := gin.New()
+			r.POST("/holds/:id/capture", New(context.Background(), log, mockRepo))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package createhold
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	"wallets/internal/herrors"
+	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/lib/errtranslate"
+	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofrs/uuid"
+)
+
+type Request struct {
+	Amount     int64 `json:"amount" binding:"required,gte=1"`
+	TTLSeconds int64 `json:"ttl_seconds" binding:"omitempty,gte=1"`
+}
+
+type Response struct {
+	resp.Response
+	Hold models.Hold `json:"hold"`
+}
+
+type holdCreator interface {
+	CreateHold(ctx context.Context, walletID uuid.UUID, amount int64, ttl time.Duration) (models.Hold, error)
+}
+
+// New создает холд на кошельке. Если ttl_seconds не передан, холд живет defaultTTL.
+func New(ctx context.Context, log *slog.Logger, repos holdCreator, defaultTTL time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "handlers.holds.createhold.New"
+
+		log := log.With(slog.String("op", op), tracing.TraceID(c.Request.Context()))
+
+		walletID := uuid.UUID{}
+		if err := walletID.Parse(c.Param("uuid")); err != nil {
+			log.Error("failed to decode request parametr", sl.Err(err))
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		var req Request
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+
+			if validationErrs, ok := err.(validator.ValidationErrors); ok {
+				fieldErrors := errtranslate.TranslateValidationErrors(validationErrs)
+				msg := strings.Join(fieldErrors, ", ")
+				c.JSON(http.StatusBadRequest, resp.Error(msg))
+				return
+			}
+
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		ttl := defaultTTL
+		if req.TTLSeconds > 0 {
+			ttl = time.Duration(req.TTLSeconds) * time.Second
+		}
+
+		hold, err := repos.CreateHold(c.Request.Context(), walletID, req.Amount, ttl)
+		if err != nil {
+			log.Error("failed to create hold", sl.Err(err))
+
+			if errors.Is(err, herrors.ErrNXUUID) {
+				c.JSON(http.StatusBadRequest, resp.Error("failed to find uuid"))
+				return
+			}
+
+			if errors.Is(err, herrors.ErrInsufficientFunds) {
+				c.JSON(http.StatusBadRequest, resp.Error("failed to create hold: insufficient funds"))
+				return
+			}
+
+			if errors.Is(err, herrors.ErrWalletFrozen) {
+				c.JSON(http.StatusForbidden, resp.Error("failed to create hold: wallet is frozen"))
+				return
+			}
+
+			if errors.Is(err, herrors.ErrWalletClosed) {
+				c.JSON(http.StatusForbidden, resp.Error("failed to create hold: wallet is closed"))
+				return
+			}
+
+			var limitErr *herrors.LimitError
+			if errors.As(err, &limitErr) {
+				c.JSON(http.StatusForbidden, resp.Error(fmt.Sprintf("failed to create hold: limit exceeded: %s", limitErr.Limit)))
+				return
+			}
+
+			c.JSON(http.StatusInternalServerError, resp.Error("failed to create hold"))
+			return
+		}
+
+		c.JSON(http.StatusCreated, Response{
+			Response: resp.OK(),
+			Hold:     hold,
+		})
+	}
+}
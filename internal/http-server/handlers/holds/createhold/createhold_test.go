@@ -0,0 +1,146 @@
+package createhold
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"wallets/internal/herrors"
+	"wallets/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockHoldCreator struct {
+	mock.Mock
+}
+
+func (m *mockHoldCreator) CreateHold(ctx context.Context, walletID uuid.UUID, amount int64, ttl time.Duration) (models.Hold, error) {
+	args := m.Called(ctx, walletID, amount, ttl)
+	return args.Get(0).(models.Hold), args.Error(1)
+}
+
+func TestNew(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	walletID, _ := uuid.NewV4()
+	holdID, _ := uuid.NewV4()
+	defaultTTL := time.Hour
+
+	tests := []struct {
+		name           string
+		walletID       string
+		body           string
+		expectedTTL    time.Duration
+		expectRepoCall bool
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Success with default ttl",
+			walletID:       walletID.String(),
+			body:           `{"amount": 500}`,
+			expectedTTL:    defaultTTL,
+			expectRepoCall: true,
+			expectedStatus: http.StatusCreated,
+			expectedBody:   holdID.String(),
+		},
+		{
+			name:           "Success with custom ttl",
+			walletID:       walletID.String(),
+			body:           `{"amount": 500, "ttl_seconds": 60}`,
+			expectedTTL:    time.Minute,
+			expectRepoCall: true,
+			expectedStatus: http.StatusCreated,
+			expectedBody:   holdID.String(),
+		},
+		{
+			name:           "Incorrect UUID",
+			walletID:       "I-n-c-o-r-r-e-c-t-uuid",
+			body:           `{"amount": 500}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to decode request",
+		},
+		{
+			name:           "amount is required",
+			walletID:       walletID.String(),
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Amount is required",
+		},
+		{
+			name:           "insufficient funds",
+			walletID:       walletID.String(),
+			body:           `{"amount": 500}`,
+			expectedTTL:    defaultTTL,
+			expectRepoCall: true,
+			mockError:      herrors.ErrInsufficientFunds,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "insufficient funds",
+		},
+		{
+			name:           "max withdrawal exceeded",
+			walletID:       walletID.String(),
+			body:           `{"amount": 500}`,
+			expectedTTL:    defaultTTL,
+			expectRepoCall: true,
+			mockError:      fmt.Errorf("storage.Postgres.CreateHold: %w", &herrors.LimitError{Limit: models.LimitMaxWithdrawal, Value: 400}),
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "limit exceeded: max_withdrawal",
+		},
+		{
+			name:           "daily withdrawal exceeded",
+			walletID:       walletID.String(),
+			body:           `{"amount": 500}`,
+			expectedTTL:    defaultTTL,
+			expectRepoCall: true,
+			mockError:      &herrors.LimitError{Limit: models.LimitDailyWithdrawal, Value: 300},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "limit exceeded: daily_withdrawal",
+		},
+		{
+			name:           "repo error",
+			walletID:       walletID.String(),
+			body:           `{"amount": 500}`,
+			expectedTTL:    defaultTTL,
+			expectRepoCall: true,
+			mockError:      errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to create hold",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := slog.New(slog.DiscardHandler)
+			mockRepo := new(mockHoldCreator)
+
+			if tc.expectRepoCall {
+				mockRepo.On("CreateHold", mock.Anything, walletID, int64(500), tc.expectedTTL).
+					Return(models.Hold{ID: holdID, WalletID: walletID, Amount: 500, Status: models.HOLD_ACTIVE}, tc.mockError).
+					Once()
+			}
+
+			req, _ := http.NewRequest("POST", "/wallets/"+tc.walletID+"/holds", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			r := gin.New()
+			r.POST("/wallets/:uuid/holds", New(context.Background(), log, mockRepo, defaultTTL))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package voidhold
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"wallets/internal/herrors"
+	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+)
+
+type Response struct {
+	resp.Response
+	Hold models.Hold `json:"hold"`
+}
+
+type holdVoider interface {
+	VoidHold(ctx context.Context, holdID uuid.UUID) (models.Hold, error)
+}
+
+func New(ctx context.Context, log *slog.Logger, repos holdVoider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "handlers.holds.voidhold.New"
+
+		log := log.With(slog.String("op", op), tracing.TraceID(c.Request.Context()))
+
+		holdID := uuid.UUID{}
+		if err := holdID.Parse(c.Param("id")); err != nil {
+			log.Error("failed to decode request parametr", sl.Err(err))
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		hold, err := repos.VoidHold(c.Request.Context(), holdID)
+		if err != nil {
+			log.Error("failed to void hold", sl.Err(err))
+
+			if errors.Is(err, herrors.ErrHoldNotFound) {
+				c.JSON(http.StatusNotFound, resp.Error("hold not found"))
+				return
+			}
+
+			if errors.Is(err, herrors.ErrHoldNotActive) {
+				c.JSON(http.StatusConflict, resp.Error("hold is not active"))
+				return
+			}
+
+			c.JSON(http.StatusInternalServerError, resp.Error("failed to void hold"))
+			return
+		}
+
+		c.JSON(http.StatusAccepted, Response{
+			Response: resp.OK(),
+			Hold:     hold,
+		})
+	}
+}
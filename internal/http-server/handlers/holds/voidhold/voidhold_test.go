@@ -0,0 +1,98 @@
+package voidhold
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallets/internal/herrors"
+	"wallets/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockHoldVoider struct {
+	mock.Mock
+}
+
+func (m *mockHoldVoider) VoidHold(ctx context.Context, holdID uuid.UUID) (models.Hold, error) {
+	args := m.Called(ctx, holdID)
+	return args.Get(0).(models.Hold), args.Error(1)
+}
+
+func TestNew(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	holdID, _ := uuid.NewV4()
+
+	tests := []struct {
+		name           string
+		holdID         string
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Success",
+			holdID:         holdID.String(),
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   `"status":"VOIDED"`,
+		},
+		{
+			name:           "Incorrect UUID",
+			holdID:         "I-n-c-o-r-r-e-c-t-uuid",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to decode request",
+		},
+		{
+			name:           "hold not found",
+			holdID:         holdID.String(),
+			mockError:      herrors.ErrHoldNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "hold not found",
+		},
+		{
+			name:           "hold not active",
+			holdID:         holdID.String(),
+			mockError:      herrors.ErrHoldNotActive,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "hold is not active",
+		},
+		{
+			name:           "repo error",
+			holdID:         holdID.String(),
+			mockError:      errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to void hold",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := slog.New(slog.DiscardHandler)
+			mockRepo := new(mockHoldVoider)
+
+			if tc.holdID == holdID.String() {
+				mockRepo.On("VoidHold", mock.Anything, holdID).
+					Return(models.Hold{ID: holdID, Status: models.HOLD_VOIDED}, tc.mockError).
+					Once()
+			}
+
+			req, _ := http.NewRequest("POST", "/holds/"+tc.holdID+"/void", nil)
+
+			w := httptest.NewRecorder()
+			r := gin.New()
+			r.POST("/holds/:id/void", New(context.Background(), log, mockRepo))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package listaccruals
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/lib/errtranslate"
+	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofrs/uuid"
+)
+
+const (
+	// defaultLimit покрывает начисления за месяц
+	defaultLimit = 31
+)
+
+type Request struct {
+	PayoutID string `form:"payout_id" binding:"omitempty,uuid4"`
+	// From и To - даты начисления, обе включительно
+	From  *time.Time `form:"from" time_format:"2006-01-02" time_utc:"1"`
+	To    *time.Time `form:"to" time_format:"2006-01-02" time_utc:"1"`
+	Limit int        `form:"limit" binding:"omitempty,gte=1,lte=366"`
+}
+
+type Response struct {
+	resp.Response
+	Accruals []models.InterestAccrual `json:"accruals"`
+}
+
+type accrualsLister interface {
+	ListInterestAccruals(ctx context.Context, filter models.InterestAccrualFilter) ([]models.InterestAccrual, error)
+}
+
+func New(ctx context.Context, log *slog.Logger, repos accrualsLister) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "handlers.interest.listaccruals.New"
+
+		log := log.With(slog.String("op", op), tracing.TraceID(c.Request.Context()))
+
+		walletID := uuid.UUID{}
+		if err := walletID.Parse(c.Param("uuid")); err != nil {
+			log.Error("failed to decode request parametr", sl.Err(err))
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		var req Request
+
+		if err := c.ShouldBindQuery(&req); err != nil {
+			log.Error("failed to decode query", sl.Err(err))
+
+			if validationErrs, ok := err.(validator.ValidationErrors); ok {
+				fieldErrors := errtranslate.TranslateValidationErrors(validationErrs)
+				msg := strings.Join(fieldErrors, ", ")
+				c.JSON(http.StatusBadRequest, resp.Error(msg))
+				return
+			}
+
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		if req.From != nil && req.To != nil && req.To.Before(*req.From) {
+			c.JSON(http.StatusBadRequest, resp.Error("from must not be after to"))
+			return
+		}
+
+		filter := models.InterestAccrualFilter{
+			WalletID: walletID,
+			From:     req.From,
+			Limit:    req.Limit,
+		}
+
+		if req.PayoutID != "" {
+			filter.PayoutID = uuid.NullUUID{UUID: uuid.FromStringOrNil(req.PayoutID), Valid: true}
+		}
+
+		if req.To != nil {
+			to := req.To.AddDate(0, 0, 1)
+			filter.To = &to
+		}
+
+		if filter.Limit == 0 {
+			filter.Limit = defaultLimit
+		}
+
+		accruals, err := repos.ListInterestAccruals(c.Request.Context(), filter)
+		if err != nil {
+			log.Error("failed to list interest accruals", sl.Err(err))
+			c.JSON(http.StatusInternalServerError, resp.Error("failed to list interest accruals"))
+			return
+		}
+
+		c.JSON(http.StatusOK, Response{
+			Response: resp.OK(),
+			Accruals: accruals,
+		})
+	}
+}
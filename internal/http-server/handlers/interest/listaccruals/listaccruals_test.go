@@ -0,0 +1,150 @@
+package listaccruals
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"wallets/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockAccrualsLister struct {
+	mock.Mock
+}
+
+func (m *mockAccrualsLister) ListInterestAccruals(ctx context.Context, filter models.InterestAccrualFilter) ([]models.InterestAccrual, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]models.InterestAccrual), args.Error(1)
+}
+
+func TestListAccruals(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	walletUUID, _ := uuid.NewV4()
+	payoutUUID, _ := uuid.NewV4()
+	accrualUUID, _ := uuid.NewV4()
+
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		walletID       string
+		query          string
+		filter         models.InterestAccrualFilter
+		mockAccruals   []models.InterestAccrual
+		mockError      error
+		expectRepoCall bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:     "Success",
+			walletID: walletUUID.String(),
+			filter:   models.InterestAccrualFilter{WalletID: walletUUID, Limit: defaultLimit},
+			mockAccruals: []models.InterestAccrual{
+				{
+					ID:            accrualUUID,
+					WalletID:      walletUUID,
+					AccrualDate:   from,
+					Balance:       10000,
+					AnnualRateBps: 500,
+					AmountMicros:  1369863,
+				},
+			},
+			expectRepoCall: true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"amount_micros":1369863`,
+		},
+		{
+			name:     "accruals of payout",
+			walletID: walletUUID.String(),
+			query:    "?payout_id=" + payoutUUID.String(),
+			filter: models.InterestAccrualFilter{
+				WalletID: walletUUID,
+				PayoutID: uuid.NullUUID{UUID: payoutUUID, Valid: true},
+				Limit:    defaultLimit,
+			},
+			mockAccruals:   []models.InterestAccrual{},
+			expectRepoCall: true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"accruals":[]`,
+		},
+		{
+			name:           "dates are inclusive",
+			walletID:       walletUUID.String(),
+			query:          "?from=2026-09-01&to=2026-09-30&limit=30",
+			filter:         models.InterestAccrualFilter{WalletID: walletUUID, From: &from, To: &to, Limit: 30},
+			mockAccruals:   []models.InterestAccrual{},
+			expectRepoCall: true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"accruals":[]`,
+		},
+		{
+			name:           "from after to",
+			walletID:       walletUUID.String(),
+			query:          "?from=2026-09-30&to=2026-09-01",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "from must not be after to",
+		},
+		{
+			name:           "invalid payout id",
+			walletID:       walletUUID.String(),
+			query:          "?payout_id=abc",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "PayoutID must be a valid uuid4",
+		},
+		{
+			name:           "invalid date",
+			walletID:       walletUUID.String(),
+			query:          "?from=01.09.2026",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to decode request",
+		},
+		{
+			name:           "Incorrect UUID",
+			walletID:       "I-n-c-o-r-r-e-c-t-uuid",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to decode request",
+		},
+		{
+			name:           "storage error",
+			walletID:       walletUUID.String(),
+			filter:         models.InterestAccrualFilter{WalletID: walletUUID, Limit: defaultLimit},
+			mockAccruals:   []models.InterestAccrual(nil),
+			mockError:      errors.New("db is down"),
+			expectRepoCall: true,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to list interest accruals",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := slog.New(slog.DiscardHandler)
+			mockRepo := new(mockAccrualsLister)
+			if tc.expectRepoCall {
+				mockRepo.On("ListInterestAccruals", mock.Anything, tc.filter).Return(tc.mockAccruals, tc.mockError).Once()
+			}
+
+			req, _ := http.NewRequest("GET", "/wallets/"+tc.walletID+"/interest/accruals"+tc.query, nil)
+			w := httptest.NewRecorder()
+
+			r := gin.New()
+			r.GET("/wallets/:uuid/interest/accruals", New(context.Background(), log, mockRepo))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
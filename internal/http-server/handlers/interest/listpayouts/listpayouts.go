@@ -0,0 +1,78 @@
+package listpayouts
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/lib/errtranslate"
+	"wallets/internal/lib/pagination"
+	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofrs/uuid"
+)
+
+type Request struct {
+	Limit int `form:"limit" binding:"omitempty,gte=1,lte=100"`
+}
+
+type Response struct {
+	resp.Response
+	Payouts []models.InterestPayout `json:"payouts"`
+}
+
+type payoutsLister interface {
+	ListInterestPayouts(ctx context.Context, walletID uuid.UUID, limit int) ([]models.InterestPayout, error)
+}
+
+func New(ctx context.Context, log *slog.Logger, repos payoutsLister) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "handlers.interest.listpayouts.New"
+
+		log := log.With(slog.String("op", op), tracing.TraceID(c.Request.Context()))
+
+		walletID := uuid.UUID{}
+		if err := walletID.Parse(c.Param("uuid")); err != nil {
+			log.Error("failed to decode request parametr", sl.Err(err))
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		var req Request
+
+		if err := c.ShouldBindQuery(&req); err != nil {
+			log.Error("failed to decode query", sl.Err(err))
+
+			if validationErrs, ok := err.(validator.ValidationErrors); ok {
+				fieldErrors := errtranslate.TranslateValidationErrors(validationErrs)
+				msg := strings.Join(fieldErrors, ", ")
+				c.JSON(http.StatusBadRequest, resp.Error(msg))
+				return
+			}
+
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		if req.Limit == 0 {
+			req.Limit = pagination.DefaultLimit
+		}
+
+		payouts, err := repos.ListInterestPayouts(c.Request.Context(), walletID, req.Limit)
+		if err != nil {
+			log.Error("failed to list interest payouts", sl.Err(err))
+			c.JSON(http.StatusInternalServerError, resp.Error("failed to list interest payouts"))
+			return
+		}
+
+		c.JSON(http.StatusOK, Response{
+			Response: resp.OK(),
+			Payouts:  payouts,
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package listpayouts
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"wallets/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockPayoutsLister struct {
+	mock.Mock
+}
+
+func (m *mockPayoutsLister) ListInterestPayouts(ctx context.Context, walletID uuid.UUID, limit int) ([]models.InterestPayout, error) {
+	args := m.Called(ctx, walletID, limit)
+	return args.Get(0).([]models.InterestPayout), args.Error(1)
+}
+
+func TestListPayouts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	walletUUID, _ := uuid.NewV4()
+	payoutUUID, _ := uuid.NewV4()
+
+	tests := []struct {
+		name           string
+		walletID       string
+		query          string
+		limit          int
+		mockPayouts    []models.InterestPayout
+		mockError      error
+		expectRepoCall bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:     "Success",
+			walletID: walletUUID.String(),
+			limit:    50,
+			mockPayouts: []models.InterestPayout{
+				{
+					ID:       payoutUUID,
+					WalletID: walletUUID,
+					Period:   time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+					Amount:   4110,
+					Currency: "RUB",
+					Exponent: 2,
+				},
+			},
+			expectRepoCall: true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"id":"` + payoutUUID.String() + `"`,
+		},
+		{
+			name:     "rejected payout",
+			walletID: walletUUID.String(),
+			limit:    50,
+			mockPayouts: []models.InterestPayout{
+				{
+					ID:       payoutUUID,
+					WalletID: walletUUID,
+					Period:   time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+					Amount:   4110,
+					Currency: "RUB",
+					Exponent: 2,
+					Status:   models.PAYOUT_REJECTED,
+					Error:    "limit exceeded: max_balance (100000)",
+				},
+			},
+			expectRepoCall: true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"status":"REJECTED","error":"limit exceeded: max_balance (100000)"`,
+		},
+		{
+			name:           "custom limit",
+			walletID:       walletUUID.String(),
+			query:          "?limit=5",
+			limit:          5,
+			mockPayouts:    []models.InterestPayout{},
+			expectRepoCall: true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"payouts":[]`,
+		},
+		{
+			name:           "limit too large",
+			walletID:       walletUUID.String(),
+			query:          "?limit=1000",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Limit must be less than or equal to 100",
+		},
+		{
+			name:           "Incorrect UUID",
+			walletID:       "I-n-c-o-r-r-e-c-t-uuid",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to decode request",
+		},
+		{
+			name:           "storage error",
+			walletID:       walletUUID.String(),
+			limit:          50,
+			mockPayouts:    []models.InterestPayout(nil),
+			mockError:      errors.New("db is down"),
+			expectRepoCall: true,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to list interest payouts",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := slog.New(slog.DiscardHandler)
+			mockRepo := new(mockPayoutsLister)
+			if tc.expectRepoCall {
+				mockRepo.On("ListInterestPayouts", mock.Anything, walletUUID, tc.limit).Return(tc.mockPayouts, tc.mockError).Once()
+			}
+
+			req, _ := http.NewRequest("GET", "/wallets/"+tc.walletID+"/interest/payouts"+tc.query, nil)
+			w := httptest.NewRecorder()
+
+			r := gin.New()
+			r.GET("/wallets/:uuid/interest/payouts", New(context.Background(), log, mockRepo))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package listwallets
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/http-server/middleware/auth"
+	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+)
+
+// maxOwnerIDLength совпадает с ограничением owner_id при создании кошелька
+const maxOwnerIDLength = 128
+
+// Wallet - кошелек владельца с балансами в том же виде, что и в запросе баланса
+type Wallet struct {
+	ID             uuid.UUID           `json:"id"`
+	Balance        int64               `json:"balance"`
+	Available      int64               `json:"available"`
+	Ledger         int64               `json:"ledger"`
+	Currency       string              `json:"currency"`
+	Exponent       int                 `json:"exponent"`
+	Status         models.WalletStatus `json:"wallet_status"`
+	CreditLine     int64               `json:"credit_line"`
+	CreditHeadroom int64               `json:"credit_headroom"`
+}
+
+type Response struct {
+	resp.Response
+	OwnerID string   `json:"owner_id"`
+	Wallets []Wallet `json:"wallets"`
+}
+
+type ownerWalletsLister interface {
+	ListOwnerWallets(ctx context.Context, ownerID string) ([]models.Wallet, error)
+}
+
+func New(ctx context.Context, log *slog.Logger, repos ownerWalletsLister) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "handlers.owners.listwallets.New"
+
+		log := log.With(slog.String("op", op), tracing.TraceID(c.Request.Context()))
+
+		ownerID := c.Param("owner_id")
+		if len(ownerID) > maxOwnerIDLength {
+			c.JSON(http.StatusBadRequest, resp.Error("owner_id is too long"))
+			return
+		}
+
+		wallets, err := repos.ListOwnerWallets(c.Request.Context(), ownerID)
+		if err != nil {
+			log.Error("failed to list owner wallets", sl.Err(err))
+			c.JSON(http.StatusInternalServerError, resp.Error("failed to list wallets"))
+			return
+		}
+
+		response := Response{
+			Response: resp.OK(),
+			OwnerID:  ownerID,
+			Wallets:  make([]Wallet, 0, len(wallets)),
+		}
+
+		for _, wallet := range wallets {
+			// Ключ, ограниченный кошельками, видит только свои кошельки владельца
+			if !auth.WalletAllowed(c, wallet.ID) {
+				continue
+			}
+
+			response.Wallets = append(response.Wallets, Wallet{
+				ID:             wallet.ID,
+				Balance:        wallet.Balance,
+				Available:      wallet.Available(),
+				Ledger:         wallet.Balance,
+				Currency:       wallet.Currency,
+				Exponent:       models.CurrencyExponent(wallet.Currency),
+				Status:         wallet.Status,
+				CreditLine:     wallet.OverdraftLimit,
+				CreditHeadroom: wallet.CreditHeadroom(),
+			})
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
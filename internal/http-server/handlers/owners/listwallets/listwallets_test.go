@@ -0,0 +1,129 @@
+package listwallets
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"wallets/internal/http-server/middleware/auth"
+	"wallets/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockOwnerWalletsLister struct {
+	mock.Mock
+}
+
+func (m *mockOwnerWalletsLister) ListOwnerWallets(ctx context.Context, ownerID string) ([]models.Wallet, error) {
+	args := m.Called(ctx, ownerID)
+	return args.Get(0).([]models.Wallet), args.Error(1)
+}
+
+func TestNew(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rubWallet, _ := uuid.NewV4()
+	usdWallet, _ := uuid.NewV4()
+
+	wallets := []models.Wallet{
+		{ID: rubWallet, Balance: 5000, Held: 1000, Currency: "RUB", Status: models.WALLET_ACTIVE, OwnerID: "customer-42"},
+		{ID: usdWallet, Balance: 700, Currency: "USD", Status: models.WALLET_FROZEN, OwnerID: "customer-42"},
+	}
+
+	tests := []struct {
+		name           string
+		ownerID        string
+		callRepo       bool
+		mockWallets    []models.Wallet
+		mockError      error
+		apiKey         *models.APIKey
+		expectedStatus int
+		expectedBody   string
+		unexpectedBody string
+	}{
+		{
+			name:           "Success",
+			ownerID:        "customer-42",
+			callRepo:       true,
+			mockWallets:    wallets,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"id":"` + usdWallet.String() + `","balance":700,"available":700,"ledger":700,"currency":"USD","exponent":2,"wallet_status":"FROZEN"`,
+		},
+		{
+			name:           "available with holds",
+			ownerID:        "customer-42",
+			callRepo:       true,
+			mockWallets:    wallets,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"balance":5000,"available":4000`,
+		},
+		{
+			name:           "owner without wallets",
+			ownerID:        "customer-7",
+			callRepo:       true,
+			mockWallets:    []models.Wallet{},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"owner_id":"customer-7","wallets":[]`,
+		},
+		{
+			name:           "api key restricted to one wallet",
+			ownerID:        "customer-42",
+			callRepo:       true,
+			mockWallets:    wallets,
+			apiKey:         &models.APIKey{Scopes: []models.Scope{models.SCOPE_WALLET_READ}, WalletIDs: []uuid.UUID{rubWallet}},
+			expectedStatus: http.StatusOK,
+			expectedBody:   rubWallet.String(),
+			unexpectedBody: usdWallet.String(),
+		},
+		{
+			name:           "owner id too long",
+			ownerID:        strings.Repeat("x", 129),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "owner_id is too long",
+		},
+		{
+			name:           "repo error",
+			ownerID:        "customer-42",
+			callRepo:       true,
+			mockWallets:    []models.Wallet{},
+			mockError:      errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to list wallets",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := slog.New(slog.DiscardHandler)
+			mockRepo := new(mockOwnerWalletsLister)
+
+			if tc.callRepo {
+				mockRepo.On("ListOwnerWallets", mock.Anything, tc.ownerID).Return(tc.mockWallets, tc.mockError).Once()
+			}
+
+			req, _ := http.NewRequest("GET", "/owners/"+tc.ownerID+"/wallets", nil)
+
+			w := httptest.NewRecorder()
+			r := gin.New()
+			if tc.apiKey != nil {
+				r.Use(func(c *gin.Context) { auth.SetAPIKey(c, *tc.apiKey) })
+			}
+			r.GET("/owners/:owner_id/wallets", New(context.Background(), log, mockRepo))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			if tc.unexpectedBody != "" {
+				assert.NotContains(t, w.Body.String(), tc.unexpectedBody)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package createschedule
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	"wallets/internal/herrors"
+	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/http-server/middleware/auth"
+	"wallets/internal/lib/errtranslate"
+	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofrs/uuid"
+)
+
+type Request struct {
+	Operation models.OperationType `json:"operation_type" binding:"required,oneof=DEPOSIT WITHDRAW TRANSFER"`
+	WalletID  uuid.UUID            `json:"wallet_id" binding:"required,uuid4"`
+	// ToWalletID - кошелек зачисления, только для TRANSFER
+	ToWalletID  uuid.UUID `json:"to_wallet_id,omitempty"`
+	Amount      int64     `json:"amount" binding:"required,gte=1"`
+	Description string    `json:"description,omitempty" binding:"omitempty,max=255"`
+	// RunAt - время первого запуска, Recurrence - период повторения, без него операция разовая
+	RunAt      time.Time         `json:"run_at" binding:"required"`
+	Recurrence models.Recurrence `json:"recurrence,omitempty" binding:"omitempty,oneof=DAILY WEEKLY MONTHLY"`
+}
+
+type Response struct {
+	resp.Response
+	Schedule models.Schedule `json:"schedule"`
+}
+
+type scheduleCreator interface {
+	CreateSchedule(ctx context.Context, schedule models.Schedule) (models.Schedule, error)
+}
+
+func New(ctx context.Context, log *slog.Logger, repos scheduleCreator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "handlers.schedules.createschedule.New"
+
+		log := log.With(slog.String("op", op), tracing.TraceID(c.Request.Context()))
+
+		var req Request
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+
+			if validationErrs, ok := err.(validator.ValidationErrors); ok {
+				fieldErrors := errtranslate.TranslateValidationErrors(validationErrs)
+				msg := strings.Join(fieldErrors, ", ")
+				c.JSON(http.StatusBadRequest, resp.Error(msg))
+				return
+			}
+
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		schedule := models.Schedule{
+			WalletID:      req.WalletID,
+			OperationType: req.Operation,
+			Amount:        req.Amount,
+			Description:   req.Description,
+			Recurrence:    req.Recurrence,
+			StartAt:       req.RunAt,
+		}
+
+		if req.Operation == models.TRANSFER {
+			if req.ToWalletID.IsNil() {
+				c.JSON(http.StatusBadRequest, resp.Error("to_wallet_id is required for TRANSFER"))
+				return
+			}
+
+			if req.ToWalletID == req.WalletID {
+				c.JSON(http.StatusBadRequest, resp.Error("source and destination wallets are the same"))
+				return
+			}
+
+			schedule.ToWalletID = uuid.NullUUID{UUID: req.ToWalletID, Valid: true}
+		}
+
+		if !req.RunAt.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, resp.Error("run_at must be in the future"))
+			return
+		}
+
+		if !auth.WalletAllowed(c, req.WalletID) {
+			c.JSON(http.StatusForbidden, resp.Error("api key is not allowed for this wallet"))
+			return
+		}
+
+		schedule, err := repos.CreateSchedule(c.Request.Context(), schedule)
+		if err != nil {
+			log.Error("failed to create schedule", sl.Err(err))
+
+			if errors.Is(err, herrors.ErrNXUUID) {
+				c.JSON(http.StatusBadRequest, resp.Error("failed to find uuid"))
+				return
+			}
+
+			c.JSON(http.StatusInternalServerError, resp.Error("failed to create schedule"))
+			return
+		}
+
+		c.JSON(http.StatusCreated, Response{
+			Response: resp.OK(),
+			Schedule: schedule,
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package createschedule
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"wallets/internal/herrors"
+	"wallets/internal/http-server/middleware/auth"
+	"wallets/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockScheduleCreator struct {
+	mock.Mock
+}
+
+func (m *mockScheduleCreator) CreateSchedule(ctx context.Context, schedule models.Schedule) (models.Schedule, error) {
+	args := m.Called(ctx, schedule)
+	return args.Get(0).(models.Schedule), args.Error(1)
+}
+
+func TestCreateSchedule(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	walletUUID, _ := uuid.NewV4()
+	payeeUUID, _ := uuid.NewV4()
+	scheduleUUID, _ := uuid.NewV4()
+
+	runAt := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+
+	standingOrder := models.Schedule{
+		WalletID:      walletUUID,
+		ToWalletID:    uuid.NullUUID{UUID: payeeUUID, Valid: true},
+		OperationType: models.TRANSFER,
+		Amount:        5000,
+		Recurrence:    models.RECURRENCE_MONTHLY,
+		StartAt:       runAt,
+	}
+
+	created := standingOrder
+	created.ID = scheduleUUID
+	created.NextRunAt = runAt
+	created.Status = models.SCHEDULE_ACTIVE
+
+	tests := []struct {
+		name           string
+		body           Request
+		mockSchedule   *models.Schedule
+		mockCreated    models.Schedule
+		mockError      error
+		apiKey         *models.APIKey
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "standing order",
+			body: Request{
+				Operation:  models.TRANSFER,
+				WalletID:   walletUUID,
+				ToWalletID: payeeUUID,
+				Amount:     5000,
+				RunAt:      runAt,
+				Recurrence: models.RECURRENCE_MONTHLY,
+			},
+			mockSchedule:   &standingOrder,
+			mockCreated:    created,
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"recurrence":"MONTHLY"`,
+		},
+		{
+			name: "one-off deposit ignores destination",
+			body: Request{
+				Operation:  models.DEPOSIT,
+				WalletID:   walletUUID,
+				ToWalletID: payeeUUID,
+				Amount:     100,
+				RunAt:      runAt,
+			},
+			mockSchedule: &models.Schedule{
+				WalletID:      walletUUID,
+				OperationType: models.DEPOSIT,
+				Amount:        100,
+				StartAt:       runAt,
+			},
+			mockCreated:    models.Schedule{ID: scheduleUUID, WalletID: walletUUID, OperationType: models.DEPOSIT, Amount: 100, Status: models.SCHEDULE_ACTIVE},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"status":"ACTIVE"`,
+		},
+		{
+			name: "wallet not found",
+			body: Request{
+				Operation: models.WITHDRAW,
+				WalletID:  walletUUID,
+				Amount:    100,
+				RunAt:     runAt,
+			},
+			mockSchedule: &models.Schedule{
+				WalletID:      walletUUID,
+				OperationType: models.WITHDRAW,
+				Amount:        100,
+				StartAt:       runAt,
+			},
+			mockCreated:    models.Schedule{},
+			mockError:      herrors.ErrNXUUID,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to find uuid",
+		},
+		{
+			name: "transfer without destination",
+			body: Request{
+				Operation: models.TRANSFER,
+				WalletID:  walletUUID,
+				Amount:    100,
+				RunAt:     runAt,
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "to_wallet_id is required for TRANSFER",
+		},
+		{
+			name: "transfer to the same wallet",
+			body: Request{
+				Operation:  models.TRANSFER,
+				WalletID:   walletUUID,
+				ToWalletID: walletUUID,
+				Amount:     100,
+				RunAt:      runAt,
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "source and destination wallets are the same",
+		},
+		{
+			name: "run_at in the past",
+			body: Request{
+				Operation: models.DEPOSIT,
+				WalletID:  walletUUID,
+				Amount:    100,
+				RunAt:     time.Now().Add(-time.Hour),
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "run_at must be in the future",
+		},
+		{
+			name: "invalid recurrence",
+			body: Request{
+				Operation:  models.DEPOSIT,
+				WalletID:   walletUUID,
+				Amount:     100,
+				RunAt:      runAt,
+				Recurrence: "YEARLY",
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Recurrence must be in (DAILY WEEKLY MONTHLY)",
+		},
+		{
+			name: "api key restricted to another wallet",
+			body: Request{
+				Operation: models.WITHDRAW,
+				WalletID:  walletUUID,
+				Amount:    100,
+				RunAt:     runAt,
+			},
+			apiKey:         &models.APIKey{Scopes: []models.Scope{models.SCOPE_WALLET_WRITE}, WalletIDs: []uuid.UUID{payeeUUID}},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "api key is not allowed for this wallet",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := slog.New(slog.DiscardHandler)
+			mockRepo := new(mockScheduleCreator)
+			if tc.mockSchedule != nil {
+				mockRepo.On("CreateSchedule", mock.Anything, *tc.mockSchedule).
+					Return(tc.mockCreated, tc.mockError).
+					Once()
+			}
+
+			reqBody, _ := json.Marshal(tc.body)
+			req, _ := http.NewRequest("POST", "/schedules", bytes.NewBuffer(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			r := gin.New()
+			if tc.apiKey != nil {
+				r.Use(func(c *gin.Context) { auth.SetAPIKey(c, *tc.apiKey) })
+			}
+			r.POST("/schedules", New(context.Background(), log, mockRepo))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package listruns
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"wallets/internal/herrors"
+	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/lib/errtranslate"
+	"wallets/internal/lib/pagination"
+	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofrs/uuid"
+)
+
+type Request struct {
+	Limit int `form:"limit" binding:"omitempty,gte=1,lte=100"`
+}
+
+type Response struct {
+	resp.Response
+	Runs []models.ScheduleRun `json:"runs"`
+}
+
+type runsLister interface {
+	ListScheduleRuns(ctx context.Context, scheduleID uuid.UUID, limit int) ([]models.ScheduleRun, error)
+}
+
+func New(ctx context.Context, log *slog.Logger, repos runsLister) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "handlers.schedules.listruns.New"
+
+		log := log.With(slog.String("op", op), tracing.TraceID(c.Request.Context()))
+
+		scheduleID := uuid.UUID{}
+		if err := scheduleID.Parse(c.Param("id")); err != nil {
+			log.Error("failed to decode request parametr", sl.Err(err))
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		var req Request
+
+		if err := c.ShouldBindQuery(&req); err != nil {
+			log.Error("failed to decode query", sl.Err(err))
+
+			if validationErrs, ok := err.(validator.ValidationErrors); ok {
+				fieldErrors := errtranslate.TranslateValidationErrors(validationErrs)
+				msg := strings.Join(fieldErrors, ", ")
+				c.JSON(http.StatusBadRequest, resp.Error(msg))
+				return
+			}
+
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		if req.Limit == 0 {
+			req.Limit = pagination.DefaultLimit
+		}
+
+		runs, err := repos.ListScheduleRuns(c.Request.Context(), scheduleID, req.Limit)
+		if err != nil {
+			log.Error("failed to list schedule runs", sl.Err(err))
+
+			if errors.Is(err, herrors.ErrScheduleNotFound) {
+				c.JSON(http.StatusNotFound, resp.Error("schedule not found"))
+				return
+			}
+
+			c.JSON(http.StatusInternalServerError, resp.Error("failed to list schedule runs"))
+			return
+		}
+
+		c.JSON(http.StatusOK, Response{
+			Response: resp.OK(),
+			Runs:     runs,
+		})
+	}
+}
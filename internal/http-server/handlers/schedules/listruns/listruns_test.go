@@ -0,0 +1,109 @@
+package listruns
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallets/internal/herrors"
+	"wallets/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockRunsLister struct {
+	mock.Mock
+}
+
+func (m *mockRunsLister) ListScheduleRuns(ctx context.Context, scheduleID uuid.UUID, limit int) ([]models.ScheduleRun, error) {
+	args := m.Called(ctx, scheduleID, limit)
+	return args.Get(0).([]models.ScheduleRun), args.Error(1)
+}
+
+func TestListRuns(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	scheduleUUID, _ := uuid.NewV4()
+
+	tests := []struct {
+		name           string
+		scheduleID     string
+		query          string
+		limit          int
+		mockRuns       []models.ScheduleRun
+		mockError      error
+		expectRepoCall bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:       "Success",
+			scheduleID: scheduleUUID.String(),
+			limit:      50,
+			mockRuns: []models.ScheduleRun{
+				{ScheduleID: scheduleUUID, Status: models.RUN_FAILED, Error: "insufficient funds"},
+			},
+			expectRepoCall: true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"status":"FAILED","error":"insufficient funds"`,
+		},
+		{
+			name:           "custom limit",
+			scheduleID:     scheduleUUID.String(),
+			query:          "?limit=5",
+			limit:          5,
+			mockRuns:       []models.ScheduleRun{},
+			expectRepoCall: true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"runs":[]`,
+		},
+		{
+			name:           "limit too large",
+			scheduleID:     scheduleUUID.String(),
+			query:          "?limit=1000",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Limit must be less than or equal to 100",
+		},
+		{
+			name:           "schedule not found",
+			scheduleID:     scheduleUUID.String(),
+			limit:          50,
+			mockRuns:       []models.ScheduleRun(nil),
+			mockError:      herrors.ErrScheduleNotFound,
+			expectRepoCall: true,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "schedule not found",
+		},
+		{
+			name:           "Incorrect UUID",
+			scheduleID:     "I-n-c-o-r-r-e-c-t-uuid",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to decode request",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := slog.New(slog.DiscardHandler)
+			mockRepo := new(mockRunsLister)
+			if tc.expectRepoCall {
+				mockRepo.On("ListScheduleRuns", mock.Anything, scheduleUUID, tc.limit).Return(tc.mockRuns, tc.mockError).Once()
+			}
+
+			req, _ := http.NewRequest("GET", "/schedules/"+tc.scheduleID+"/runs"+tc.query, nil)
+			w := httptest.NewRecorder()
+
+			r := gin.New()
+			r.GET("/schedules/:id/runs", New(context.Background(), log, mockRepo))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
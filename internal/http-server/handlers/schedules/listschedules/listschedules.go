@@ -0,0 +1,50 @@
+package listschedules
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+)
+
+type Response struct {
+	resp.Response
+	Schedules []models.Schedule `json:"schedules"`
+}
+
+type schedulesLister interface {
+	ListWalletSchedules(ctx context.Context, walletID uuid.UUID) ([]models.Schedule, error)
+}
+
+func New(ctx context.Context, log *slog.Logger, repos schedulesLister) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "handlers.schedules.listschedules.New"
+
+		log := log.With(slog.String("op", op), tracing.TraceID(c.Request.Context()))
+
+		walletID := uuid.UUID{}
+		if err := walletID.Parse(c.Param("uuid")); err != nil {
+			log.Error("failed to decode request parametr", sl.Err(err))
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		schedules, err := repos.ListWalletSchedules(c.Request.Context(), walletID)
+		if err != nil {
+			log.Error("failed to list schedules", sl.Err(err))
+			c.JSON(http.StatusInternalServerError, resp.Error("failed to list schedules"))
+			return
+		}
+
+		c.JSON(http.StatusOK, Response{
+			Response:  resp.OK(),
+			Schedules: schedules,
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package listschedules
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallets/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockSchedulesLister struct {
+	mock.Mock
+}
+
+func (m *mockSchedulesLister) ListWalletSchedules(ctx context.Context, walletID uuid.UUID) ([]models.Schedule, error) {
+	args := m.Called(ctx, walletID)
+	return args.Get(0).([]models.Schedule), args.Error(1)
+}
+
+func TestListSchedules(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	walletUUID, _ := uuid.NewV4()
+	scheduleUUID, _ := uuid.NewV4()
+
+	tests := []struct {
+		name           string
+		walletID       string
+		mockSchedules  []models.Schedule
+		mockError      error
+		expectRepoCall bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:     "Success",
+			walletID: walletUUID.String(),
+			mockSchedules: []models.Schedule{
+				{ID: scheduleUUID, WalletID: walletUUID, OperationType: models.WITHDRAW, Amount: 100, Status: models.SCHEDULE_PAUSED},
+			},
+			expectRepoCall: true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"id":"` + scheduleUUID.String() + `"`,
+		},
+		{
+			name:           "no schedules",
+			walletID:       walletUUID.String(),
+			mockSchedules:  []models.Schedule{},
+			expectRepoCall: true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"schedules":[]`,
+		},
+		{
+			name:           "Incorrect UUID",
+			walletID:       "I-n-c-o-r-r-e-c-t-uuid",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to decode request",
+		},
+		{
+			name:           "storage error",
+			walletID:       walletUUID.String(),
+			mockSchedules:  []models.Schedule(nil),
+			mockError:      errors.New("db is down"),
+			expectRepoCall: true,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to list schedules",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := slog.New(slog.DiscardHandler)
+			mockRepo := new(mockSchedulesLister)
+			if tc.expectRepoCall {
+				mockRepo.On("ListWalletSchedules", mock.Anything, walletUUID).Return(tc.mockSchedules, tc.mockError).Once()
+			}
+
+			req, _ := http.NewRequest("GET", "/wallets/"+tc.walletID+"/schedules", nil)
+			w := httptest.NewRecorder()
+
+			r := gin.New()
+			r.GET("/wallets/:uuid/schedules", New(context.Background(), log, mockRepo))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package setschedulestatus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"wallets/internal/herrors"
+	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+)
+
+type Response struct {
+	resp.Response
+	Schedule models.Schedule `json:"schedule"`
+}
+
+type scheduleStatusSetter interface {
+	SetScheduleStatus(ctx context.Context, id uuid.UUID, status models.ScheduleStatus) (models.Schedule, error)
+}
+
+// New переводит расписание в status: PAUSED приостанавливает его, ACTIVE возобновляет, CANCELLED отменяет
+func New(ctx context.Context, log *slog.Logger, repos scheduleStatusSetter, status models.ScheduleStatus) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "handlers.schedules.setschedulestatus.New"
+
+		log := log.With(slog.String("op", op), tracing.TraceID(c.Request.Context()))
+
+		scheduleID := uuid.UUID{}
+		if err := scheduleID.Parse(c.Param("id")); err != nil {
+			log.Error("failed to decode request parametr", sl.Err(err))
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		schedule, err := repos.SetScheduleStatus(c.Request.Context(), scheduleID, status)
+		if err != nil {
+			log.Error("failed to set schedule status", sl.Err(err))
+
+			if errors.Is(err, herrors.ErrScheduleNotFound) {
+				c.JSON(http.StatusNotFound, resp.Error("schedule not found"))
+				return
+			}
+
+			if errors.Is(err, herrors.ErrInvalidScheduleTransition) {
+				c.JSON(http.StatusConflict, resp.Error(fmt.Sprintf("schedule can not be set to %s", status)))
+				return
+			}
+
+			c.JSON(http.StatusInternalServerError, resp.Error("failed to set schedule status"))
+			return
+		}
+
+		c.JSON(http.StatusOK, Response{
+			Response: resp.OK(),
+			Schedule: schedule,
+		})
+	}
+}
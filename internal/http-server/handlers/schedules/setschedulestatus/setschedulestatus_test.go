@@ -0,0 +1,107 @@
+package setschedulestatus
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallets/internal/herrors"
+	"wallets/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockScheduleStatusSetter struct {
+	mock.Mock
+}
+
+func (m *mockScheduleStatusSetter) SetScheduleStatus(ctx context.Context, id uuid.UUID, status models.ScheduleStatus) (models.Schedule, error) {
+	args := m.Called(ctx, id, status)
+	return args.Get(0).(models.Schedule), args.Error(1)
+}
+
+func TestSetScheduleStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	scheduleUUID, _ := uuid.NewV4()
+
+	tests := []struct {
+		name           string
+		scheduleID     string
+		status         models.ScheduleStatus
+		mockSchedule   models.Schedule
+		mockError      error
+		expectRepoCall bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "pause",
+			scheduleID:     scheduleUUID.String(),
+			status:         models.SCHEDULE_PAUSED,
+			mockSchedule:   models.Schedule{ID: scheduleUUID, Status: models.SCHEDULE_PAUSED},
+			expectRepoCall: true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"status":"PAUSED"`,
+		},
+		{
+			name:           "cancel",
+			scheduleID:     scheduleUUID.String(),
+			status:         models.SCHEDULE_CANCELLED,
+			mockSchedule:   models.Schedule{ID: scheduleUUID, Status: models.SCHEDULE_CANCELLED},
+			expectRepoCall: true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"status":"CANCELLED"`,
+		},
+		{
+			name:           "resume cancelled schedule",
+			scheduleID:     scheduleUUID.String(),
+			status:         models.SCHEDULE_ACTIVE,
+			mockError:      herrors.ErrInvalidScheduleTransition,
+			expectRepoCall: true,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "schedule can not be set to ACTIVE",
+		},
+		{
+			name:           "schedule not found",
+			scheduleID:     scheduleUUID.String(),
+			status:         models.SCHEDULE_PAUSED,
+			mockError:      herrors.ErrScheduleNotFound,
+			expectRepoCall: true,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "schedule not found",
+		},
+		{
+			name:           "Incorrect UUID",
+			scheduleID:     "I-n-c-o-r-r-e-c-t-uuid",
+			status:         models.SCHEDULE_PAUSED,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to decode request",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := slog.New(slog.DiscardHandler)
+			mockRepo := new(mockScheduleStatusSetter)
+			if tc.expectRepoCall {
+				mockRepo.On("SetScheduleStatus", mock.Anything, scheduleUUID, tc.status).Return(tc.mockSchedule, tc.mockError).Once()
+			}
+
+			req, _ := http.NewRequest("POST", "/schedules/"+tc.scheduleID, nil)
+			w := httptest.NewRecorder()
+
+			r := gin.New()
+			r.POST("/schedules/:id", New(context.Background(), log, mockRepo, tc.status))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
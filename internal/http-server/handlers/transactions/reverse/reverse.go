@@ -0,0 +1,99 @@
+package reverse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"wallets/internal/herrors"
+	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/lib/errtranslate"
+	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofrs/uuid"
+)
+
+// Request - без тела или без amount отменяется весь еще не отмененный остаток операции
+type Request struct {
+	Amount int64 `json:"amount" binding:"omitempty,gte=1"`
+}
+
+type Response struct {
+	resp.Response
+	Transaction models.Transactions `json:"transaction"`
+}
+
+type transactionReverser interface {
+	ReverseTransaction(ctx context.Context, txID uuid.UUID, amount int64) (models.Transactions, error)
+}
+
+func New(ctx context.Context, log *slog.Logger, repos transactionReverser) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "handlers.transactions.reverse.New"
+
+		log := log.With(slog.String("op", op), tracing.TraceID(c.Request.Context()))
+
+		txID := uuid.UUID{}
+		if err := txID.Parse(c.Param("id")); err != nil {
+			log.Error("failed to decode request parametr", sl.Err(err))
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		var req Request
+
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			log.Error("failed to decode request", sl.Err(err))
+
+			if validationErrs, ok := err.(validator.ValidationErrors); ok {
+				fieldErrors := errtranslate.TranslateValidationErrors(validationErrs)
+				msg := strings.Join(fieldErrors, ", ")
+				c.JSON(http.StatusBadRequest, resp.Error(msg))
+				return
+			}
+
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		tx, err := repos.ReverseTransaction(c.Request.Context(), txID, req.Amount)
+		if err != nil {
+			log.Error("failed to reverse transaction", sl.Err(err))
+
+			var limitErr *herrors.LimitError
+
+			switch {
+			case errors.Is(err, herrors.ErrTransactionNotFound):
+				c.JSON(http.StatusNotFound, resp.Error("transaction not found"))
+			case errors.Is(err, herrors.ErrNotReversible):
+				c.JSON(http.StatusBadRequest, resp.Error("only DEPOSIT and WITHDRAW can be reversed"))
+			case errors.Is(err, herrors.ErrReversalExceedsAmount):
+				c.JSON(http.StatusConflict, resp.Error("reversal exceeds the not yet reversed amount"))
+			case errors.Is(err, herrors.ErrFundsAlreadySpent):
+				c.JSON(http.StatusConflict, resp.Error("failed to refund: deposited funds are already spent"))
+			case errors.Is(err, herrors.ErrWalletFrozen):
+				c.JSON(http.StatusForbidden, resp.Error("failed to refund: wallet is frozen"))
+			case errors.Is(err, herrors.ErrWalletClosed):
+				c.JSON(http.StatusForbidden, resp.Error("failed to reverse: wallet is closed"))
+			case errors.As(err, &limitErr):
+				c.JSON(http.StatusForbidden, resp.Error(fmt.Sprintf("limit exceeded: %s", limitErr.Limit)))
+			default:
+				c.JSON(http.StatusInternalServerError, resp.Error("failed to reverse transaction"))
+			}
+
+			return
+		}
+
+		c.JSON(http.StatusAccepted, Response{
+			Response:    resp.OK(),
+			Transaction: tx,
+		})
+	}
+}
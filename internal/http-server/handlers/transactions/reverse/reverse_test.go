@@ -0,0 +1,154 @@
+package reverse
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallets/internal/herrors"
+	"wallets/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockTransactionReverser struct {
+	mock.Mock
+}
+
+func (m *mockTransactionReverser) ReverseTransaction(ctx context.Context, txID uuid.UUID, amount int64) (models.Transactions, error) {
+	args := m.Called(ctx, txID, amount)
+	return args.Get(0).(models.Transactions), args.Error(1)
+}
+
+func TestNew(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	txID, _ := uuid.NewV4()
+	reversalID, _ := uuid.NewV4()
+
+	tests := []struct {
+		name           string
+		txID           string
+		body           string
+		expectedAmount int64
+		expectRepoCall bool
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "full reversal",
+			txID:           txID.String(),
+			expectRepoCall: true,
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   reversalID.String(),
+		},
+		{
+			name:           "partial refund",
+			txID:           txID.String(),
+			body:           `{"amount": 250}`,
+			expectedAmount: 250,
+			expectRepoCall: true,
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   txID.String(),
+		},
+		{
+			name:           "Incorrect UUID",
+			txID:           "I-n-c-o-r-r-e-c-t-uuid",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to decode request",
+		},
+		{
+			name:           "negative amount",
+			txID:           txID.String(),
+			body:           `{"amount": -10}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Amount must be greater than or equal to 1",
+		},
+		{
+			name:           "transaction not found",
+			txID:           txID.String(),
+			expectRepoCall: true,
+			mockError:      herrors.ErrTransactionNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "transaction not found",
+		},
+		{
+			name:           "not reversible",
+			txID:           txID.String(),
+			expectRepoCall: true,
+			mockError:      herrors.ErrNotReversible,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "only DEPOSIT and WITHDRAW can be reversed",
+		},
+		{
+			name:           "exceeds original amount",
+			txID:           txID.String(),
+			body:           `{"amount": 100000}`,
+			expectedAmount: 100000,
+			expectRepoCall: true,
+			mockError:      herrors.ErrReversalExceedsAmount,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "reversal exceeds the not yet reversed amount",
+		},
+		{
+			name:           "funds already spent",
+			txID:           txID.String(),
+			expectRepoCall: true,
+			mockError:      herrors.ErrFundsAlreadySpent,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "deposited funds are already spent",
+		},
+		{
+			name:           "max balance exceeded",
+			txID:           txID.String(),
+			expectRepoCall: true,
+			mockError:      &herrors.LimitError{Limit: models.LimitMaxBalance, Value: 1000},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "limit exceeded: max_balance",
+		},
+		{
+			name:           "repo error",
+			txID:           txID.String(),
+			expectRepoCall: true,
+			mockError:      errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to reverse transaction",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := slog.New(slog.DiscardHandler)
+			mockRepo := new(mockTransactionReverser)
+
+			if tc.expectRepoCall {
+				mockRepo.On("ReverseTransaction", mock.Anything, txID, tc.expectedAmount).
+					Return(models.Transactions{
+						ID:            reversalID,
+						OperationType: models.REVERSAL_DEBIT,
+						ReversalOf:    uuid.NullUUID{UUID: txID, Valid: true},
+					}, tc.mockError).
+					Once()
+			}
+
+			req, _ := http.NewRequest("POST", "/transactions/"+tc.txID+"/reverse", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			r := gin.New()
+			r.POST("/transactions/:id/reverse", New(context.Background(), log, mockRepo))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
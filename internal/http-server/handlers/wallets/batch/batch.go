@@ -0,0 +1,205 @@
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"wallets/internal/herrors"
+	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/http-server/middleware/auth"
+	"wallets/internal/lib/errtranslate"
+	"wallets/internal/lib/metadata"
+	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofrs/uuid"
+)
+
+type Item struct {
+	Operation models.OperationType `json:"operation_type" binding:"required,oneof=DEPOSIT WITHDRAW TRANSFER"`
+	WalletID  uuid.UUID            `json:"wallet_id" binding:"required,uuid4"`
+	// ToWalletID - кошелек зачисления, только для TRANSFER
+	ToWalletID        uuid.UUID       `json:"to_wallet_id,omitempty"`
+	Amount            int64           `json:"amount" binding:"required,gte=1"`
+	Currency          string          `json:"currency,omitempty" binding:"omitempty,iso4217"`
+	Description       string          `json:"description,omitempty" binding:"omitempty,max=255"`
+	Metadata          json.RawMessage `json:"metadata,omitempty"`
+	ExternalReference string          `json:"external_reference,omitempty" binding:"omitempty,max=128"`
+}
+
+type Request struct {
+	Items []Item `json:"items" binding:"required,min=1,dive"`
+}
+
+type Response struct {
+	resp.Response
+	Results []models.BatchItemResult `json:"results"`
+}
+
+type batchApplier interface {
+	ApplyBatch(ctx context.Context, items []models.BatchItem) ([]models.BatchItemResult, error)
+}
+
+// New принимает до maxItems операций и проводит их все или ни одной
+func New(ctx context.Context, log *slog.Logger, repos batchApplier, maxItems int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "handlers.wallets.batch.New"
+
+		log := log.With("op", op, tracing.TraceID(c.Request.Context()))
+
+		var req Request
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+
+			if validationErrs, ok := err.(validator.ValidationErrors); ok {
+				fieldErrors := errtranslate.TranslateValidationErrors(validationErrs)
+				msg := strings.Join(fieldErrors, ", ")
+				c.JSON(http.StatusBadRequest, resp.Error(msg))
+				return
+			}
+
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		if len(req.Items) > maxItems {
+			c.JSON(http.StatusBadRequest, resp.Error(fmt.Sprintf("items must contain at most %d elements", maxItems)))
+			return
+		}
+
+		items := make([]models.BatchItem, 0, len(req.Items))
+		for i, item := range req.Items {
+			if item.Operation == models.TRANSFER && item.ToWalletID.IsNil() {
+				c.JSON(http.StatusBadRequest, resp.Error(fmt.Sprintf("item %d: to_wallet_id is required for TRANSFER", i)))
+				return
+			}
+
+			if err := metadata.Validate(item.Metadata); err != nil {
+				c.JSON(http.StatusBadRequest, resp.Error(fmt.Sprintf("item %d: %s", i, err)))
+				return
+			}
+
+			// Как и в одиночном переводе, ключ должен иметь доступ к кошельку списания
+			if !auth.WalletAllowed(c, item.WalletID) {
+				c.JSON(http.StatusForbidden, resp.Error(fmt.Sprintf("item %d: api key is not allowed for this wallet", i)))
+				return
+			}
+
+			batchItem := models.BatchItem{
+				OperationType:     item.Operation,
+				WalletID:          item.WalletID,
+				Amount:            item.Amount,
+				Currency:          item.Currency,
+				Description:       item.Description,
+				Metadata:          metadata.Normalize(item.Metadata),
+				ExternalReference: item.ExternalReference,
+			}
+
+			if item.Operation == models.TRANSFER {
+				batchItem.ToWalletID = item.ToWalletID
+			}
+
+			items = append(items, batchItem)
+		}
+
+		results, err := repos.ApplyBatch(c.Request.Context(), items)
+		if err != nil {
+			log.Error("failed to apply batch", sl.Err(err))
+
+			if errors.Is(err, herrors.ErrLockedWallet) {
+				c.JSON(http.StatusConflict, resp.Error("wallets of the batch are locked by another operation"))
+				return
+			}
+
+			var itemErr *herrors.BatchItemError
+			if errors.As(err, &itemErr) {
+				status, msg := itemError(itemErr.Err)
+
+				c.JSON(status, Response{
+					Response: resp.Error(fmt.Sprintf("item %d: %s", itemErr.Index, msg)),
+					Results:  failedResults(len(items), itemErr.Index, msg),
+				})
+				return
+			}
+
+			// Кошелек доходов, который не удалось заблокировать, не относится к одной операции пакета
+			if errors.Is(err, herrors.ErrNoRevenueWallet) {
+				status, msg := itemError(err)
+				c.JSON(status, resp.Error(msg))
+				return
+			}
+
+			c.JSON(http.StatusInternalServerError, resp.Error("failed to apply batch"))
+			return
+		}
+
+		c.JSON(http.StatusAccepted, Response{
+			Response: resp.OK(),
+			Results:  results,
+		})
+
+	}
+}
+
+// itemError подбирает код ответа и сообщение для ошибки операции пакета так же, как одиночные операции
+func itemError(err error) (int, string) {
+	if errors.Is(err, herrors.ErrNXUUID) {
+		return http.StatusBadRequest, "failed to find uuid"
+	}
+
+	if errors.Is(err, herrors.ErrSameWallet) {
+		return http.StatusBadRequest, "source and destination wallets are the same"
+	}
+
+	if errors.Is(err, herrors.ErrInsufficientFunds) {
+		return http.StatusBadRequest, "insufficient funds"
+	}
+
+	if errors.Is(err, herrors.ErrCurrencyMismatch) {
+		return http.StatusBadRequest, "currency does not match wallet currency"
+	}
+
+	if errors.Is(err, herrors.ErrDuplicateExternalReference) {
+		return http.StatusConflict, "external_reference is already used for this wallet"
+	}
+
+	if errors.Is(err, herrors.ErrWalletFrozen) {
+		return http.StatusForbidden, "wallet is frozen"
+	}
+
+	if errors.Is(err, herrors.ErrWalletClosed) {
+		return http.StatusForbidden, "wallet is closed"
+	}
+
+	var limitErr *herrors.LimitError
+	if errors.As(err, &limitErr) {
+		return http.StatusForbidden, fmt.Sprintf("limit exceeded: %s", limitErr.Limit)
+	}
+
+	if errors.Is(err, herrors.ErrNoRevenueWallet) {
+		return http.StatusUnprocessableEntity, "fee revenue wallet is not available"
+	}
+
+	return http.StatusInternalServerError, "failed to apply operation"
+}
+
+// failedResults описывает откатившийся пакет: операция failed отклонена, остальные не проведены
+func failedResults(count, failed int, msg string) []models.BatchItemResult {
+	results := make([]models.BatchItemResult, count)
+	for i := range results {
+		results[i] = models.BatchItemResult{Index: i, Status: models.BATCH_ITEM_ROLLED_BACK}
+	}
+
+	results[failed].Status = models.BATCH_ITEM_FAILED
+	results[failed].Error = msg
+
+	return results
+}
@@ -0,0 +1,186 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallets/internal/herrors"
+	"wallets/internal/http-server/middleware/auth"
+	"wallets/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockBatchApplier struct {
+	mock.Mock
+}
+
+func (m *mockBatchApplier) ApplyBatch(ctx context.Context, items []models.BatchItem) ([]models.BatchItemResult, error) {
+	args := m.Called(ctx, items)
+	return args.Get(0).([]models.BatchItemResult), args.Error(1)
+}
+
+func TestBatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	payerUUID, _ := uuid.NewV4()
+	payeeUUID, _ := uuid.NewV4()
+	transferUUID, _ := uuid.NewV4()
+
+	payroll := []Item{
+		{Operation: models.DEPOSIT, WalletID: payerUUID, Amount: 5000, ExternalReference: "payroll-1"},
+		{Operation: models.TRANSFER, WalletID: payerUUID, ToWalletID: payeeUUID, Amount: 3000},
+	}
+
+	payrollItems := []models.BatchItem{
+		{OperationType: models.DEPOSIT, WalletID: payerUUID, Amount: 5000, ExternalReference: "payroll-1"},
+		{OperationType: models.TRANSFER, WalletID: payerUUID, ToWalletID: payeeUUID, Amount: 3000},
+	}
+
+	tests := []struct {
+		name           string
+		body           Request
+		mockItems      []models.BatchItem
+		mockResults    []models.BatchItemResult
+		mockError      error
+		apiKey         *models.APIKey
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:      "Success",
+			body:      Request{Items: payroll},
+			mockItems: payrollItems,
+			mockResults: []models.BatchItemResult{
+				{Index: 0, Status: models.BATCH_ITEM_APPLIED, Transactions: []models.Transactions{{WalletID: payerUUID, OperationType: models.DEPOSIT, Amount: 5000}}},
+				{Index: 1, Status: models.BATCH_ITEM_APPLIED, TransferID: uuid.NullUUID{UUID: transferUUID, Valid: true}},
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   `"index":1,"status":"APPLIED","transfer_id":"` + transferUUID.String() + `"`,
+		},
+		{
+			name:           "item failed",
+			body:           Request{Items: payroll},
+			mockItems:      payrollItems,
+			mockResults:    []models.BatchItemResult(nil),
+			mockError:      fmt.Errorf("storage.ApplyBatch: %w", &herrors.BatchItemError{Index: 1, Err: herrors.ErrInsufficientFunds}),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"error":"item 1: insufficient funds","results":[{"index":0,"status":"ROLLED_BACK"},{"index":1,"status":"FAILED","error":"insufficient funds"}]`,
+		},
+		{
+			name:           "item over limit",
+			body:           Request{Items: payroll},
+			mockItems:      payrollItems,
+			mockResults:    []models.BatchItemResult(nil),
+			mockError:      &herrors.BatchItemError{Index: 0, Err: &herrors.LimitError{Limit: models.LimitMaxBalance, Value: 1000}},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "item 0: limit exceeded: max_balance",
+		},
+		{
+			name:           "item without fee revenue wallet",
+			body:           Request{Items: payroll},
+			mockItems:      payrollItems,
+			mockResults:    []models.BatchItemResult(nil),
+			mockError:      &herrors.BatchItemError{Index: 1, Err: herrors.ErrNoRevenueWallet},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `"error":"item 1: fee revenue wallet is not available"`,
+		},
+		{
+			name:           "fee revenue wallet missing",
+			body:           Request{Items: payroll},
+			mockItems:      payrollItems,
+			mockResults:    []models.BatchItemResult(nil),
+			mockError:      fmt.Errorf("storage.ApplyBatch: %w", herrors.ErrNoRevenueWallet),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   "fee revenue wallet is not available",
+		},
+		{
+			name:           "wallets locked",
+			body:           Request{Items: payroll},
+			mockItems:      payrollItems,
+			mockResults:    []models.BatchItemResult(nil),
+			mockError:      herrors.ErrLockedWallet,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "wallets of the batch are locked by another operation",
+		},
+		{
+			name:           "empty batch",
+			body:           Request{Items: []Item{}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Items must contain at least 1 elements",
+		},
+		{
+			name: "too many items",
+			body: Request{Items: []Item{
+				{Operation: models.DEPOSIT, WalletID: payerUUID, Amount: 1},
+				{Operation: models.DEPOSIT, WalletID: payerUUID, Amount: 1},
+				{Operation: models.DEPOSIT, WalletID: payerUUID, Amount: 1},
+			}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "items must contain at most 2 elements",
+		},
+		{
+			name:           "transfer without destination",
+			body:           Request{Items: []Item{{Operation: models.TRANSFER, WalletID: payerUUID, Amount: 100}}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "item 0: to_wallet_id is required for TRANSFER",
+		},
+		{
+			name:           "invalid operation",
+			body:           Request{Items: []Item{{Operation: models.HOLD_CAPTURE, WalletID: payerUUID, Amount: 100}}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Operation must be in (DEPOSIT WITHDRAW TRANSFER)",
+		},
+		{
+			name: "metadata is not an object",
+			body: Request{Items: []Item{
+				{Operation: models.DEPOSIT, WalletID: payerUUID, Amount: 100, Metadata: json.RawMessage(`"A-1"`)},
+			}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "item 0: metadata must be a JSON object",
+		},
+		{
+			name:           "api key restricted to another wallet",
+			body:           Request{Items: payroll},
+			apiKey:         &models.APIKey{Scopes: []models.Scope{models.SCOPE_WALLET_WRITE}, WalletIDs: []uuid.UUID{payeeUUID}},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "item 0: api key is not allowed for this wallet",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := slog.New(slog.DiscardHandler)
+			mockRepo := new(mockBatchApplier)
+			if tc.mockItems != nil {
+				mockRepo.On("ApplyBatch", mock.Anything, tc.mockItems).
+					Return(tc.mockResults, tc.mockError).
+					Once()
+			}
+
+			reqBody, _ := json.Marshal(tc.body)
+			req, _ := http.NewRequest("POST", "/wallet/batch", bytes.NewBuffer(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			r := gin.New()
+			if tc.apiKey != nil {
+				r.Use(func(c *gin.Context) { auth.SetAPIKey(c, *tc.apiKey) })
+			}
+			r.POST("/wallet/batch", New(context.Background(), log, mockRepo, 2))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
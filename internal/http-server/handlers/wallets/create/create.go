@@ -6,24 +6,39 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"strings"
+	"wallets/internal/herrors"
 	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/lib/errtranslate"
 	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/tracing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 	"github.com/gofrs/uuid"
 )
 
 type Request struct {
-	Balance int64 `json:"balance" binding:"required"`
+	Balance  int64  `json:"balance" binding:"required"`
+	Currency string `json:"currency" binding:"omitempty,iso4217"`
+	// OwnerID - внешний идентификатор владельца, задается только при создании
+	OwnerID string `json:"owner_id" binding:"omitempty,max=128"`
+	// Type - тип кошелька, по нему выбираются тарифы комиссий
+	Type string `json:"type" binding:"omitempty,max=32"`
 }
 
 type Response struct {
 	resp.Response
-	ID uuid.UUID `json:"id"`
+	ID       uuid.UUID `json:"id"`
+	Currency string    `json:"currency"`
+	Exponent int       `json:"exponent"`
+	OwnerID  string    `json:"owner_id,omitempty"`
+	Type     string    `json:"type"`
 }
 
 type walletCreator interface {
-	CreateWallet(ctx context.Context, balance int64) (uuid.UUID, error)
+	CreateWallet(ctx context.Context, balance int64, currency, ownerID, walletType string) (uuid.UUID, error)
 }
 
 func New(ctx context.Context, log *slog.Logger, repos walletCreator) gin.HandlerFunc {
@@ -31,7 +46,7 @@ func New(ctx context.Context, log *slog.Logger, repos walletCreator) gin.Handler
 	return func(c *gin.Context) {
 		const op = "handlers.wallets.create.New"
 
-		log := log.With(slog.String("op", op))
+		log := log.With(slog.String("op", op), tracing.TraceID(c.Request.Context()))
 
 		var req Request
 
@@ -43,17 +58,38 @@ func New(ctx context.Context, log *slog.Logger, repos walletCreator) gin.Handler
 				req.Balance = 0
 			} else {
 				log.Error("failed to decode request body", sl.Err(err))
+
+				if validationErrs, ok := err.(validator.ValidationErrors); ok {
+					fieldErrors := errtranslate.TranslateValidationErrors(validationErrs)
+					msg := strings.Join(fieldErrors, ", ")
+					c.JSON(http.StatusBadRequest, resp.Error(msg))
+					return
+				}
+
 				c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
 				return
 			}
 		}
 
+		if req.Currency == "" {
+			req.Currency = models.DefaultCurrency
+		}
+
+		if req.Type == "" {
+			req.Type = models.DefaultWalletType
+		}
+
 		log.Info("request body decoded", slog.Any("request", req))
 
-		id, err := repos.CreateWallet(ctx, req.Balance)
+		id, err := repos.CreateWallet(c.Request.Context(), req.Balance, req.Currency, req.OwnerID, req.Type)
 		if err != nil {
 			log.Error("failed to create wallet", sl.Err(err))
 
+			if errors.Is(err, herrors.ErrOwnerWalletExists) {
+				c.JSON(http.StatusConflict, resp.Error("owner already has a wallet in this currency"))
+				return
+			}
+
 			c.JSON(http.StatusInternalServerError, resp.Error("failed to create wallet"))
 
 			return
@@ -62,6 +98,10 @@ func New(ctx context.Context, log *slog.Logger, repos walletCreator) gin.Handler
 		c.JSON(http.StatusCreated, Response{
 			Response: resp.OK(),
 			ID:       id,
+			Currency: req.Currency,
+			Exponent: models.CurrencyExponent(req.Currency),
+			OwnerID:  req.OwnerID,
+			Type:     req.Type,
 		})
 
 	}
@@ -8,8 +8,11 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"wallets/internal/herrors"
 	"wallets/internal/http-server/api/response"
+	"wallets/internal/models"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/assert/v2"
@@ -25,6 +28,9 @@ type mockWalletCreator struct {
 type testCase struct {
 	name           string
 	requestBody    string
+	currency       string
+	ownerID        string
+	walletType     string
 	mockReturnID   uuid.UUID
 	mockReturnErr  error
 	expectedCode   int
@@ -32,8 +38,8 @@ type testCase struct {
 	expectRepoCall bool
 }
 
-func (m *mockWalletCreator) CreateWallet(ctx context.Context, balance int64) (uuid.UUID, error) {
-	args := m.Called(ctx, balance)
+func (m *mockWalletCreator) CreateWallet(ctx context.Context, balance int64, currency, ownerID, walletType string) (uuid.UUID, error) {
+	args := m.Called(ctx, balance, currency, ownerID, walletType)
 	return args.Get(0).(uuid.UUID), args.Error(1)
 }
 
@@ -70,6 +76,75 @@ func TestNew(t *testing.T) {
 			expectRepoCall: true,
 		},
 
+		{
+			name:          "with currency",
+			requestBody:   `{"balance": 1000, "currency": "JPY"}`,
+			currency:      "JPY",
+			mockReturnID:  walletID,
+			mockReturnErr: nil,
+			expectedCode:  http.StatusCreated,
+			expectedResp: Response{
+				Response: response.OK(),
+				ID:       walletID,
+				Currency: "JPY",
+				Exponent: 0,
+			},
+			expectRepoCall: true,
+		},
+
+		{
+			name:          "with owner",
+			requestBody:   `{"balance": 1000, "owner_id": "customer-42"}`,
+			ownerID:       "customer-42",
+			mockReturnID:  walletID,
+			mockReturnErr: nil,
+			expectedCode:  http.StatusCreated,
+			expectedResp: Response{
+				Response: response.OK(),
+				ID:       walletID,
+				OwnerID:  "customer-42",
+			},
+			expectRepoCall: true,
+		},
+
+		{
+			name:          "with type",
+			requestBody:   `{"balance": 1000, "type": "merchant"}`,
+			walletType:    "merchant",
+			mockReturnID:  walletID,
+			mockReturnErr: nil,
+			expectedCode:  http.StatusCreated,
+			expectedResp: Response{
+				Response: response.OK(),
+				ID:       walletID,
+				Type:     "merchant",
+			},
+			expectRepoCall: true,
+		},
+
+		{
+			name:           "too long type",
+			requestBody:    `{"balance": 1000, "type": "` + strings.Repeat("a", 33) + `"}`,
+			expectedCode:   http.StatusBadRequest,
+			expectRepoCall: false,
+		},
+
+		{
+			name:           "owner already has a wallet in currency",
+			requestBody:    `{"balance": 1000, "owner_id": "customer-42"}`,
+			ownerID:        "customer-42",
+			mockReturnErr:  herrors.ErrOwnerWalletExists,
+			expectedCode:   http.StatusConflict,
+			expectRepoCall: true,
+		},
+
+		{
+			name:           "unknown currency",
+			requestBody:    `{"balance": 1000, "currency": "ABC"}`,
+			expectedCode:   http.StatusBadRequest,
+			expectRepoCall: false,
+		},
+
 		{
 			name:           "invalid request body",
 			requestBody:    `{"invalid": "json"}`,
@@ -100,7 +175,15 @@ func TestNew(t *testing.T) {
 
 			mockRepo.ExpectedCalls = nil
 			if tc.expectRepoCall {
-				mockRepo.On("CreateWallet", mock.Anything, mock.AnythingOfType("int64")).
+				currency := tc.currency
+				if currency == "" {
+					currency = models.DefaultCurrency
+				}
+				walletType := tc.walletType
+				if walletType == "" {
+					walletType = models.DefaultWalletType
+				}
+				mockRepo.On("CreateWallet", mock.Anything, mock.AnythingOfType("int64"), currency, tc.ownerID, walletType).
 					Return(tc.mockReturnID, tc.mockReturnErr).
 					Once()
 			}
@@ -126,6 +209,14 @@ func TestNew(t *testing.T) {
 				require.NoError(t, err)
 
 				assert.Equal(t, tc.expectedResp.ID, response.ID)
+				assert.Equal(t, tc.expectedResp.OwnerID, response.OwnerID)
+				if tc.walletType != "" {
+					assert.Equal(t, tc.expectedResp.Type, response.Type)
+				}
+				if tc.currency != "" {
+					assert.Equal(t, tc.expectedResp.Currency, response.Currency)
+					assert.Equal(t, tc.expectedResp.Exponent, response.Exponent)
+				}
 			}
 
 			mockRepo.AssertExpectations(t)
@@ -2,12 +2,17 @@ package getbalance
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strings"
+	"time"
+	"wallets/internal/herrors"
 	resp "wallets/internal/http-server/api/response"
 	"wallets/internal/lib/errtranslate"
 	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/tracing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -18,20 +23,49 @@ type Request struct {
 	ID uuid.UUID `validate:"uuid4,required"`
 }
 
+// Query - с as_of возвращается состояние кошелька на прошедший момент времени
+type Query struct {
+	AsOf *time.Time `form:"as_of" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
 type Response struct {
 	resp.Response
-	Balance int64 `json:"balance"`
+	// Balance совпадает с Ledger и оставлен для совместимости
+	Balance   int64               `json:"balance"`
+	Available int64               `json:"available"`
+	Ledger    int64               `json:"ledger"`
+	Currency  string              `json:"currency"`
+	Exponent  int                 `json:"exponent"`
+	Status    models.WalletStatus `json:"wallet_status"`
+	// CreditLine - разрешенный овердрафт, CreditHeadroom - сколько из него еще можно потратить
+	CreditLine     int64  `json:"credit_line"`
+	CreditHeadroom int64  `json:"credit_headroom"`
+	OwnerID        string `json:"owner_id,omitempty"`
+	Type           string `json:"type"`
+}
+
+// AsOfResponse - исторический баланс. Кредитная линия не хранит историю и в ответ не входит.
+type AsOfResponse struct {
+	resp.Response
+	AsOf      time.Time           `json:"as_of"`
+	Balance   int64               `json:"balance"`
+	Available int64               `json:"available"`
+	Ledger    int64               `json:"ledger"`
+	Currency  string              `json:"currency"`
+	Exponent  int                 `json:"exponent"`
+	Status    models.WalletStatus `json:"wallet_status"`
 }
 
 type balanceWallet interface {
-	GetBalance(ctx context.Context, walletID uuid.UUID) (int64, error)
+	GetBalance(ctx context.Context, walletID uuid.UUID) (models.Wallet, error)
+	GetBalanceAsOf(ctx context.Context, walletID uuid.UUID, asOf time.Time) (models.Wallet, error)
 }
 
 func New(ctx context.Context, log *slog.Logger, repos balanceWallet) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		const op = "handlers.wallets.getbalance.New"
 
-		log := log.With(slog.String("op", op))
+		log := log.With(slog.String("op", op), tracing.TraceID(c.Request.Context()))
 
 		var req Request
 
@@ -52,7 +86,47 @@ func New(ctx context.Context, log *slog.Logger, repos balanceWallet) gin.Handler
 			return
 		}
 
-		balance, err := repos.GetBalance(ctx, req.ID)
+		var query Query
+
+		if err := c.ShouldBindQuery(&query); err != nil {
+			log.Error("failed to decode query", sl.Err(err))
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		if query.AsOf != nil {
+			if query.AsOf.After(time.Now()) {
+				c.JSON(http.StatusBadRequest, resp.Error("as_of must not be in the future"))
+				return
+			}
+
+			wallet, err := repos.GetBalanceAsOf(c.Request.Context(), req.ID, *query.AsOf)
+			if err != nil {
+				log.Error("failed to get balance as of", sl.Err(err))
+
+				if errors.Is(err, herrors.ErrNXUUID) {
+					c.JSON(http.StatusBadRequest, resp.Error("failed to find uuid"))
+					return
+				}
+
+				c.JSON(http.StatusInternalServerError, resp.Error("failed to get balance"))
+				return
+			}
+
+			c.JSON(http.StatusOK, AsOfResponse{
+				Response:  resp.OK(),
+				AsOf:      *query.AsOf,
+				Balance:   wallet.Balance,
+				Available: wallet.Available(),
+				Ledger:    wallet.Balance,
+				Currency:  wallet.Currency,
+				Exponent:  models.CurrencyExponent(wallet.Currency),
+				Status:    wallet.Status,
+			})
+			return
+		}
+
+		wallet, err := repos.GetBalance(c.Request.Context(), req.ID)
 		if err != nil {
 			log.Error("failed to get balance", sl.Err(err))
 			c.JSON(http.StatusInternalServerError, resp.Error("failed to get balance"))
@@ -60,8 +134,17 @@ func New(ctx context.Context, log *slog.Logger, repos balanceWallet) gin.Handler
 		}
 
 		c.JSON(http.StatusAccepted, Response{
-			Response: resp.OK(),
-			Balance:  balance,
+			Response:       resp.OK(),
+			Balance:        wallet.Balance,
+			Available:      wallet.Available(),
+			Ledger:         wallet.Balance,
+			Currency:       wallet.Currency,
+			Exponent:       models.CurrencyExponent(wallet.Currency),
+			Status:         wallet.Status,
+			CreditLine:     wallet.OverdraftLimit,
+			CreditHeadroom: wallet.CreditHeadroom(),
+			OwnerID:        wallet.OwnerID,
+			Type:           wallet.Type,
 		})
 
 	}
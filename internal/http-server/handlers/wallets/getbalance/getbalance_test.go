@@ -6,7 +6,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 	"wallets/internal/herrors"
+	"wallets/internal/models"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gofrs/uuid"
@@ -17,7 +19,9 @@ import (
 type testCase struct {
 	name              string
 	walletID          string
-	mockBalanceWallet int64
+	query             string
+	asOf              *time.Time
+	mockBalanceWallet models.Wallet
 	mockError         error
 	expectedStatus    int
 	expectedBody      string
@@ -27,9 +31,14 @@ type mockBalanceWallet struct {
 	mock.Mock
 }
 
-func (m *mockBalanceWallet) GetBalance(ctx context.Context, walletID uuid.UUID) (int64, error) {
+func (m *mockBalanceWallet) GetBalance(ctx context.Context, walletID uuid.UUID) (models.Wallet, error) {
 	args := m.Called(ctx, walletID)
-	return args.Get(0).(int64), args.Error(1)
+	return args.Get(0).(models.Wallet), args.Error(1)
+}
+
+func (m *mockBalanceWallet) GetBalanceAsOf(ctx context.Context, walletID uuid.UUID, asOf time.Time) (models.Wallet, error) {
+	args := m.Called(ctx, walletID, asOf)
+	return args.Get(0).(models.Wallet), args.Error(1)
 }
 
 func TestNew(t *testing.T) {
@@ -38,19 +47,95 @@ func TestNew(t *testing.T) {
 
 	validUUID, _ := uuid.NewV4()
 
+	asOf := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+
 	tests := []testCase{
 		{
 			name:              "Success test",
 			walletID:          validUUID.String(),
-			mockBalanceWallet: 5000,
+			mockBalanceWallet: models.Wallet{ID: validUUID, Balance: 5000, Currency: "RUB"},
 			mockError:         nil,
 			expectedStatus:    http.StatusAccepted,
-			expectedBody:      `"balance":5000`,
+			expectedBody:      `"balance":5000,"available":5000,"ledger":5000,"currency":"RUB","exponent":2`,
+		},
+		{
+			name:              "zero exponent currency",
+			walletID:          validUUID.String(),
+			mockBalanceWallet: models.Wallet{ID: validUUID, Balance: 700, Currency: "JPY"},
+			mockError:         nil,
+			expectedStatus:    http.StatusAccepted,
+			expectedBody:      `"balance":700,"available":700,"ledger":700,"currency":"JPY","exponent":0`,
+		},
+		{
+			name:              "active holds",
+			walletID:          validUUID.String(),
+			mockBalanceWallet: models.Wallet{ID: validUUID, Balance: 5000, Held: 1500, Currency: "RUB"},
+			mockError:         nil,
+			expectedStatus:    http.StatusAccepted,
+			expectedBody:      `"balance":5000,"available":3500,"ledger":5000`,
+		},
+		{
+			name:              "frozen wallet",
+			walletID:          validUUID.String(),
+			mockBalanceWallet: models.Wallet{ID: validUUID, Balance: 5000, Currency: "RUB", Status: models.WALLET_FROZEN},
+			mockError:         nil,
+			expectedStatus:    http.StatusAccepted,
+			expectedBody:      `"wallet_status":"FROZEN"`,
+		},
+		{
+			name:              "overdraft in use",
+			walletID:          validUUID.String(),
+			mockBalanceWallet: models.Wallet{ID: validUUID, Balance: -300, Currency: "RUB", OverdraftLimit: 1000},
+			mockError:         nil,
+			expectedStatus:    http.StatusAccepted,
+			expectedBody:      `"credit_line":1000,"credit_headroom":700`,
+		},
+		{
+			name:              "with owner",
+			walletID:          validUUID.String(),
+			mockBalanceWallet: models.Wallet{ID: validUUID, Balance: 5000, Currency: "RUB", OwnerID: "customer-42"},
+			mockError:         nil,
+			expectedStatus:    http.StatusAccepted,
+			expectedBody:      `"owner_id":"customer-42"`,
+		},
+		{
+			name:              "as of",
+			walletID:          validUUID.String(),
+			query:             "?as_of=2025-01-31T23:59:59Z",
+			asOf:              &asOf,
+			mockBalanceWallet: models.Wallet{ID: validUUID, Balance: 4200, Held: 200, Currency: "RUB", Status: models.WALLET_ACTIVE},
+			mockError:         nil,
+			expectedStatus:    http.StatusOK,
+			expectedBody:      `"as_of":"2025-01-31T23:59:59Z","balance":4200,"available":4000,"ledger":4200,"currency":"RUB"`,
+		},
+		{
+			name:              "as of wallet not found",
+			walletID:          validUUID.String(),
+			query:             "?as_of=2025-01-31T23:59:59Z",
+			asOf:              &asOf,
+			mockBalanceWallet: models.Wallet{},
+			mockError:         herrors.ErrNXUUID,
+			expectedStatus:    http.StatusBadRequest,
+			expectedBody:      "failed to find uuid",
+		},
+		{
+			name:           "as of in the future",
+			walletID:       validUUID.String(),
+			query:          "?as_of=2999-01-01T00:00:00Z",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "as_of must not be in the future",
+		},
+		{
+			name:           "as of invalid",
+			walletID:       validUUID.String(),
+			query:          "?as_of=yesterday",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to decode request",
 		},
 		{
 			name:              "Incorrect UUID",
 			walletID:          "I-n-c-o-r-r-e-c-t-uuid",
-			mockBalanceWallet: 0,
+			mockBalanceWallet: models.Wallet{},
 			mockError:         nil, //TODO распарсить ошибки валидатора
 			expectedStatus:    http.StatusBadRequest,
 			expectedBody:      "failed to decode request",
@@ -58,7 +143,7 @@ func TestNew(t *testing.T) {
 		{
 			name:              "wallet not found",
 			walletID:          validUUID.String(),
-			mockBalanceWallet: 0,
+			mockBalanceWallet: models.Wallet{},
 			mockError:         herrors.ErrNXUUID,
 			expectedStatus:    http.StatusInternalServerError,
 			expectedBody:      "failed to get balance",
@@ -71,11 +156,13 @@ func TestNew(t *testing.T) {
 
 			log := slog.New(slog.DiscardHandler)
 
-			if tc.walletID == validUUID.String() {
+			if tc.asOf != nil {
+				mockRepo.On("GetBalanceAsOf", mock.Anything, validUUID, *tc.asOf).Return(tc.mockBalanceWallet, tc.mockError)
+			} else if tc.walletID == validUUID.String() && tc.query == "" {
 				mockRepo.On("GetBalance", mock.Anything, validUUID).Return(tc.mockBalanceWallet, tc.mockError)
 			}
 
-			req, _ := http.NewRequest("GET", "/wallets/"+tc.walletID, nil)
+			req, _ := http.NewRequest("GET", "/wallets/"+tc.walletID+tc.query, nil)
 			w := httptest.NewRecorder()
 
 			r := gin.Default()
@@ -84,6 +171,7 @@ func TestNew(t *testing.T) {
 
 			assert.Equal(t, tc.expectedStatus, w.Code)
 			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			mockRepo.AssertExpectations(t)
 		})
 	}
 }
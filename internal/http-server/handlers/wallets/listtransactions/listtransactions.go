@@ -0,0 +1,129 @@
+package listtransactions
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/lib/errtranslate"
+	"wallets/internal/lib/pagination"
+	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofrs/uuid"
+)
+
+type Request struct {
+	OperationType models.OperationType `form:"operation_type" binding:"omitempty,oneof=DEPOSIT WITHDRAW TRANSFER_IN TRANSFER_OUT HOLD_CAPTURE REVERSAL_DEBIT REVERSAL_CREDIT FEE FEE_INCOME INTEREST"`
+	MinAmount     *int64               `form:"min_amount" binding:"omitempty,gte=1"`
+	MaxAmount     *int64               `form:"max_amount" binding:"omitempty,gte=1"`
+	// ExternalReference - найти операцию по ссылке из системы клиента
+	ExternalReference string     `form:"external_reference" binding:"omitempty,max=128"`
+	From              *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To                *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Cursor            string     `form:"cursor"`
+	Limit             int        `form:"limit" binding:"omitempty,gte=1,lte=100"`
+}
+
+type Response struct {
+	resp.Response
+	Transactions []models.Transactions `json:"transactions"`
+	NextCursor   string                `json:"next_cursor,omitempty"`
+}
+
+type transactionsLister interface {
+	ListTransactions(ctx context.Context, filter models.TransactionFilter) ([]models.Transactions, error)
+}
+
+func New(ctx context.Context, log *slog.Logger, repos transactionsLister) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "handlers.wallets.listtransactions.New"
+
+		log := log.With(slog.String("op", op), tracing.TraceID(c.Request.Context()))
+
+		walletID := uuid.UUID{}
+		if err := walletID.Parse(c.Param("uuid")); err != nil {
+			log.Error("failed to decode request parametr", sl.Err(err))
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		var req Request
+
+		if err := c.ShouldBindQuery(&req); err != nil {
+			log.Error("failed to decode query", sl.Err(err))
+
+			if validationErrs, ok := err.(validator.ValidationErrors); ok {
+				fieldErrors := errtranslate.TranslateValidationErrors(validationErrs)
+				msg := strings.Join(fieldErrors, ", ")
+				c.JSON(http.StatusBadRequest, resp.Error(msg))
+				return
+			}
+
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		if req.MinAmount != nil && req.MaxAmount != nil && *req.MinAmount > *req.MaxAmount {
+			c.JSON(http.StatusBadRequest, resp.Error("min_amount must be less than or equal to max_amount"))
+			return
+		}
+
+		if req.From != nil && req.To != nil && !req.From.Before(*req.To) {
+			c.JSON(http.StatusBadRequest, resp.Error("from must be before to"))
+			return
+		}
+
+		filter := models.TransactionFilter{
+			WalletID:          walletID,
+			OperationType:     req.OperationType,
+			MinAmount:         req.MinAmount,
+			MaxAmount:         req.MaxAmount,
+			ExternalReference: req.ExternalReference,
+			From:              req.From,
+			To:                req.To,
+			Limit:             req.Limit,
+		}
+
+		if filter.Limit == 0 {
+			filter.Limit = pagination.DefaultLimit
+		}
+
+		if req.Cursor != "" {
+			var after models.TransactionCursor
+			if err := pagination.DecodeCursor(req.Cursor, &after); err != nil {
+				log.Error("failed to decode cursor", sl.Err(err))
+				c.JSON(http.StatusBadRequest, resp.Error("invalid cursor"))
+				return
+			}
+			filter.After = &after
+		}
+
+		transactions, err := repos.ListTransactions(c.Request.Context(), filter)
+		if err != nil {
+			log.Error("failed to list transactions", sl.Err(err))
+			c.JSON(http.StatusInternalServerError, resp.Error("failed to list transactions"))
+			return
+		}
+
+		response := Response{
+			Response:     resp.OK(),
+			Transactions: transactions,
+		}
+
+		if len(transactions) == filter.Limit {
+			last := transactions[len(transactions)-1]
+			response.NextCursor = pagination.EncodeCursor(models.TransactionCursor{
+				CreatedAt: last.Created_at,
+				ID:        last.ID,
+			})
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
@@ -0,0 +1,187 @@
+package listtransactions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"wallets/internal/lib/pagination"
+	"wallets/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockTransactionsLister struct {
+	mock.Mock
+}
+
+func (m *mockTransactionsLister) ListTransactions(ctx context.Context, filter models.TransactionFilter) ([]models.Transactions, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]models.Transactions), args.Error(1)
+}
+
+func TestNew(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	walletID, _ := uuid.NewV4()
+	txID, _ := uuid.NewV4()
+	createdAt := time.Date(2025, 3, 29, 12, 0, 0, 0, time.UTC)
+
+	cursor := pagination.EncodeCursor(models.TransactionCursor{CreatedAt: createdAt, ID: txID})
+
+	tests := []struct {
+		name           string
+		walletID       string
+		query          string
+		filter         func(f models.TransactionFilter) bool
+		mockTxs        []models.Transactions
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+		expectCursor   bool
+	}{
+		{
+			name:     "Success with defaults",
+			walletID: walletID.String(),
+			filter: func(f models.TransactionFilter) bool {
+				return f.WalletID == walletID && f.Limit == pagination.DefaultLimit && f.After == nil
+			},
+			mockTxs:        []models.Transactions{{ID: txID, WalletID: walletID, OperationType: models.DEPOSIT, Amount: 100}},
+			expectedStatus: http.StatusOK,
+			expectedBody:   txID.String(),
+		},
+		{
+			name:     "filters and full page",
+			walletID: walletID.String(),
+			query:    "?operation_type=WITHDRAW&min_amount=10&max_amount=500&from=2025-03-01T00:00:00Z&to=2025-04-01T00:00:00Z&limit=1",
+			filter: func(f models.TransactionFilter) bool {
+				return f.OperationType == models.WITHDRAW &&
+					*f.MinAmount == 10 && *f.MaxAmount == 500 &&
+					f.From.Equal(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)) &&
+					f.To.Equal(time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)) &&
+					f.Limit == 1
+			},
+			mockTxs:        []models.Transactions{{ID: txID, WalletID: walletID, OperationType: models.WITHDRAW, Amount: 100, Created_at: createdAt}},
+			expectedStatus: http.StatusOK,
+			expectedBody:   txID.String(),
+			expectCursor:   true,
+		},
+		{
+			name:     "period with offset",
+			walletID: walletID.String(),
+			query:    "?from=2026-01-01T00:00:00%2B03:00&to=2026-01-02T00:00:00%2B03:00",
+			filter: func(f models.TransactionFilter) bool {
+				return f.From.Equal(time.Date(2025, 12, 31, 21, 0, 0, 0, time.UTC)) &&
+					f.To.Equal(time.Date(2026, 1, 1, 21, 0, 0, 0, time.UTC))
+			},
+			mockTxs:        []models.Transactions{},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"transactions":[]`,
+		},
+		{
+			name:     "with cursor",
+			walletID: walletID.String(),
+			query:    "?cursor=" + cursor,
+			filter: func(f models.TransactionFilter) bool {
+				return f.After != nil && f.After.ID == txID && f.After.CreatedAt.Equal(createdAt)
+			},
+			mockTxs:        []models.Transactions{},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"transactions":[]`,
+		},
+		{
+			name:     "by external reference",
+			walletID: walletID.String(),
+			query:    "?external_reference=order-A-1",
+			filter: func(f models.TransactionFilter) bool {
+				return f.ExternalReference == "order-A-1"
+			},
+			mockTxs:        []models.Transactions{{ID: txID, WalletID: walletID, ExternalReference: "order-A-1", Metadata: []byte(`{"order_id":"A-1"}`)}},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"Metadata":{"order_id":"A-1"},"ExternalReference":"order-A-1"`,
+		},
+		{
+			name:           "Incorrect UUID",
+			walletID:       "I-n-c-o-r-r-e-c-t-uuid",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to decode request",
+		},
+		{
+			name:           "invalid operation type",
+			walletID:       walletID.String(),
+			query:          "?operation_type=INVALID",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "OperationType must be in",
+		},
+		{
+			name:           "limit too big",
+			walletID:       walletID.String(),
+			query:          "?limit=1000",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Limit must be less than or equal to 100",
+		},
+		{
+			name:           "min greater than max",
+			walletID:       walletID.String(),
+			query:          "?min_amount=100&max_amount=10",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "min_amount must be less than or equal to max_amount",
+		},
+		{
+			name:           "invalid cursor",
+			walletID:       walletID.String(),
+			query:          "?cursor=not-a-cursor",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid cursor",
+		},
+		{
+			name:           "repo error",
+			walletID:       walletID.String(),
+			filter:         func(f models.TransactionFilter) bool { return true },
+			mockTxs:        []models.Transactions{},
+			mockError:      errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to list transactions",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(mockTransactionsLister)
+
+			log := slog.New(slog.DiscardHandler)
+
+			if tc.filter != nil {
+				mockRepo.On("ListTransactions", mock.Anything, mock.MatchedBy(tc.filter)).
+					Return(tc.mockTxs, tc.mockError).
+					Once()
+			}
+
+			req, _ := http.NewRequest("GET", "/wallets/"+tc.walletID+"/transactions"+tc.query, nil)
+			w := httptest.NewRecorder()
+
+			r := gin.New()
+			r.GET("/wallets/:uuid/transactions", New(context.Background(), log, mockRepo))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+
+			if tc.expectedStatus == http.StatusOK {
+				var response Response
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tc.expectCursor, response.NextCursor != "")
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
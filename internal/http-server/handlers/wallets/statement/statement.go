@@ -0,0 +1,159 @@
+package statement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	"wallets/internal/herrors"
+	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/lib/errtranslate"
+	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/statement"
+	"wallets/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofrs/uuid"
+)
+
+type Request struct {
+	From   time.Time  `form:"from" binding:"required" time_format:"2006-01-02T15:04:05Z07:00"`
+	To     *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Format string     `form:"format" binding:"omitempty,oneof=csv json ofx"`
+}
+
+type statementStreamer interface {
+	StreamStatement(ctx context.Context, walletID uuid.UUID, from, to time.Time, w models.StatementWriter) error
+}
+
+// flushLines - через сколько строк выписка сбрасывается клиенту, чтобы большой файл шел потоком
+const flushLines = 1000
+
+// responseWriter выставляет заголовки ответа, когда выписка начинается: до этого момента
+// ошибку еще можно вернуть обычным JSON ответом
+type responseWriter struct {
+	statement.Writer
+	c        *gin.Context
+	format   string
+	filename string
+	lines    int
+}
+
+func (w *responseWriter) Begin(s models.Statement) error {
+	w.c.Header("Content-Type", statement.ContentType(w.format))
+	w.c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, w.filename))
+	w.c.Status(http.StatusOK)
+
+	return w.Writer.Begin(s)
+}
+
+func (w *responseWriter) Line(line models.StatementLine) error {
+	if err := w.Writer.Line(line); err != nil {
+		return err
+	}
+
+	w.lines++
+	if w.lines%flushLines != 0 {
+		return nil
+	}
+
+	if err := w.Writer.Flush(); err != nil {
+		return err
+	}
+
+	w.c.Writer.Flush()
+
+	return nil
+}
+
+// New отдает выписку потоком. Общий WriteTimeout сервера рассчитан на короткие запросы и оборвал бы
+// выписку большого кошелька, поэтому для нее срок записи ответа свой - timeout.
+func New(ctx context.Context, log *slog.Logger, repos statementStreamer, timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "handlers.wallets.statement.New"
+
+		log := log.With(slog.String("op", op), tracing.TraceID(c.Request.Context()))
+
+		walletID := uuid.UUID{}
+		if err := walletID.Parse(c.Param("uuid")); err != nil {
+			log.Error("failed to decode request parametr", sl.Err(err))
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		var req Request
+
+		if err := c.ShouldBindQuery(&req); err != nil {
+			log.Error("failed to decode query", sl.Err(err))
+
+			if validationErrs, ok := err.(validator.ValidationErrors); ok {
+				fieldErrors := errtranslate.TranslateValidationErrors(validationErrs)
+				msg := strings.Join(fieldErrors, ", ")
+				c.JSON(http.StatusBadRequest, resp.Error(msg))
+				return
+			}
+
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		if req.Format == "" {
+			req.Format = statement.FormatCSV
+		}
+
+		// Выписка за еще не наступивший период обрезается текущим моментом
+		to := time.Now()
+		if req.To != nil && req.To.Before(to) {
+			to = *req.To
+		}
+
+		if !req.From.Before(to) {
+			c.JSON(http.StatusBadRequest, resp.Error("from must be before to"))
+			return
+		}
+
+		if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(timeout)); err != nil {
+			log.Warn("failed to extend write deadline", sl.Err(err))
+		}
+
+		writer, err := statement.NewWriter(req.Format, c.Writer)
+		if err != nil {
+			log.Error("failed to create statement writer", sl.Err(err))
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		err = repos.StreamStatement(c.Request.Context(), walletID, req.From, to, &responseWriter{
+			Writer: writer,
+			c:      c,
+			format: req.Format,
+			filename: fmt.Sprintf("statement_%s_%s_%s.%s", walletID,
+				req.From.UTC().Format("20060102"), to.UTC().Format("20060102"), req.Format),
+		})
+		if err != nil {
+			log.Error("failed to stream statement", sl.Err(err))
+
+			// Часть выписки уже отправлена и статус не поменять. Обрываем соединение без завершающего
+			// чанка, чтобы клиент увидел неполный ответ, а не файл без исходящего остатка с кодом 200.
+			if c.Writer.Written() {
+				panic(http.ErrAbortHandler)
+			}
+
+			c.Writer.Header().Del("Content-Type")
+			c.Writer.Header().Del("Content-Disposition")
+
+			if errors.Is(err, herrors.ErrNXUUID) {
+				c.JSON(http.StatusBadRequest, resp.Error("failed to find uuid"))
+				return
+			}
+
+			c.JSON(http.StatusInternalServerError, resp.Error("failed to get statement"))
+			return
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package statement
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"wallets/internal/herrors"
+	"wallets/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type testCase struct {
+	name                string
+	walletID            string
+	query               string
+	mockLines           []models.StatementLine
+	mockError           error
+	expectStream        bool
+	expectedStatus      int
+	expectedContentType string
+	expectedBody        string
+	expectFlushed       bool
+}
+
+type mockStatementStreamer struct {
+	mock.Mock
+}
+
+func (m *mockStatementStreamer) StreamStatement(ctx context.Context, walletID uuid.UUID, from, to time.Time, w models.StatementWriter) error {
+	args := m.Called(ctx, walletID, from, to, w)
+	return args.Error(0)
+}
+
+func TestNew(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	validUUID, _ := uuid.NewV4()
+	txID, _ := uuid.NewV4()
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	period := "?from=2025-01-01T00:00:00Z&to=2025-02-01T00:00:00Z"
+
+	lines := []models.StatementLine{
+		{ID: txID, OperationType: models.WITHDRAW, Amount: -250, Balance: 750, CreatedAt: time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)},
+	}
+
+	// Выписка длиннее flushLines уходит клиенту частями, не дожидаясь End
+	longLines := make([]models.StatementLine, flushLines+1)
+	for i := range longLines {
+		longLines[i] = models.StatementLine{ID: txID, OperationType: models.DEPOSIT, Amount: 1, Balance: int64(1001 + i), CreatedAt: from}
+	}
+
+	tests := []testCase{
+		{
+			name:                "csv by default",
+			walletID:            validUUID.String(),
+			query:               period,
+			mockLines:           lines,
+			expectStream:        true,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedBody:        "2025-01-15T12:00:00Z," + txID.String() + ",WITHDRAW,-2.50,RUB,7.50\n2025-02-01T00:00:00Z,,CLOSING_BALANCE,,RUB,7.50\n",
+		},
+		{
+			name:                "json",
+			walletID:            validUUID.String(),
+			query:               period + "&format=json",
+			mockLines:           lines,
+			expectStream:        true,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `"opening_balance":1000,"transactions":[{"id":"` + txID.String() + `","operation_type":"WITHDRAW","amount":-250,"balance":750,"created_at":"2025-01-15T12:00:00Z"}],"closing_balance":750}`,
+		},
+		{
+			name:                "ofx",
+			walletID:            validUUID.String(),
+			query:               period + "&format=ofx",
+			mockLines:           lines,
+			expectStream:        true,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/x-ofx",
+			expectedBody:        "<STMTTRN><TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>20250115120000.000[0:GMT]</DTPOSTED><TRNAMT>-2.50</TRNAMT>",
+		},
+		{
+			name:                "long statement is flushed while streaming",
+			walletID:            validUUID.String(),
+			query:               period,
+			mockLines:           longLines,
+			expectStream:        true,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedBody:        "2025-02-01T00:00:00Z,,CLOSING_BALANCE,,RUB,20.01\n",
+			expectFlushed:       true,
+		},
+		{
+			name:           "wallet not found",
+			walletID:       validUUID.String(),
+			query:          period,
+			mockError:      herrors.ErrNXUUID,
+			expectStream:   true,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to find uuid",
+		},
+		{
+			name:           "storage error",
+			walletID:       validUUID.String(),
+			query:          period,
+			mockError:      errors.New("connection refused"),
+			expectStream:   true,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to get statement",
+		},
+		{
+			name:           "unknown format",
+			walletID:       validUUID.String(),
+			query:          period + "&format=xlsx",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Format must be in (csv json ofx)",
+		},
+		{
+			name:           "missing from",
+			walletID:       validUUID.String(),
+			query:          "?to=2025-02-01T00:00:00Z",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "From is required",
+		},
+		{
+			name:           "from after to",
+			walletID:       validUUID.String(),
+			query:          "?from=2025-02-01T00:00:00Z&to=2025-01-01T00:00:00Z",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "from must be before to",
+		},
+		{
+			name:           "Incorrect UUID",
+			walletID:       "I-n-c-o-r-r-e-c-t-uuid",
+			query:          period,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to decode request",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(mockStatementStreamer)
+
+			log := slog.New(slog.DiscardHandler)
+
+			if tc.expectStream {
+				mockRepo.On("StreamStatement", mock.Anything, validUUID, from, to, mock.Anything).
+					Run(func(args mock.Arguments) {
+						if tc.mockError != nil {
+							return
+						}
+
+						w := args.Get(4).(models.StatementWriter)
+						_ = w.Begin(models.Statement{WalletID: validUUID, Currency: "RUB", Exponent: 2, From: from, To: to, OpeningBalance: 1000})
+						for _, line := range tc.mockLines {
+							_ = w.Line(line)
+						}
+						_ = w.End(tc.mockLines[len(tc.mockLines)-1].Balance)
+					}).
+					Return(tc.mockError)
+			}
+
+			req, _ := http.NewRequest("GET", "/wallets/"+tc.walletID+"/statement"+tc.query, nil)
+			w := httptest.NewRecorder()
+
+			r := gin.Default()
+			r.GET("/wallets/:uuid/statement", New(context.Background(), log, mockRepo, time.Minute))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			assert.Equal(t, tc.expectFlushed, w.Flushed)
+			if tc.expectedContentType != "" {
+				assert.Equal(t, tc.expectedContentType, w.Header().Get("Content-Type"))
+				assert.Contains(t, w.Header().Get("Content-Disposition"), "statement_"+validUUID.String()+"_20250101_20250201")
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNewAbortsInterruptedStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	walletID, _ := uuid.NewV4()
+
+	mockRepo := new(mockStatementStreamer)
+	mockRepo.On("StreamStatement", mock.Anything, walletID, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			w := args.Get(4).(models.StatementWriter)
+			_ = w.Begin(models.Statement{WalletID: walletID, Currency: "RUB", Exponent: 2})
+			_ = w.(*responseWriter).Flush()
+			w.(*responseWriter).c.Writer.Flush()
+		}).
+		Return(errors.New("connection reset"))
+
+	req, _ := http.NewRequest("GET", "/wallets/"+walletID.String()+"/statement?from=2025-01-01T00:00:00Z", nil)
+	w := httptest.NewRecorder()
+
+	r := gin.New()
+	r.GET("/wallets/:uuid/statement", New(context.Background(), slog.New(slog.DiscardHandler), mockRepo, time.Minute))
+
+	// net/http обрывает соединение на http.ErrAbortHandler, клиент не получает завершенный ответ
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() { r.ServeHTTP(w, req) })
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockRepo.AssertExpectations(t)
+}
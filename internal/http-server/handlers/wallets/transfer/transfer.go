@@ -0,0 +1,120 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"wallets/internal/herrors"
+	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/http-server/middleware/auth"
+	"wallets/internal/lib/errtranslate"
+	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofrs/uuid"
+)
+
+type Request struct {
+	FromID uuid.UUID `json:"from_wallet_id" binding:"required,uuid4"`
+	ToID   uuid.UUID `json:"to_wallet_id" binding:"required,uuid4"`
+	Amount int64     `json:"amount" binding:"required,gte=1"`
+}
+
+type Response struct {
+	resp.Response
+	Transfer models.Transfer `json:"transfer"`
+}
+
+type walletTransferrer interface {
+	Transfer(ctx context.Context, fromID, toID uuid.UUID, amount int64) (models.Transfer, error)
+}
+
+func New(ctx context.Context, log *slog.Logger, repos walletTransferrer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "handlers.wallets.transfer.New"
+
+		log := log.With("op", op, tracing.TraceID(c.Request.Context()))
+
+		var req Request
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+
+			if validationErrs, ok := err.(validator.ValidationErrors); ok {
+				fieldErrors := errtranslate.TranslateValidationErrors(validationErrs)
+				msg := strings.Join(fieldErrors, ", ")
+				c.JSON(http.StatusBadRequest, resp.Error(msg))
+				return
+			}
+
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		if !auth.WalletAllowed(c, req.FromID) {
+			c.JSON(http.StatusForbidden, resp.Error("api key is not allowed for this wallet"))
+			return
+		}
+
+		transfer, err := repos.Transfer(c.Request.Context(), req.FromID, req.ToID, req.Amount)
+		if err != nil {
+			log.Error("failed to transfer", sl.Err(err))
+
+			if errors.Is(err, herrors.ErrNXUUID) {
+				c.JSON(http.StatusBadRequest, resp.Error("failed to find uuid"))
+				return
+			}
+
+			if errors.Is(err, herrors.ErrSameWallet) {
+				c.JSON(http.StatusBadRequest, resp.Error("failed to transfer: source and destination wallets are the same"))
+				return
+			}
+
+			if errors.Is(err, herrors.ErrInsufficientFunds) {
+				c.JSON(http.StatusBadRequest, resp.Error("failed to transfer: insufficient funds"))
+				return
+			}
+
+			if errors.Is(err, herrors.ErrCurrencyMismatch) {
+				c.JSON(http.StatusBadRequest, resp.Error("failed to transfer: wallets have different currencies"))
+				return
+			}
+
+			if errors.Is(err, herrors.ErrWalletFrozen) {
+				c.JSON(http.StatusForbidden, resp.Error("failed to transfer: source wallet is frozen"))
+				return
+			}
+
+			var limitErr *herrors.LimitError
+			if errors.As(err, &limitErr) {
+				c.JSON(http.StatusForbidden, resp.Error(fmt.Sprintf("failed to transfer: limit exceeded: %s", limitErr.Limit)))
+				return
+			}
+
+			if errors.Is(err, herrors.ErrWalletClosed) {
+				c.JSON(http.StatusForbidden, resp.Error("failed to transfer: wallet is closed"))
+				return
+			}
+
+			if errors.Is(err, herrors.ErrNoRevenueWallet) {
+				c.JSON(http.StatusUnprocessableEntity, resp.Error("failed to transfer: fee revenue wallet is not available"))
+				return
+			}
+
+			c.JSON(http.StatusInternalServerError, resp.Error("failed to transfer"))
+			return
+		}
+
+		c.JSON(http.StatusAccepted, Response{
+			Response: resp.OK(),
+			Transfer: transfer,
+		})
+
+	}
+}
@@ -0,0 +1,238 @@
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallets/internal/herrors"
+	"wallets/internal/http-server/middleware/auth"
+	"wallets/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockWalletTransferrer struct {
+	mock.Mock
+}
+
+func (m *mockWalletTransferrer) Transfer(ctx context.Context, fromID, toID uuid.UUID, amount int64) (models.Transfer, error) {
+	args := m.Called(ctx, fromID, toID, amount)
+	return args.Get(0).(models.Transfer), args.Error(1)
+}
+
+func TestTransfer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	fromUUID, _ := uuid.NewV4()
+	toUUID, _ := uuid.NewV4()
+	transferUUID, _ := uuid.NewV4()
+
+	tests := []struct {
+		name           string
+		body           Request
+		mockTransfer   models.Transfer
+		mockError      error
+		expectRepoCall bool
+		apiKey         *models.APIKey
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Success",
+			body: Request{
+				FromID: fromUUID,
+				ToID:   toUUID,
+				Amount: 1000,
+			},
+			mockTransfer: models.Transfer{
+				ID:     transferUUID,
+				Amount: 1000,
+				Debit:  models.Transactions{WalletID: fromUUID, OperationType: models.TRANSFER_OUT, Amount: 1000},
+				Credit: models.Transactions{WalletID: toUUID, OperationType: models.TRANSFER_IN, Amount: 1000},
+			},
+			expectRepoCall: true,
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   transferUUID.String(),
+		},
+		{
+			name: "transfer with fee",
+			body: Request{
+				FromID: fromUUID,
+				ToID:   toUUID,
+				Amount: 1000,
+			},
+			mockTransfer: models.Transfer{
+				ID:     transferUUID,
+				Amount: 1000,
+				Debit:  models.Transactions{WalletID: fromUUID, OperationType: models.TRANSFER_OUT, Amount: 1000},
+				Credit: models.Transactions{WalletID: toUUID, OperationType: models.TRANSFER_IN, Amount: 1000},
+				Fee:    &models.Fee{Amount: 10, Currency: "RUB", Exponent: 2},
+			},
+			expectRepoCall: true,
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   `"fee":{"transaction_id":"` + uuid.Nil.String() + `","amount":10`,
+		},
+		{
+			name: "missing destination",
+			body: Request{
+				FromID: fromUUID,
+				Amount: 1000,
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "ToID is required",
+		},
+		{
+			name: "amount < 0",
+			body: Request{
+				FromID: fromUUID,
+				ToID:   toUUID,
+				Amount: -5,
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Amount must be greater than or equal to 1",
+		},
+		{
+			name: "same wallet",
+			body: Request{
+				FromID: fromUUID,
+				ToID:   fromUUID,
+				Amount: 100,
+			},
+			mockError:      herrors.ErrSameWallet,
+			expectRepoCall: true,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "source and destination wallets are the same",
+		},
+		{
+			name: "insufficient funds",
+			body: Request{
+				FromID: fromUUID,
+				ToID:   toUUID,
+				Amount: 100,
+			},
+			mockError:      herrors.ErrInsufficientFunds,
+			expectRepoCall: true,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "insufficient funds",
+		},
+		{
+			name: "currency mismatch",
+			body: Request{
+				FromID: fromUUID,
+				ToID:   toUUID,
+				Amount: 100,
+			},
+			mockError:      herrors.ErrCurrencyMismatch,
+			expectRepoCall: true,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "wallets have different currencies",
+		},
+		{
+			name: "frozen source wallet",
+			body: Request{
+				FromID: fromUUID,
+				ToID:   toUUID,
+				Amount: 100,
+			},
+			mockError:      herrors.ErrWalletFrozen,
+			expectRepoCall: true,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "source wallet is frozen",
+		},
+		{
+			name: "no fee revenue wallet",
+			body: Request{
+				FromID: fromUUID,
+				ToID:   toUUID,
+				Amount: 100,
+			},
+			mockError:      herrors.ErrNoRevenueWallet,
+			expectRepoCall: true,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   "fee revenue wallet is not available",
+		},
+		{
+			name: "max balance exceeded",
+			body: Request{
+				FromID: fromUUID,
+				ToID:   toUUID,
+				Amount: 100,
+			},
+			mockError:      &herrors.LimitError{Limit: models.LimitMaxBalance, Value: 50},
+			expectRepoCall: true,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "limit exceeded: max_balance",
+		},
+		{
+			name: "wallet not found",
+			body: Request{
+				FromID: fromUUID,
+				ToID:   toUUID,
+				Amount: 100,
+			},
+			mockError:      herrors.ErrNXUUID,
+			expectRepoCall: true,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to find uuid",
+		},
+		{
+			name: "repo error",
+			body: Request{
+				FromID: fromUUID,
+				ToID:   toUUID,
+				Amount: 100,
+			},
+			mockError:      errors.New("db error"),
+			expectRepoCall: true,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to transfer",
+		},
+		{
+			name: "api key restricted to another wallet",
+			body: Request{
+				FromID: fromUUID,
+				ToID:   toUUID,
+				Amount: 1000,
+			},
+			apiKey:         &models.APIKey{Scopes: []models.Scope{models.SCOPE_WALLET_WRITE}, WalletIDs: []uuid.UUID{toUUID}},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "api key is not allowed for this wallet",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := slog.New(slog.DiscardHandler)
+			mockRepo := new(mockWalletTransferrer)
+			if tc.expectRepoCall {
+				mockRepo.On("Transfer", mock.Anything, tc.body.FromID, tc.body.ToID, tc.body.Amount).
+					Return(tc.mockTransfer, tc.mockError).
+					Once()
+			}
+
+			reqBody, _ := json.Marshal(tc.body)
+			req, _ := http.NewRequest("POST", "/wallet/transfer", bytes.NewBuffer(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			r := gin.New()
+			if tc.apiKey != nil {
+				r.Use(func(c *gin.Context) { auth.SetAPIKey(c, *tc.apiKey) })
+			}
+			r.POST("/wallet/transfer", New(context.Background(), log, mockRepo))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
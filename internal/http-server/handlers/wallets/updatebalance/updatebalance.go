@@ -2,15 +2,22 @@ package updatebalance
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strings"
 	"wallets/internal/herrors"
 	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/http-server/middleware/auth"
 	"wallets/internal/lib/errtranslate"
+	"wallets/internal/lib/metadata"
 	"wallets/internal/lib/sl"
 	"wallets/internal/models"
+	"wallets/internal/tracing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -26,17 +33,27 @@ type Request struct {
 	ID        uuid.UUID            `json:"wallet_id" binding:"required,uuid4"`
 	Operation models.OperationType `json:"operation_type" binding:"required,oneof=DEPOSIT WITHDRAW"`
 	Amount    int64                `json:"amount" binding:"required,gte=1"`
+	Currency  string               `json:"currency,omitempty" binding:"omitempty,iso4217"`
+	// Description, Metadata и ExternalReference сохраняются в транзакции и возвращаются в истории операций
+	Description       string          `json:"description,omitempty" binding:"omitempty,max=255"`
+	Metadata          json.RawMessage `json:"metadata,omitempty"`
+	ExternalReference string          `json:"external_reference,omitempty" binding:"omitempty,max=128"`
 }
 
 type BalanceUpdater interface {
-	UpdateBalance(ctx context.Context, walletID uuid.UUID, operationType models.OperationType, amount int64) (models.Transactions, error)
+	UpdateBalance(ctx context.Context, walletID uuid.UUID, operationType models.OperationType, amount int64, opts models.TxOptions) (models.Transactions, error)
 }
 
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	maxIdempotencyKeyLen = 255
+)
+
 func New(ctx context.Context, log *slog.Logger, repos BalanceUpdater) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		const op = "handlers.wallets.updatebalance.New"
 
-		log := log.With("op", op)
+		log := log.With("op", op, tracing.TraceID(c.Request.Context()))
 
 		var req Request
 
@@ -54,10 +71,54 @@ func New(ctx context.Context, log *slog.Logger, repos BalanceUpdater) gin.Handle
 			return
 		}
 
-		tx, err := repos.UpdateBalance(ctx, req.ID, req.Operation, req.Amount)
+		if !auth.WalletAllowed(c, req.ID) {
+			c.JSON(http.StatusForbidden, resp.Error("api key is not allowed for this wallet"))
+			return
+		}
+
+		if err := metadata.Validate(req.Metadata); err != nil {
+			c.JSON(http.StatusBadRequest, resp.Error(err.Error()))
+			return
+		}
+
+		opts := models.TxOptions{
+			IdempotencyKey:    c.GetHeader(IdempotencyKeyHeader),
+			Currency:          req.Currency,
+			Description:       req.Description,
+			Metadata:          metadata.Normalize(req.Metadata),
+			ExternalReference: req.ExternalReference,
+		}
+
+		if len(opts.IdempotencyKey) > maxIdempotencyKeyLen {
+			c.JSON(http.StatusBadRequest, resp.Error(fmt.Sprintf("%s must be at most %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLen)))
+			return
+		}
+
+		if opts.IdempotencyKey != "" {
+			hash, err := requestHash(req)
+			if err != nil {
+				log.Error("failed to hash request", sl.Err(err))
+				c.JSON(http.StatusInternalServerError, resp.Error("failed to update balance"))
+				return
+			}
+
+			opts.RequestHash = hash
+		}
+
+		tx, err := repos.UpdateBalance(c.Request.Context(), req.ID, req.Operation, req.Amount, opts)
 		if err != nil {
 			log.Error("failed to update balance", sl.Err(err))
 
+			if errors.Is(err, herrors.ErrIdempotencyConflict) {
+				c.JSON(http.StatusConflict, resp.Error("idempotency key is already used with a different request"))
+				return
+			}
+
+			if errors.Is(err, herrors.ErrDuplicateExternalReference) {
+				c.JSON(http.StatusConflict, resp.Error("external_reference is already used for this wallet"))
+				return
+			}
+
 			if errors.Is(err, herrors.ErrNXUUID) {
 				c.JSON(http.StatusBadRequest, resp.Error("failed to find uuid"))
 				return
@@ -68,6 +129,32 @@ func New(ctx context.Context, log *slog.Logger, repos BalanceUpdater) gin.Handle
 				return
 			}
 
+			if errors.Is(err, herrors.ErrCurrencyMismatch) {
+				c.JSON(http.StatusBadRequest, resp.Error("currency does not match wallet currency"))
+				return
+			}
+
+			if errors.Is(err, herrors.ErrWalletFrozen) {
+				c.JSON(http.StatusForbidden, resp.Error("failed to WITHDRAW: wallet is frozen"))
+				return
+			}
+
+			var limitErr *herrors.LimitError
+			if errors.As(err, &limitErr) {
+				c.JSON(http.StatusForbidden, resp.Error(fmt.Sprintf("limit exceeded: %s", limitErr.Limit)))
+				return
+			}
+
+			if errors.Is(err, herrors.ErrWalletClosed) {
+				c.JSON(http.StatusForbidden, resp.Error("wallet is closed"))
+				return
+			}
+
+			if errors.Is(err, herrors.ErrNoRevenueWallet) {
+				c.JSON(http.StatusUnprocessableEntity, resp.Error("fee revenue wallet is not available"))
+				return
+			}
+
 			c.JSON(http.StatusInternalServerError, resp.Error("failed to update balance"))
 			return
 		}
@@ -76,3 +163,17 @@ func New(ctx context.Context, log *slog.Logger, repos BalanceUpdater) gin.Handle
 
 	}
 }
+
+// requestHash - отпечаток запроса для сравнения повторов с одним Idempotency-Key.
+// Метаданные берутся в том виде, в каком сохраняются, поэтому пробелы и порядок ключей на него не влияют.
+func requestHash(req Request) (string, error) {
+	req.Metadata = metadata.Normalize(req.Metadata)
+
+	raw, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
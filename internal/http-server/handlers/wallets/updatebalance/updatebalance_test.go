@@ -5,16 +5,21 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"wallets/internal/herrors"
+	"wallets/internal/http-server/middleware/auth"
 	"wallets/internal/models"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gofrs/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // Мок репозитория
@@ -22,8 +27,8 @@ type mockBalanceUpdater struct {
 	mock.Mock
 }
 
-func (m *mockBalanceUpdater) UpdateBalance(ctx context.Context, walletID uuid.UUID, operationType models.OperationType, amount int64) (models.Transactions, error) {
-	args := m.Called(ctx, walletID, operationType, amount)
+func (m *mockBalanceUpdater) UpdateBalance(ctx context.Context, walletID uuid.UUID, operationType models.OperationType, amount int64, opts models.TxOptions) (models.Transactions, error) {
+	args := m.Called(ctx, walletID, operationType, amount, opts)
 	return args.Get(0).(models.Transactions), args.Error(1)
 }
 
@@ -38,6 +43,7 @@ func TestUpdateBalance(t *testing.T) {
 		body           Request
 		mockTx         models.Transactions
 		mockError      error
+		apiKey         *models.APIKey
 		expectedStatus int
 		expectedBody   string
 	}{
@@ -58,6 +64,29 @@ func TestUpdateBalance(t *testing.T) {
 			expectedStatus: http.StatusAccepted,
 			expectedBody:   transactionUUID.String(),
 		},
+		{
+			name: "withdraw with fee",
+			body: Request{
+				ID:        validUUID,
+				Operation: models.WITHDRAW,
+				Amount:    1000,
+			},
+			mockTx: models.Transactions{
+				ID:            transactionUUID,
+				WalletID:      validUUID,
+				OperationType: models.WITHDRAW,
+				Amount:        1000,
+				Fee: &models.Fee{
+					TransactionID:   validUUID,
+					Amount:          15,
+					Currency:        "RUB",
+					Exponent:        2,
+					RevenueWalletID: validUUID,
+				},
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   `"Fee":{"transaction_id":"` + validUUID.String() + `","amount":15,"currency":"RUB","exponent":2,"revenue_wallet_id":"` + validUUID.String() + `"}`,
+		},
 		{
 			name: "Invalid UUID",
 			body: Request{
@@ -105,6 +134,80 @@ func TestUpdateBalance(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   "Amount must be greater than or equal to 1", // TODO move to const errtranslate.go
 		},
+		{
+			name: "invalid currency",
+			body: Request{
+				ID:        validUUID,
+				Operation: models.DEPOSIT,
+				Amount:    500,
+				Currency:  "rub",
+			},
+			mockTx:         models.Transactions{},
+			mockError:      nil,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Currency must be a valid ISO 4217 currency code",
+		},
+		{
+			name: "currency mismatch",
+			body: Request{
+				ID:        validUUID,
+				Operation: models.DEPOSIT,
+				Amount:    500,
+				Currency:  "USD",
+			},
+			mockTx:         models.Transactions{},
+			mockError:      herrors.ErrCurrencyMismatch,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "currency does not match wallet currency",
+		},
+		{
+			name: "withdraw from frozen wallet",
+			body: Request{
+				ID:        validUUID,
+				Operation: models.WITHDRAW,
+				Amount:    500,
+			},
+			mockTx:         models.Transactions{},
+			mockError:      herrors.ErrWalletFrozen,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "failed to WITHDRAW: wallet is frozen",
+		},
+		{
+			name: "deposit to closed wallet",
+			body: Request{
+				ID:        validUUID,
+				Operation: models.DEPOSIT,
+				Amount:    500,
+			},
+			mockTx:         models.Transactions{},
+			mockError:      herrors.ErrWalletClosed,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "wallet is closed",
+		},
+		{
+			name: "withdraw without fee revenue wallet",
+			body: Request{
+				ID:        validUUID,
+				Operation: models.WITHDRAW,
+				Amount:    500,
+			},
+			mockTx:         models.Transactions{},
+			mockError:      fmt.Errorf("storage: %w", herrors.ErrNoRevenueWallet),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   "fee revenue wallet is not available",
+		},
+		{
+			name: "daily limit exceeded",
+			body: Request{
+				ID:        validUUID,
+				Operation: models.WITHDRAW,
+				Amount:    500,
+			},
+			mockTx:         models.Transactions{},
+			mockError:      fmt.Errorf("storage: %w", &herrors.LimitError{Limit: models.LimitDailyWithdrawal, Value: 1000}),
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "limit exceeded: daily_withdrawal",
+		},
 		{
 			name: "repo update balance error",
 			body: Request{
@@ -117,6 +220,17 @@ func TestUpdateBalance(t *testing.T) {
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   "failed to update balance",
 		},
+		{
+			name: "api key restricted to another wallet",
+			body: Request{
+				ID:        validUUID,
+				Operation: models.WITHDRAW,
+				Amount:    500,
+			},
+			apiKey:         &models.APIKey{Scopes: []models.Scope{models.SCOPE_WALLET_WRITE}, WalletIDs: []uuid.UUID{transactionUUID}},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "api key is not allowed for this wallet",
+		},
 	}
 
 	for _, tc := range tests {
@@ -126,7 +240,7 @@ func TestUpdateBalance(t *testing.T) {
 
 			log := slog.New(slog.DiscardHandler)
 			mockRepo := new(mockBalanceUpdater)
-			mockRepo.On("UpdateBalance", mock.Anything, tc.body.ID, tc.body.Operation, tc.body.Amount).Return(tc.mockTx, tc.mockError)
+			mockRepo.On("UpdateBalance", mock.Anything, tc.body.ID, tc.body.Operation, tc.body.Amount, mock.Anything).Return(tc.mockTx, tc.mockError)
 
 			reqBody, _ := json.Marshal(tc.body)
 			req, _ := http.NewRequest("POST", "/wallet", bytes.NewBuffer(reqBody))
@@ -134,11 +248,220 @@ func TestUpdateBalance(t *testing.T) {
 
 			w := httptest.NewRecorder()
 			r := gin.Default()
+			if tc.apiKey != nil {
+				r.Use(func(c *gin.Context) { auth.SetAPIKey(c, *tc.apiKey) })
+			}
+			r.POST("/wallet", New(context.Background(), log, mockRepo))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+		})
+	}
+}
+
+func TestUpdateBalanceIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	validUUID, _ := uuid.NewV4()
+	transactionUUID, _ := uuid.NewV4()
+
+	body := Request{
+		ID:        validUUID,
+		Operation: models.DEPOSIT,
+		Amount:    1000,
+	}
+
+	tests := []struct {
+		name           string
+		key            string
+		mockError      error
+		expectRepoCall bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "key is passed to repo",
+			key:            "order-42",
+			expectRepoCall: true,
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   transactionUUID.String(),
+		},
+		{
+			name:           "key reused with different body",
+			key:            "order-42",
+			mockError:      herrors.ErrIdempotencyConflict,
+			expectRepoCall: true,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "idempotency key is already used with a different request",
+		},
+		{
+			name:           "key too long",
+			key:            strings.Repeat("k", 256),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Idempotency-Key must be at most 255 characters",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := slog.New(slog.DiscardHandler)
+			mockRepo := new(mockBalanceUpdater)
+
+			if tc.expectRepoCall {
+				hash, err := requestHash(body)
+				require.NoError(t, err)
+
+				expectedOpts := models.TxOptions{IdempotencyKey: tc.key, RequestHash: hash}
+				mockRepo.On("UpdateBalance", mock.Anything, body.ID, body.Operation, body.Amount, expectedOpts).
+					Return(models.Transactions{ID: transactionUUID}, tc.mockError).
+					Once()
+			}
+
+			reqBody, _ := json.Marshal(body)
+			req, _ := http.NewRequest("POST", "/wallet", bytes.NewBuffer(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(IdempotencyKeyHeader, tc.key)
+
+			w := httptest.NewRecorder()
+			r := gin.New()
+			r.POST("/wallet", New(context.Background(), log, mockRepo))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRequestHash(t *testing.T) {
+	walletID, _ := uuid.NewV4()
+
+	deposit := Request{ID: walletID, Operation: models.DEPOSIT, Amount: 100}
+	withdraw := Request{ID: walletID, Operation: models.WITHDRAW, Amount: 100}
+
+	depositHash, err := requestHash(deposit)
+	require.NoError(t, err)
+
+	withdrawHash, err := requestHash(withdraw)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, depositHash, withdrawHash)
+
+	// Повтор с переформатированными метаданными - тот же запрос
+	original := Request{ID: walletID, Operation: models.DEPOSIT, Amount: 100,
+		Metadata: json.RawMessage(`{"order_id":"A-1","amount":1.50}`)}
+	retry := Request{ID: walletID, Operation: models.DEPOSIT, Amount: 100,
+		Metadata: json.RawMessage("{\n  \"amount\": 1.50,\n  \"order_id\": \"A-1\"\n}")}
+	changed := Request{ID: walletID, Operation: models.DEPOSIT, Amount: 100,
+		Metadata: json.RawMessage(`{"order_id":"A-2","amount":1.50}`)}
+
+	originalHash, err := requestHash(original)
+	require.NoError(t, err)
+
+	retryHash, err := requestHash(retry)
+	require.NoError(t, err)
+
+	changedHash, err := requestHash(changed)
+	require.NoError(t, err)
+
+	assert.Equal(t, originalHash, retryHash)
+	assert.NotEqual(t, originalHash, changedHash)
+}
+
+func TestUpdateBalanceTransactionDetails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	validUUID, _ := uuid.NewV4()
+	transactionUUID, _ := uuid.NewV4()
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedOpts   *models.TxOptions
+		mockTx         models.Transactions
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "details are stored and returned",
+			body: `{"wallet_id":"` + validUUID.String() + `","operation_type":"DEPOSIT","amount":1000,` +
+				`"description":"Order payment","metadata":{"order_id":"A-1"},"external_reference":"order-A-1"}`,
+			expectedOpts: &models.TxOptions{
+				Description:       "Order payment",
+				Metadata:          json.RawMessage(`{"order_id":"A-1"}`),
+				ExternalReference: "order-A-1",
+			},
+			mockTx: models.Transactions{
+				ID:                transactionUUID,
+				Description:       "Order payment",
+				Metadata:          json.RawMessage(`{"order_id":"A-1"}`),
+				ExternalReference: "order-A-1",
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   `"Description":"Order payment","Metadata":{"order_id":"A-1"},"ExternalReference":"order-A-1"`,
+		},
+		{
+			name:           "null metadata",
+			body:           `{"wallet_id":"` + validUUID.String() + `","operation_type":"DEPOSIT","amount":1000,"metadata":null}`,
+			expectedOpts:   &models.TxOptions{},
+			mockTx:         models.Transactions{ID: transactionUUID},
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   transactionUUID.String(),
+		},
+		{
+			name:           "duplicate external reference",
+			body:           `{"wallet_id":"` + validUUID.String() + `","operation_type":"DEPOSIT","amount":1000,"external_reference":"order-A-1"}`,
+			expectedOpts:   &models.TxOptions{ExternalReference: "order-A-1"},
+			mockError:      herrors.ErrDuplicateExternalReference,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "external_reference is already used for this wallet",
+		},
+		{
+			name:           "metadata is not an object",
+			body:           `{"wallet_id":"` + validUUID.String() + `","operation_type":"DEPOSIT","amount":1000,"metadata":["A-1"]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "metadata must be a JSON object",
+		},
+		{
+			name: "metadata too large",
+			body: `{"wallet_id":"` + validUUID.String() + `","operation_type":"DEPOSIT","amount":1000,` +
+				`"metadata":{"note":"` + strings.Repeat("x", 4096) + `"}}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "metadata must be at most 4096 bytes",
+		},
+		{
+			name:           "external reference too long",
+			body:           `{"wallet_id":"` + validUUID.String() + `","operation_type":"DEPOSIT","amount":1000,"external_reference":"` + strings.Repeat("r", 129) + `"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "ExternalReference must be at most 128 characters long",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := slog.New(slog.DiscardHandler)
+			mockRepo := new(mockBalanceUpdater)
+
+			if tc.expectedOpts != nil {
+				mockRepo.On("UpdateBalance", mock.Anything, validUUID, models.DEPOSIT, int64(1000), *tc.expectedOpts).
+					Return(tc.mockTx, tc.mockError).
+					Once()
+			}
+
+			req, _ := http.NewRequest("POST", "/wallet", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			r := gin.New()
 			r.POST("/wallet", New(context.Background(), log, mockRepo))
 			r.ServeHTTP(w, req)
 
 			assert.Equal(t, tc.expectedStatus, w.Code)
 			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			mockRepo.AssertExpectations(t)
 		})
 	}
 }
@@ -0,0 +1,96 @@
+package createwebhook
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"wallets/internal/herrors"
+	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/http-server/middleware/auth"
+	"wallets/internal/lib/errtranslate"
+	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/tracing"
+	"wallets/internal/webhooks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofrs/uuid"
+)
+
+// Request - без wallet_id подписка получает события всех кошельков
+type Request struct {
+	URL      string     `json:"url" binding:"required,http_url"`
+	WalletID *uuid.UUID `json:"wallet_id"`
+}
+
+type Response struct {
+	resp.Response
+	Webhook models.WebhookSubscription `json:"webhook"`
+}
+
+type webhookCreator interface {
+	CreateWebhook(ctx context.Context, url string, walletID uuid.NullUUID, secret string) (models.WebhookSubscription, error)
+}
+
+func New(ctx context.Context, log *slog.Logger, repos webhookCreator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "handlers.webhooks.createwebhook.New"
+
+		log := log.With(slog.String("op", op), tracing.TraceID(c.Request.Context()))
+
+		var req Request
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			log.Error("failed to decode request", sl.Err(err))
+
+			if validationErrs, ok := err.(validator.ValidationErrors); ok {
+				fieldErrors := errtranslate.TranslateValidationErrors(validationErrs)
+				msg := strings.Join(fieldErrors, ", ")
+				c.JSON(http.StatusBadRequest, resp.Error(msg))
+				return
+			}
+
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		var walletID uuid.NullUUID
+		if req.WalletID != nil {
+			walletID = uuid.NullUUID{UUID: *req.WalletID, Valid: true}
+		}
+
+		// Ключ с ограничением по кошелькам не может подписаться на чужие кошельки и на все сразу
+		if !auth.WalletAllowed(c, walletID.UUID) {
+			c.JSON(http.StatusForbidden, resp.Error("api key is not allowed for this wallet"))
+			return
+		}
+
+		secret, err := webhooks.NewSecret()
+		if err != nil {
+			log.Error("failed to generate webhook secret", sl.Err(err))
+			c.JSON(http.StatusInternalServerError, resp.Error("failed to create webhook"))
+			return
+		}
+
+		webhook, err := repos.CreateWebhook(c.Request.Context(), req.URL, walletID, secret)
+		if err != nil {
+			log.Error("failed to create webhook", sl.Err(err))
+
+			if errors.Is(err, herrors.ErrNXUUID) {
+				c.JSON(http.StatusBadRequest, resp.Error("failed to find uuid"))
+				return
+			}
+
+			c.JSON(http.StatusInternalServerError, resp.Error("failed to create webhook"))
+			return
+		}
+
+		c.JSON(http.StatusCreated, Response{
+			Response: resp.OK(),
+			Webhook:  webhook,
+		})
+	}
+}
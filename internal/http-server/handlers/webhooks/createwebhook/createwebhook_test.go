@@ -0,0 +1,158 @@
+package createwebhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallets/internal/herrors"
+	"wallets/internal/http-server/middleware/auth"
+	"wallets/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockWebhookCreator struct {
+	mock.Mock
+}
+
+func (m *mockWebhookCreator) CreateWebhook(ctx context.Context, url string, walletID uuid.NullUUID, secret string) (models.WebhookSubscription, error) {
+	args := m.Called(ctx, url, walletID, secret)
+	return args.Get(0).(models.WebhookSubscription), args.Error(1)
+}
+
+func TestNew(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	walletID, _ := uuid.NewV4()
+	otherWallet, _ := uuid.NewV4()
+	webhookID, _ := uuid.NewV4()
+
+	restricted := &models.APIKey{Scopes: []models.Scope{models.SCOPE_WEBHOOKS}, WalletIDs: []uuid.UUID{walletID}}
+
+	tests := []struct {
+		name           string
+		body           string
+		walletID       uuid.NullUUID
+		apiKey         *models.APIKey
+		callRepo       bool
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Success global",
+			body:           `{"url": "https://example.com/hook"}`,
+			callRepo:       true,
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"url":"https://example.com/hook"`,
+		},
+		{
+			name:           "Success wallet",
+			body:           `{"url": "https://example.com/hook", "wallet_id": "` + walletID.String() + `"}`,
+			walletID:       uuid.NullUUID{UUID: walletID, Valid: true},
+			callRepo:       true,
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"wallet_id":"` + walletID.String() + `"`,
+		},
+		{
+			name:           "restricted key on allowed wallet",
+			body:           `{"url": "https://example.com/hook", "wallet_id": "` + walletID.String() + `"}`,
+			walletID:       uuid.NullUUID{UUID: walletID, Valid: true},
+			apiKey:         restricted,
+			callRepo:       true,
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"wallet_id":"` + walletID.String() + `"`,
+		},
+		{
+			name:           "restricted key on other wallet",
+			body:           `{"url": "https://example.com/hook", "wallet_id": "` + otherWallet.String() + `"}`,
+			apiKey:         restricted,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "api key is not allowed for this wallet",
+		},
+		{
+			name:           "restricted key global subscription",
+			body:           `{"url": "https://example.com/hook"}`,
+			apiKey:         restricted,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "api key is not allowed for this wallet",
+		},
+		{
+			name:           "missing url",
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "URL is required",
+		},
+		{
+			name:           "invalid url",
+			body:           `{"url": "ftp://example.com"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "URL must be a valid http or https url",
+		},
+		{
+			name:           "invalid wallet id",
+			body:           `{"url": "https://example.com/hook", "wallet_id": "I-n-c-o-r-r-e-c-t-uuid"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to decode request",
+		},
+		{
+			name:           "wallet not found",
+			body:           `{"url": "https://example.com/hook", "wallet_id": "` + walletID.String() + `"}`,
+			walletID:       uuid.NullUUID{UUID: walletID, Valid: true},
+			callRepo:       true,
+			mockError:      herrors.ErrNXUUID,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to find uuid",
+		},
+		{
+			name:           "repo error",
+			body:           `{"url": "https://example.com/hook"}`,
+			callRepo:       true,
+			mockError:      errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to create webhook",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := slog.New(slog.DiscardHandler)
+			mockRepo := new(mockWebhookCreator)
+
+			if tc.callRepo {
+				secretGenerated := mock.MatchedBy(func(secret string) bool { return len(secret) == 64 })
+
+				mockRepo.On("CreateWebhook", mock.Anything, "https://example.com/hook", tc.walletID, secretGenerated).
+					Return(models.WebhookSubscription{
+						ID:       webhookID,
+						URL:      "https://example.com/hook",
+						WalletID: tc.walletID,
+						Active:   true,
+					}, tc.mockError).
+					Once()
+			}
+
+			req, _ := http.NewRequest("POST", "/webhooks", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			r := gin.New()
+			if tc.apiKey != nil {
+				r.Use(func(c *gin.Context) { auth.SetAPIKey(c, *tc.apiKey) })
+			}
+			r.POST("/webhooks", New(context.Background(), log, mockRepo))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
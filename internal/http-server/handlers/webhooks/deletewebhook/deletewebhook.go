@@ -0,0 +1,48 @@
+package deletewebhook
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"wallets/internal/herrors"
+	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/lib/sl"
+	"wallets/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+)
+
+type webhookDeleter interface {
+	DeleteWebhook(ctx context.Context, id uuid.UUID) error
+}
+
+func New(ctx context.Context, log *slog.Logger, repos webhookDeleter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "handlers.webhooks.deletewebhook.New"
+
+		log := log.With(slog.String("op", op), tracing.TraceID(c.Request.Context()))
+
+		webhookID := uuid.UUID{}
+		if err := webhookID.Parse(c.Param("id")); err != nil {
+			log.Error("failed to decode request parametr", sl.Err(err))
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		if err := repos.DeleteWebhook(c.Request.Context(), webhookID); err != nil {
+			log.Error("failed to delete webhook", sl.Err(err))
+
+			if errors.Is(err, herrors.ErrWebhookNotFound) {
+				c.JSON(http.StatusNotFound, resp.Error("webhook not found"))
+				return
+			}
+
+			c.JSON(http.StatusInternalServerError, resp.Error("failed to delete webhook"))
+			return
+		}
+
+		c.JSON(http.StatusOK, resp.OK())
+	}
+}
@@ -0,0 +1,92 @@
+package deletewebhook
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallets/internal/herrors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockWebhookDeleter struct {
+	mock.Mock
+}
+
+func (m *mockWebhookDeleter) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestNew(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	webhookID, _ := uuid.NewV4()
+
+	tests := []struct {
+		name           string
+		webhookID      string
+		callRepo       bool
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Success",
+			webhookID:      webhookID.String(),
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"status":"OK"`,
+		},
+		{
+			name:           "Incorrect UUID",
+			webhookID:      "I-n-c-o-r-r-e-c-t-uuid",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to decode request",
+		},
+		{
+			name:           "webhook not found",
+			webhookID:      webhookID.String(),
+			callRepo:       true,
+			mockError:      herrors.ErrWebhookNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "webhook not found",
+		},
+		{
+			name:           "repo error",
+			webhookID:      webhookID.String(),
+			callRepo:       true,
+			mockError:      errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to delete webhook",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := slog.New(slog.DiscardHandler)
+			mockRepo := new(mockWebhookDeleter)
+
+			if tc.callRepo {
+				mockRepo.On("DeleteWebhook", mock.Anything, webhookID).Return(tc.mockError).Once()
+			}
+
+			req, _ := http.NewRequest("DELETE", "/webhooks/"+tc.webhookID, nil)
+
+			w := httptest.NewRecorder()
+			r := gin.New()
+			r.DELETE("/webhooks/:id", New(context.Background(), log, mockRepo))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
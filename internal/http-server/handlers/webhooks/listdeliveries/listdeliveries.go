@@ -0,0 +1,86 @@
+package listdeliveries
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"wallets/internal/herrors"
+	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/lib/errtranslate"
+	"wallets/internal/lib/pagination"
+	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofrs/uuid"
+)
+
+type Request struct {
+	Limit int `form:"limit" binding:"omitempty,gte=1,lte=100"`
+}
+
+type Response struct {
+	resp.Response
+	Deliveries []models.WebhookDelivery `json:"deliveries"`
+}
+
+type deliveriesLister interface {
+	ListWebhookDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]models.WebhookDelivery, error)
+}
+
+func New(ctx context.Context, log *slog.Logger, repos deliveriesLister) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "handlers.webhooks.listdeliveries.New"
+
+		log := log.With(slog.String("op", op), tracing.TraceID(c.Request.Context()))
+
+		webhookID := uuid.UUID{}
+		if err := webhookID.Parse(c.Param("id")); err != nil {
+			log.Error("failed to decode request parametr", sl.Err(err))
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		var req Request
+
+		if err := c.ShouldBindQuery(&req); err != nil {
+			log.Error("failed to decode query", sl.Err(err))
+
+			if validationErrs, ok := err.(validator.ValidationErrors); ok {
+				fieldErrors := errtranslate.TranslateValidationErrors(validationErrs)
+				msg := strings.Join(fieldErrors, ", ")
+				c.JSON(http.StatusBadRequest, resp.Error(msg))
+				return
+			}
+
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		if req.Limit == 0 {
+			req.Limit = pagination.DefaultLimit
+		}
+
+		deliveries, err := repos.ListWebhookDeliveries(c.Request.Context(), webhookID, req.Limit)
+		if err != nil {
+			log.Error("failed to list webhook deliveries", sl.Err(err))
+
+			if errors.Is(err, herrors.ErrWebhookNotFound) {
+				c.JSON(http.StatusNotFound, resp.Error("webhook not found"))
+				return
+			}
+
+			c.JSON(http.StatusInternalServerError, resp.Error("failed to list webhook deliveries"))
+			return
+		}
+
+		c.JSON(http.StatusOK, Response{
+			Response:   resp.OK(),
+			Deliveries: deliveries,
+		})
+	}
+}
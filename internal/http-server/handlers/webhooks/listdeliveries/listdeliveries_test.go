@@ -0,0 +1,118 @@
+package listdeliveries
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallets/internal/herrors"
+	"wallets/internal/lib/pagination"
+	"wallets/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockDeliveriesLister struct {
+	mock.Mock
+}
+
+func (m *mockDeliveriesLister) ListWebhookDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]models.WebhookDelivery, error) {
+	args := m.Called(ctx, subscriptionID, limit)
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func TestNew(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	webhookID, _ := uuid.NewV4()
+	deliveryID, _ := uuid.NewV4()
+
+	tests := []struct {
+		name           string
+		webhookID      string
+		query          string
+		limit          int
+		callRepo       bool
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Success",
+			webhookID:      webhookID.String(),
+			limit:          pagination.DefaultLimit,
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"id":"` + deliveryID.String() + `"`,
+		},
+		{
+			name:           "custom limit",
+			webhookID:      webhookID.String(),
+			query:          "?limit=10",
+			limit:          10,
+			callRepo:       true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"status":"OK"`,
+		},
+		{
+			name:           "limit too big",
+			webhookID:      webhookID.String(),
+			query:          "?limit=1000",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Limit must be less than or equal to 100",
+		},
+		{
+			name:           "Incorrect UUID",
+			webhookID:      "I-n-c-o-r-r-e-c-t-uuid",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to decode request",
+		},
+		{
+			name:           "webhook not found",
+			webhookID:      webhookID.String(),
+			limit:          pagination.DefaultLimit,
+			callRepo:       true,
+			mockError:      herrors.ErrWebhookNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "webhook not found",
+		},
+		{
+			name:           "repo error",
+			webhookID:      webhookID.String(),
+			limit:          pagination.DefaultLimit,
+			callRepo:       true,
+			mockError:      errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to list webhook deliveries",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := slog.New(slog.DiscardHandler)
+			mockRepo := new(mockDeliveriesLister)
+
+			if tc.callRepo {
+				mockRepo.On("ListWebhookDeliveries", mock.Anything, webhookID, tc.limit).
+					Return([]models.WebhookDelivery{{ID: deliveryID, SubscriptionID: webhookID, Status: models.DELIVERY_FAILED}}, tc.mockError).
+					Once()
+			}
+
+			req, _ := http.NewRequest("GET", "/webhooks/"+tc.webhookID+"/deliveries"+tc.query, nil)
+
+			w := httptest.NewRecorder()
+			r := gin.New()
+			r.GET("/webhooks/:id/deliveries", New(context.Background(), log, mockRepo))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
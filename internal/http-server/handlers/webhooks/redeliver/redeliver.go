@@ -0,0 +1,65 @@
+package redeliver
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"wallets/internal/herrors"
+	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+)
+
+type Response struct {
+	resp.Response
+	Delivery models.WebhookDelivery `json:"delivery"`
+}
+
+type webhookRedeliverer interface {
+	RedeliverWebhook(ctx context.Context, subscriptionID, deliveryID uuid.UUID) (models.WebhookDelivery, error)
+}
+
+func New(ctx context.Context, log *slog.Logger, repos webhookRedeliverer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "handlers.webhooks.redeliver.New"
+
+		log := log.With(slog.String("op", op), tracing.TraceID(c.Request.Context()))
+
+		webhookID := uuid.UUID{}
+		if err := webhookID.Parse(c.Param("id")); err != nil {
+			log.Error("failed to decode request parametr", sl.Err(err))
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		deliveryID := uuid.UUID{}
+		if err := deliveryID.Parse(c.Param("delivery_id")); err != nil {
+			log.Error("failed to decode request parametr", sl.Err(err))
+			c.JSON(http.StatusBadRequest, resp.Error("failed to decode request"))
+			return
+		}
+
+		delivery, err := repos.RedeliverWebhook(c.Request.Context(), webhookID, deliveryID)
+		if err != nil {
+			log.Error("failed to redeliver webhook", sl.Err(err))
+
+			if errors.Is(err, herrors.ErrDeliveryNotFound) {
+				c.JSON(http.StatusNotFound, resp.Error("delivery not found"))
+				return
+			}
+
+			c.JSON(http.StatusInternalServerError, resp.Error("failed to redeliver webhook"))
+			return
+		}
+
+		c.JSON(http.StatusAccepted, Response{
+			Response: resp.OK(),
+			Delivery: delivery,
+		})
+	}
+}
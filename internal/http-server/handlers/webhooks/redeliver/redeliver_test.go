@@ -0,0 +1,108 @@
+package redeliver
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallets/internal/herrors"
+	"wallets/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockWebhookRedeliverer struct {
+	mock.Mock
+}
+
+func (m *mockWebhookRedeliverer) RedeliverWebhook(ctx context.Context, subscriptionID, deliveryID uuid.UUID) (models.WebhookDelivery, error) {
+	args := m.Called(ctx, subscriptionID, deliveryID)
+	return args.Get(0).(models.WebhookDelivery), args.Error(1)
+}
+
+func TestNew(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	webhookID, _ := uuid.NewV4()
+	deliveryID, _ := uuid.NewV4()
+
+	tests := []struct {
+		name           string
+		webhookID      string
+		deliveryID     string
+		callRepo       bool
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Success",
+			webhookID:      webhookID.String(),
+			deliveryID:     deliveryID.String(),
+			callRepo:       true,
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   `"status":"PENDING"`,
+		},
+		{
+			name:           "Incorrect webhook UUID",
+			webhookID:      "I-n-c-o-r-r-e-c-t-uuid",
+			deliveryID:     deliveryID.String(),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to decode request",
+		},
+		{
+			name:           "Incorrect delivery UUID",
+			webhookID:      webhookID.String(),
+			deliveryID:     "I-n-c-o-r-r-e-c-t-uuid",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "failed to decode request",
+		},
+		{
+			name:           "delivery not found",
+			webhookID:      webhookID.String(),
+			deliveryID:     deliveryID.String(),
+			callRepo:       true,
+			mockError:      herrors.ErrDeliveryNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "delivery not found",
+		},
+		{
+			name:           "repo error",
+			webhookID:      webhookID.String(),
+			deliveryID:     deliveryID.String(),
+			callRepo:       true,
+			mockError:      errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to redeliver webhook",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := slog.New(slog.DiscardHandler)
+			mockRepo := new(mockWebhookRedeliverer)
+
+			if tc.callRepo {
+				mockRepo.On("RedeliverWebhook", mock.Anything, webhookID, deliveryID).
+					Return(models.WebhookDelivery{ID: deliveryID, SubscriptionID: webhookID, Status: models.DELIVERY_PENDING}, tc.mockError).
+					Once()
+			}
+
+			req, _ := http.NewRequest("POST", "/webhooks/"+tc.webhookID+"/deliveries/"+tc.deliveryID+"/redeliver", nil)
+
+			w := httptest.NewRecorder()
+			r := gin.New()
+			r.POST("/webhooks/:id/deliveries/:delivery_id/redeliver", New(context.Background(), log, mockRepo))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,230 @@
+// Package auth проверяет ключи API и их права на маршрутах
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	"wallets/internal/apikeys"
+	"wallets/internal/config"
+	"wallets/internal/herrors"
+	resp "wallets/internal/http-server/api/response"
+	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+)
+
+const (
+	HeaderAPIKey = "X-API-Key"
+
+	bearerPrefix = "Bearer "
+	contextKey   = "api_key"
+)
+
+// bootstrapKeyName - имя ключа из конфига, которым выпускаются первые ключи
+const bootstrapKeyName = "bootstrap"
+
+// anonymousKeyName - имя ключа запросов при выключенной проверке
+const anonymousKeyName = "anonymous"
+
+type keyFinder interface {
+	FindAPIKey(ctx context.Context, keyHash string) (models.APIKey, error)
+}
+
+// New проверяет ключ из заголовка X-API-Key или Authorization: Bearer и пишет в лог каждый запрос
+// с идентификатором ключа. Если проверка выключена в конфиге, запрос получает все права.
+func New(ctx context.Context, log *slog.Logger, repos keyFinder, cfg config.Auth) gin.HandlerFunc {
+	var bootstrapHash []byte
+	if cfg.BootstrapKey != "" {
+		bootstrapHash = []byte(apikeys.Hash(cfg.BootstrapKey))
+	}
+
+	return func(c *gin.Context) {
+		const op = "middleware.auth.New"
+
+		log := log.With(slog.String("op", op), tracing.TraceID(c.Request.Context()))
+
+		start := time.Now()
+
+		key, ok := authenticate(c.Request.Context(), log, c, repos, cfg.Enabled, bootstrapHash)
+		if ok {
+			SetAPIKey(c, key)
+			c.Next()
+		}
+
+		attrs := []any{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", c.Writer.Status()),
+			slog.Duration("duration", time.Since(start)),
+		}
+
+		// У ключа из конфига и анонимного доступа нет идентификатора, в лог попадает имя
+		if key.ID != uuid.Nil {
+			attrs = append(attrs, slog.String("key_id", key.ID.String()))
+		}
+		if key.Name != "" {
+			attrs = append(attrs, slog.String("key_name", key.Name))
+		}
+
+		log.Info("request", attrs...)
+	}
+}
+
+func authenticate(ctx context.Context, log *slog.Logger, c *gin.Context, repos keyFinder, enabled bool, bootstrapHash []byte) (models.APIKey, bool) {
+	if !enabled {
+		return models.APIKey{Name: anonymousKeyName, Scopes: allScopes}, true
+	}
+
+	raw := c.GetHeader(HeaderAPIKey)
+	if raw == "" {
+		raw, _ = strings.CutPrefix(c.GetHeader("Authorization"), bearerPrefix)
+	}
+
+	if raw == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, resp.Error("missing api key"))
+		return models.APIKey{}, false
+	}
+
+	hash := apikeys.Hash(raw)
+
+	if bootstrapHash != nil && subtle.ConstantTimeCompare([]byte(hash), bootstrapHash) == 1 {
+		return models.APIKey{Name: bootstrapKeyName, Scopes: []models.Scope{models.SCOPE_ADMIN}}, true
+	}
+
+	key, err := repos.FindAPIKey(ctx, hash)
+	if err != nil {
+		if errors.Is(err, herrors.ErrAPIKeyNotFound) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, resp.Error("invalid api key"))
+			return models.APIKey{}, false
+		}
+
+		log.Error("failed to find api key", sl.Err(err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, resp.Error("failed to check api key"))
+		return models.APIKey{}, false
+	}
+
+	return key, true
+}
+
+var allScopes = []models.Scope{
+	models.SCOPE_WALLET_READ,
+	models.SCOPE_WALLET_WRITE,
+	models.SCOPE_WALLET_CREATE,
+	models.SCOPE_WEBHOOKS,
+	models.SCOPE_ADMIN,
+}
+
+// RequireScope пропускает запрос, только если у ключа есть scope. Кошелек из параметра :uuid
+// маршрута сверяется с ограничениями ключа.
+func RequireScope(scope models.Scope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, _ := APIKeyFrom(c)
+
+		if !key.HasScope(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, resp.Error(fmt.Sprintf("api key lacks scope %s", scope)))
+			return
+		}
+
+		walletID := uuid.UUID{}
+		if err := walletID.Parse(c.Param("uuid")); err == nil && !key.AllowsWallet(walletID) {
+			c.AbortWithStatusJSON(http.StatusForbidden, resp.Error("api key is not allowed for this wallet"))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireWallet сверяет с ограничениями ключа кошелек объекта из параметра :id маршрута:
+// холда, транзакции, расписания или подписки. Если объект не найден, ответ остается за обработчиком.
+// Объект без кошелька (resolve вернул uuid.Nil, как глобальная подписка) доступен только ключам
+// без ограничений по кошелькам. Любая другая ошибка resolve отклоняет запрос, чтобы он не прошел без проверки.
+func RequireWallet(log *slog.Logger, resolve func(ctx context.Context, id uuid.UUID) (uuid.UUID, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "middleware.auth.RequireWallet"
+
+		key, _ := APIKeyFrom(c)
+
+		if len(key.WalletIDs) == 0 {
+			c.Next()
+			return
+		}
+
+		id := uuid.UUID{}
+		if err := id.Parse(c.Param("id")); err != nil {
+			c.Next()
+			return
+		}
+
+		walletID, err := resolve(c.Request.Context(), id)
+		if err != nil {
+			if isNotFound(err) {
+				c.Next()
+				return
+			}
+
+			log.Error("failed to resolve wallet", slog.String("op", op), tracing.TraceID(c.Request.Context()), sl.Err(err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, resp.Error("failed to check api key"))
+			return
+		}
+
+		if !key.AllowsWallet(walletID) {
+			c.AbortWithStatusJSON(http.StatusForbidden, resp.Error("api key is not allowed for this wallet"))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// isNotFound сообщает, что объекта маршрута нет: обработчик сам ответит 404
+func isNotFound(err error) bool {
+	return errors.Is(err, herrors.ErrHoldNotFound) ||
+		errors.Is(err, herrors.ErrTransactionNotFound) ||
+		errors.Is(err, herrors.ErrScheduleNotFound) ||
+		errors.Is(err, herrors.ErrWebhookNotFound)
+}
+
+// WalletAllowed проверяет кошелек из тела запроса. Без ключа в контексте (проверка не подключена) разрешено все.
+func WalletAllowed(c *gin.Context, walletID uuid.UUID) bool {
+	key, ok := APIKeyFrom(c)
+	return !ok || key.AllowsWallet(walletID)
+}
+
+// Actor возвращает автора запроса для журналов: идентификатор ключа, а у ключа из конфига
+// и анонимного доступа, у которых идентификатора нет, - имя ключа
+func Actor(c *gin.Context) string {
+	key, ok := APIKeyFrom(c)
+	if !ok {
+		return anonymousKeyName
+	}
+
+	if key.ID != uuid.Nil {
+		return key.ID.String()
+	}
+
+	return key.Name
+}
+
+func SetAPIKey(c *gin.Context, key models.APIKey) {
+	c.Set(contextKey, key)
+}
+
+func APIKeyFrom(c *gin.Context) (models.APIKey, bool) {
+	value, ok := c.Get(contextKey)
+	if !ok {
+		return models.APIKey{}, false
+	}
+
+	key, ok := value.(models.APIKey)
+	return key, ok
+}
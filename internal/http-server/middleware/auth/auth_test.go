@@ -0,0 +1,249 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallets/internal/apikeys"
+	"wallets/internal/config"
+	"wallets/internal/herrors"
+	"wallets/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockKeyFinder struct {
+	mock.Mock
+}
+
+func (m *mockKeyFinder) FindAPIKey(ctx context.Context, keyHash string) (models.APIKey, error) {
+	args := m.Called(ctx, keyHash)
+	return args.Get(0).(models.APIKey), args.Error(1)
+}
+
+func TestNew(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	keyID, _ := uuid.NewV4()
+	allowedWallet, _ := uuid.NewV4()
+	otherWallet, _ := uuid.NewV4()
+	holdID, _ := uuid.NewV4()
+	globalID, _ := uuid.NewV4()
+	missingID, _ := uuid.NewV4()
+	brokenID, _ := uuid.NewV4()
+
+	const (
+		readKey       = "wk_read"
+		restrictedKey = "wk_restricted"
+		bootstrapKey  = "bootstrap-secret"
+	)
+
+	keys := map[string]models.APIKey{
+		readKey:       {ID: keyID, Name: "reader", Scopes: []models.Scope{models.SCOPE_WALLET_READ}},
+		restrictedKey: {ID: keyID, Name: "shop", Scopes: []models.Scope{models.SCOPE_WALLET_READ, models.SCOPE_WALLET_WRITE}, WalletIDs: []uuid.UUID{allowedWallet}},
+	}
+
+	// globalID - объект без кошелька, как глобальная подписка на вебхуки
+	holdWallets := map[uuid.UUID]uuid.UUID{holdID: otherWallet, globalID: uuid.Nil}
+
+	tests := []struct {
+		name           string
+		disabled       bool
+		method         string
+		path           string
+		header         string
+		value          string
+		findKey        string
+		findError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "missing key",
+			method:         "GET",
+			path:           "/wallets/" + allowedWallet.String(),
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "missing api key",
+		},
+		{
+			name:           "unknown key",
+			method:         "GET",
+			path:           "/wallets/" + allowedWallet.String(),
+			header:         HeaderAPIKey,
+			value:          "wk_unknown",
+			findKey:        "wk_unknown",
+			findError:      herrors.ErrAPIKeyNotFound,
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "invalid api key",
+		},
+		{
+			name:           "storage error",
+			method:         "GET",
+			path:           "/wallets/" + allowedWallet.String(),
+			header:         HeaderAPIKey,
+			value:          readKey,
+			findKey:        readKey,
+			findError:      errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to check api key",
+		},
+		{
+			name:           "read scope",
+			method:         "GET",
+			path:           "/wallets/" + otherWallet.String(),
+			header:         HeaderAPIKey,
+			value:          readKey,
+			findKey:        readKey,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "bearer token",
+			method:         "GET",
+			path:           "/wallets/" + otherWallet.String(),
+			header:         "Authorization",
+			value:          "Bearer " + readKey,
+			findKey:        readKey,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing scope",
+			method:         "POST",
+			path:           "/wallets/" + otherWallet.String() + "/holds",
+			header:         HeaderAPIKey,
+			value:          readKey,
+			findKey:        readKey,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "api key lacks scope wallet:write",
+		},
+		{
+			name:           "restricted key on allowed wallet",
+			method:         "POST",
+			path:           "/wallets/" + allowedWallet.String() + "/holds",
+			header:         HeaderAPIKey,
+			value:          restrictedKey,
+			findKey:        restrictedKey,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "restricted key on other wallet",
+			method:         "GET",
+			path:           "/wallets/" + otherWallet.String(),
+			header:         HeaderAPIKey,
+			value:          restrictedKey,
+			findKey:        restrictedKey,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "api key is not allowed for this wallet",
+		},
+		{
+			name:           "restricted key on hold of other wallet",
+			method:         "POST",
+			path:           "/holds/" + holdID.String() + "/void",
+			header:         HeaderAPIKey,
+			value:          restrictedKey,
+			findKey:        restrictedKey,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "api key is not allowed for this wallet",
+		},
+		{
+			name:           "restricted key on object without wallet",
+			method:         "POST",
+			path:           "/holds/" + globalID.String() + "/void",
+			header:         HeaderAPIKey,
+			value:          restrictedKey,
+			findKey:        restrictedKey,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "api key is not allowed for this wallet",
+		},
+		{
+			name:           "restricted key on missing hold",
+			method:         "POST",
+			path:           "/holds/" + missingID.String() + "/void",
+			header:         HeaderAPIKey,
+			value:          restrictedKey,
+			findKey:        restrictedKey,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "restricted key on hold lookup error",
+			method:         "POST",
+			path:           "/holds/" + brokenID.String() + "/void",
+			header:         HeaderAPIKey,
+			value:          restrictedKey,
+			findKey:        restrictedKey,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to check api key",
+		},
+		{
+			name:           "bootstrap key is admin only",
+			method:         "GET",
+			path:           "/admin/ledger",
+			header:         HeaderAPIKey,
+			value:          bootstrapKey,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "bootstrap key has no wallet scopes",
+			method:         "GET",
+			path:           "/wallets/" + otherWallet.String(),
+			header:         HeaderAPIKey,
+			value:          bootstrapKey,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "api key lacks scope wallet:read",
+		},
+		{
+			name:           "disabled",
+			disabled:       true,
+			method:         "GET",
+			path:           "/admin/ledger",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := slog.New(slog.DiscardHandler)
+			mockRepo := new(mockKeyFinder)
+
+			if tc.findKey != "" {
+				mockRepo.On("FindAPIKey", mock.Anything, apikeys.Hash(tc.findKey)).Return(keys[tc.findKey], tc.findError).Once()
+			}
+
+			resolveHold := func(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+				switch id {
+				case missingID:
+					return uuid.Nil, herrors.ErrHoldNotFound
+				case brokenID:
+					return uuid.Nil, errors.New("db error")
+				}
+				return holdWallets[id], nil
+			}
+
+			ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+
+			r := gin.New()
+			r.Use(New(context.Background(), log, mockRepo, config.Auth{Enabled: !tc.disabled, BootstrapKey: bootstrapKey}))
+			r.GET("/wallets/:uuid", RequireScope(models.SCOPE_WALLET_READ), ok)
+			r.POST("/wallets/:uuid/holds", RequireScope(models.SCOPE_WALLET_WRITE), ok)
+			r.POST("/holds/:id/void", RequireScope(models.SCOPE_WALLET_WRITE), RequireWallet(log, resolveHold), ok)
+			r.GET("/admin/ledger", RequireScope(models.SCOPE_ADMIN), ok)
+
+			req, _ := http.NewRequest(tc.method, tc.path, nil)
+			if tc.header != "" {
+				req.Header.Set(tc.header, tc.value)
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	walletID, _ := uuid.NewV4()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		route  string
+		status string
+	}{
+		{
+			name:   "route template instead of path",
+			method: http.MethodGet,
+			path:   "/wallets/" + walletID.String(),
+			route:  "/wallets/:uuid",
+			status: "200",
+		},
+		{
+			name:   "handler error status",
+			method: http.MethodPost,
+			path:   "/wallet",
+			route:  "/wallet",
+			status: "400",
+		},
+		{
+			name:   "unknown path",
+			method: http.MethodGet,
+			path:   "/no/such/route",
+			route:  unmatchedRoute,
+			status: "404",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(New())
+			r.GET("/wallets/:uuid", func(c *gin.Context) { c.Status(http.StatusOK) })
+			r.POST("/wallet", func(c *gin.Context) { c.Status(http.StatusBadRequest) })
+
+			counter := requests.WithLabelValues(tc.method, tc.route, tc.status)
+			before := testutil.ToFloat64(counter)
+			observed := sampleCount(t, tc.method, tc.route)
+
+			req, _ := http.NewRequest(tc.method, tc.path, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, before+1, testutil.ToFloat64(counter))
+			assert.Equal(t, observed+1, sampleCount(t, tc.method, tc.route))
+		})
+	}
+}
+
+func sampleCount(t *testing.T, method, route string) uint64 {
+	t.Helper()
+
+	m := &dto.Metric{}
+	require.NoError(t, requestDuration.WithLabelValues(method, route).(prometheus.Metric).Write(m))
+
+	return m.GetHistogram().GetSampleCount()
+}
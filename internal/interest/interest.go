@@ -0,0 +1,58 @@
+package interest
+
+import (
+	"math"
+	"math/big"
+	"time"
+)
+
+const (
+	// MicrosPerUnit - во сколько раз начисления точнее минорной единицы валюты
+	MicrosPerUnit = 1_000_000
+	// DaysInYear - база начисления: дневная ставка - годовая, деленная на 365, в том числе в високосный год
+	DaysInYear = 365
+)
+
+// DailyAccrual возвращает проценты за день на остаток balance по годовой ставке annualRateBps
+// в сотых долях процента. Результат в миллионных долях минорной единицы, округляется вниз.
+// На отрицательный остаток проценты не начисляются.
+func DailyAccrual(balance, annualRateBps int64) int64 {
+	if balance <= 0 || annualRateBps <= 0 {
+		return 0
+	}
+
+	// balance * bps / 10000 / 365 * MicrosPerUnit
+	amount := new(big.Int).Mul(big.NewInt(balance), big.NewInt(annualRateBps))
+	amount.Mul(amount, big.NewInt(MicrosPerUnit))
+	amount.Quo(amount, big.NewInt(10000*DaysInYear))
+
+	if !amount.IsInt64() {
+		return math.MaxInt64
+	}
+
+	return amount.Int64()
+}
+
+// Payout переводит сумму начислений в минорные единицы с округлением половины вверх
+func Payout(micros int64) int64 {
+	return micros/MicrosPerUnit + (micros%MicrosPerUnit+MicrosPerUnit/2)/MicrosPerUnit
+}
+
+// LastClosedDay возвращает последние сутки (UTC), которые закончились не позже чем lag назад.
+// lag нужен, чтобы успели закоммититься транзакции, начатые до полуночи.
+func LastClosedDay(now time.Time, lag time.Duration) time.Time {
+	year, month, day := now.UTC().Add(-lag).Date()
+	return time.Date(year, month, day-1, 0, 0, 0, 0, time.UTC)
+}
+
+// PayableBefore возвращает начало месяца, в котором еще есть незакрытые сутки.
+// Начисления до этой даты относятся к закрытым месяцам и могут быть выплачены.
+func PayableBefore(now time.Time, lag time.Duration) time.Time {
+	return Period(LastClosedDay(now, lag).AddDate(0, 0, 1))
+}
+
+// Period возвращает месяц выплаты, к которому относится начисление за day
+func Period(day time.Time) time.Time {
+	year, month, _ := day.Date()
+	return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,90 @@
+package interest
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDailyAccrual(t *testing.T) {
+	tests := []struct {
+		name     string
+		balance  int64
+		rate     int64
+		expected int64
+	}{
+		{
+			name:     "10% on 3650.00",
+			balance:  365000,
+			rate:     1000,
+			expected: 100 * MicrosPerUnit,
+		},
+		{
+			name:     "fraction of a minor unit",
+			balance:  10000,
+			rate:     500,
+			expected: 1369863,
+		},
+		{
+			name:     "negative balance",
+			balance:  -10000,
+			rate:     500,
+			expected: 0,
+		},
+		{
+			name:     "zero rate",
+			balance:  10000,
+			rate:     0,
+			expected: 0,
+		},
+		{
+			name:     "huge balance does not overflow",
+			balance:  math.MaxInt64,
+			rate:     10000,
+			expected: math.MaxInt64,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, DailyAccrual(tc.balance, tc.rate))
+		})
+	}
+}
+
+func TestPayout(t *testing.T) {
+	assert.Equal(t, int64(0), Payout(0))
+	assert.Equal(t, int64(0), Payout(499_999))
+	assert.Equal(t, int64(1), Payout(500_000))
+	assert.Equal(t, int64(41), Payout(30*1369863))
+}
+
+func TestLastClosedDay(t *testing.T) {
+	lag := 5 * time.Minute
+
+	assert.Equal(t, time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+		LastClosedDay(time.Date(2026, 10, 1, 0, 10, 0, 0, time.UTC), lag))
+
+	// Полночь еще не отстала на lag: последние закрытые сутки - позавчера
+	assert.Equal(t, time.Date(2026, 9, 29, 0, 0, 0, 0, time.UTC),
+		LastClosedDay(time.Date(2026, 10, 1, 0, 2, 0, 0, time.UTC), lag))
+
+	assert.Equal(t, time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+		LastClosedDay(time.Date(2026, 10, 1, 4, 0, 0, 0, time.FixedZone("MSK", 3*60*60)), lag))
+}
+
+func TestPayableBefore(t *testing.T) {
+	lag := 5 * time.Minute
+
+	// 30 сентября закрыто - сентябрь можно выплачивать
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		PayableBefore(time.Date(2026, 10, 1, 0, 10, 0, 0, time.UTC), lag))
+
+	assert.Equal(t, time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		PayableBefore(time.Date(2026, 10, 1, 0, 2, 0, 0, time.UTC), lag))
+
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		PayableBefore(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC), lag))
+}
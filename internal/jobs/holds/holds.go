@@ -0,0 +1,40 @@
+package holds
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"wallets/internal/lib/sl"
+)
+
+type holdsExpirer interface {
+	ExpireHolds(ctx context.Context) (int, error)
+}
+
+// Run периодически закрывает просроченные холды, пока не отменен ctx
+func Run(ctx context.Context, log *slog.Logger, repos holdsExpirer, interval time.Duration) {
+	const op = "jobs.holds.Run"
+
+	log = log.With(slog.String("op", op))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			wallets, err := repos.ExpireHolds(ctx)
+			if err != nil {
+				log.Error("failed to expire holds", sl.Err(err))
+				continue
+			}
+
+			if wallets > 0 {
+				log.Info("expired holds released", slog.Int("wallets", wallets))
+			}
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package idempotency
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"wallets/internal/config"
+	"wallets/internal/lib/sl"
+)
+
+type keysExpirer interface {
+	ExpireIdempotencyKeys(ctx context.Context, ttl time.Duration) (int64, error)
+}
+
+// Run по расписанию освобождает просроченные ключи идемпотентности, пока не отменен ctx
+func Run(ctx context.Context, log *slog.Logger, repos keysExpirer, cfg config.Idempotency) {
+	const op = "jobs.idempotency.Run"
+
+	log = log.With(slog.String("op", op))
+
+	ticker := time.NewTicker(cfg.CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			expired, err := repos.ExpireIdempotencyKeys(ctx, cfg.TTL)
+			if err != nil {
+				log.Error("failed to expire idempotency keys", sl.Err(err))
+				continue
+			}
+
+			log.Debug("idempotency keys expired", slog.Int64("count", expired))
+		}
+	}
+}
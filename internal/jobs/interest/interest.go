@@ -0,0 +1,147 @@
+package interest
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+	"wallets/internal/config"
+	"wallets/internal/herrors"
+	"wallets/internal/interest"
+	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+
+	"github.com/gofrs/uuid"
+)
+
+type interestRepos interface {
+	ListInterestBalances(ctx context.Context, day time.Time, walletTypes []string) ([]models.InterestBalance, error)
+	RecordInterestAccruals(ctx context.Context, accruals []models.InterestAccrual) (int64, error)
+	ListInterestDue(ctx context.Context, before time.Time, limit int) ([]models.InterestDue, error)
+}
+
+type interestPayer interface {
+	PayInterest(ctx context.Context, walletID uuid.UUID, period time.Time) (models.InterestPayout, error)
+}
+
+// Run периодически начисляет проценты за закончившиеся сутки и выплачивает их за закончившиеся месяцы,
+// пока не отменен ctx. Начисление за сутки делается один раз, поэтому повторный запуск безопасен.
+func Run(ctx context.Context, log *slog.Logger, repos interestRepos, payer interestPayer, cfg config.Interest) {
+	const op = "jobs.interest.Run"
+
+	log = log.With(slog.String("op", op))
+
+	walletTypes := make([]string, 0, len(cfg.AnnualRates))
+	for walletType := range cfg.AnnualRates {
+		walletTypes = append(walletTypes, walletType)
+	}
+
+	ticker := time.NewTicker(cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			now := time.Now()
+
+			// Выплата за месяц ждет начислений за все его сутки
+			if err := accrue(ctx, log, repos, cfg, walletTypes, interest.LastClosedDay(now, cfg.Lag)); err != nil {
+				log.Error("failed to accrue interest", sl.Err(err))
+				continue
+			}
+
+			if err := pay(ctx, log, repos, payer, cfg, interest.PayableBefore(now, cfg.Lag)); err != nil {
+				log.Error("failed to pay interest", sl.Err(err))
+			}
+		}
+	}
+}
+
+// accrue начисляет проценты за последние cfg.LookbackDays суток до lastDay включительно
+func accrue(ctx context.Context, log *slog.Logger, repos interestRepos, cfg config.Interest, walletTypes []string, lastDay time.Time) error {
+	if len(walletTypes) == 0 {
+		return nil
+	}
+
+	for day := lastDay.AddDate(0, 0, 1-cfg.LookbackDays); !day.After(lastDay); day = day.AddDate(0, 0, 1) {
+		balances, err := repos.ListInterestBalances(ctx, day, walletTypes)
+		if err != nil {
+			return err
+		}
+
+		accruals := make([]models.InterestAccrual, 0, len(balances))
+		for _, balance := range balances {
+			rate := cfg.AnnualRates[balance.Type]
+
+			amount := interest.DailyAccrual(balance.Balance, rate)
+			if amount == 0 {
+				continue
+			}
+
+			accruals = append(accruals, models.InterestAccrual{
+				WalletID:      balance.WalletID,
+				AccrualDate:   day,
+				Balance:       balance.Balance,
+				AnnualRateBps: rate,
+				AmountMicros:  amount,
+			})
+		}
+
+		if len(accruals) == 0 {
+			continue
+		}
+
+		recorded, err := repos.RecordInterestAccruals(ctx, accruals)
+		if err != nil {
+			return err
+		}
+
+		if recorded > 0 {
+			log.Info("interest accrued", slog.String("day", day.Format(time.DateOnly)), slog.Int64("wallets", recorded))
+		}
+	}
+
+	return nil
+}
+
+// pay выплачивает начисления за месяцы до before. Кошелек, занятый другой операцией, будет выплачен
+// на следующем запуске. Отклоненная выплата не повторяется.
+func pay(ctx context.Context, log *slog.Logger, repos interestRepos, payer interestPayer, cfg config.Interest, before time.Time) error {
+	due, err := repos.ListInterestDue(ctx, before, cfg.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range due {
+		payout, err := payer.PayInterest(ctx, d.WalletID, d.Period)
+		if err != nil {
+			if errors.Is(err, herrors.ErrNoInterestDue) || errors.Is(err, herrors.ErrLockedWallet) {
+				continue
+			}
+
+			log.Error("failed to pay interest", slog.String("wallet_id", d.WalletID.String()), sl.Err(err))
+			continue
+		}
+
+		if payout.Status == models.PAYOUT_REJECTED {
+			log.Warn("interest payout rejected",
+				slog.String("wallet_id", payout.WalletID.String()),
+				slog.String("period", payout.Period.Format("2006-01")),
+				slog.Int64("amount", payout.Amount),
+				slog.String("reason", payout.Error),
+			)
+			continue
+		}
+
+		log.Info("interest paid",
+			slog.String("wallet_id", payout.WalletID.String()),
+			slog.String("period", payout.Period.Format("2006-01")),
+			slog.Int64("amount", payout.Amount),
+		)
+	}
+
+	return nil
+}
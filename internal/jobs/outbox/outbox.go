@@ -0,0 +1,116 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+	"wallets/internal/config"
+	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+)
+
+// Sink - приемник событий. Publish должен быть идемпотентным на стороне потребителя:
+// после сбоя событие может быть доставлено повторно.
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, event models.OutboxEvent) error
+}
+
+type eventsRepos interface {
+	LockOutbox(ctx context.Context) (func(), bool, error)
+	PendingEvents(ctx context.Context, limit int) ([]models.OutboxEvent, error)
+	MarkEventsPublished(ctx context.Context, ids []int64) error
+}
+
+// Run периодически публикует неотправленные события outbox во все приемники, пока не отменен ctx.
+// События отправляются строго по порядку: на первой ошибке пачка прерывается и повторяется на следующем тике.
+func Run(ctx context.Context, log *slog.Logger, repos eventsRepos, sinks []Sink, cfg config.Outbox) {
+	const op = "jobs.outbox.Run"
+
+	log = log.With(slog.String("op", op))
+
+	ticker := time.NewTicker(cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			drain(ctx, log, repos, sinks, cfg.BatchSize)
+		}
+	}
+}
+
+// drain публикует пачки, пока они заполняются целиком. Публикует только экземпляр сервиса,
+// взявший lock outbox, остальные пропускают тик.
+func drain(ctx context.Context, log *slog.Logger, repos eventsRepos, sinks []Sink, batchSize int) {
+	unlock, locked, err := repos.LockOutbox(ctx)
+	if err != nil {
+		log.Error("failed to lock outbox", sl.Err(err))
+		return
+	}
+
+	if !locked {
+		return
+	}
+
+	defer unlock()
+
+	for {
+		published, err := dispatch(ctx, log, repos, sinks, batchSize)
+		if err != nil {
+			log.Error("failed to dispatch outbox events", sl.Err(err))
+			return
+		}
+
+		if published > 0 {
+			log.Debug("outbox events published", slog.Int("count", published))
+		}
+
+		if published < batchSize {
+			return
+		}
+	}
+}
+
+// dispatch отправляет одну пачку событий и возвращает, сколько из них доставлено во все приемники
+func dispatch(ctx context.Context, log *slog.Logger, repos eventsRepos, sinks []Sink, batchSize int) (int, error) {
+	events, err := repos.PendingEvents(ctx, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	published := make([]int64, 0, len(events))
+
+	for _, event := range events {
+		if err := publish(ctx, sinks, event); err != nil {
+			log.Error("failed to publish outbox event", slog.Int64("event_id", event.ID), sl.Err(err))
+			break
+		}
+		published = append(published, event.ID)
+	}
+
+	if err := repos.MarkEventsPublished(ctx, published); err != nil {
+		return 0, err
+	}
+
+	if len(published) < len(events) {
+		// Пачка прервана ошибкой приемника, продолжим на следующем тике
+		return 0, nil
+	}
+
+	return len(published), nil
+}
+
+func publish(ctx context.Context, sinks []Sink, event models.OutboxEvent) error {
+	for _, sink := range sinks {
+		if err := sink.Publish(ctx, event); err != nil {
+			return fmt.Errorf("%s: %w", sink.Name(), err)
+		}
+	}
+
+	return nil
+}
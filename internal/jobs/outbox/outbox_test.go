@@ -0,0 +1,165 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"wallets/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepos struct {
+	busy     bool
+	unlocked bool
+	events   []models.OutboxEvent
+	pending  int
+	marked   [][]int64
+}
+
+func (r *fakeRepos) LockOutbox(ctx context.Context) (func(), bool, error) {
+	if r.busy {
+		return nil, false, nil
+	}
+
+	return func() { r.unlocked = true }, true, nil
+}
+
+func (r *fakeRepos) PendingEvents(ctx context.Context, limit int) ([]models.OutboxEvent, error) {
+	r.pending++
+
+	var events []models.OutboxEvent
+	for _, event := range r.events {
+		if !r.isMarked(event.ID) && len(events) < limit {
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+func (r *fakeRepos) MarkEventsPublished(ctx context.Context, ids []int64) error {
+	r.marked = append(r.marked, ids)
+	return nil
+}
+
+func (r *fakeRepos) isMarked(id int64) bool {
+	for _, ids := range r.marked {
+		for _, marked := range ids {
+			if marked == id {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// fakeSink запоминает опубликованные события и отказывает на событии failOn
+type fakeSink struct {
+	name      string
+	failOn    int64
+	published []int64
+}
+
+func (s *fakeSink) Name() string {
+	return s.name
+}
+
+func (s *fakeSink) Publish(ctx context.Context, event models.OutboxEvent) error {
+	if event.ID == s.failOn {
+		return errors.New("sink unavailable")
+	}
+
+	s.published = append(s.published, event.ID)
+	return nil
+}
+
+func events(ids ...int64) []models.OutboxEvent {
+	result := make([]models.OutboxEvent, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, models.OutboxEvent{ID: id, Type: models.EventBalanceChanged})
+	}
+
+	return result
+}
+
+func TestDispatch(t *testing.T) {
+	tests := []struct {
+		name              string
+		failOn            int64
+		expectedPublished int
+		expectedMarked    []int64
+		expectedFirst     []int64
+		expectedSecond    []int64
+	}{
+		{
+			name:              "all events published",
+			expectedPublished: 3,
+			expectedMarked:    []int64{1, 2, 3},
+			expectedFirst:     []int64{1, 2, 3},
+			expectedSecond:    []int64{1, 2, 3},
+		},
+		{
+			name:              "stops on first sink error",
+			failOn:            2,
+			expectedPublished: 0,
+			expectedMarked:    []int64{1},
+			expectedFirst:     []int64{1, 2},
+			expectedSecond:    []int64{1},
+		},
+		{
+			name:              "first event fails",
+			failOn:            1,
+			expectedPublished: 0,
+			expectedMarked:    []int64{},
+			expectedFirst:     []int64{1},
+			expectedSecond:    nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := slog.New(slog.DiscardHandler)
+			repos := &fakeRepos{events: events(1, 2, 3)}
+			first := &fakeSink{name: "first"}
+			second := &fakeSink{name: "second", failOn: tc.failOn}
+
+			published, err := dispatch(context.Background(), log, repos, []Sink{first, second}, 10)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedPublished, published)
+			require.Len(t, repos.marked, 1)
+			assert.Equal(t, tc.expectedMarked, repos.marked[0])
+			assert.Equal(t, tc.expectedFirst, first.published)
+			assert.Equal(t, tc.expectedSecond, second.published)
+		})
+	}
+}
+
+func TestDrain(t *testing.T) {
+	log := slog.New(slog.DiscardHandler)
+
+	t.Run("publishes full batches until drained", func(t *testing.T) {
+		repos := &fakeRepos{events: events(1, 2, 3, 4, 5)}
+		sink := &fakeSink{name: "sink"}
+
+		drain(context.Background(), log, repos, []Sink{sink}, 2)
+
+		assert.Equal(t, []int64{1, 2, 3, 4, 5}, sink.published)
+		assert.Equal(t, [][]int64{{1, 2}, {3, 4}, {5}}, repos.marked)
+		assert.True(t, repos.unlocked)
+	})
+
+	t.Run("skips while another instance holds the lock", func(t *testing.T) {
+		repos := &fakeRepos{busy: true, events: events(1)}
+		sink := &fakeSink{name: "sink"}
+
+		drain(context.Background(), log, repos, []Sink{sink}, 2)
+
+		assert.Zero(t, repos.pending)
+		assert.Empty(t, sink.published)
+	})
+}
@@ -0,0 +1,170 @@
+package schedules
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+	"wallets/internal/config"
+	"wallets/internal/herrors"
+	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/recurrence"
+
+	"github.com/gofrs/uuid"
+)
+
+type schedulesRepos interface {
+	ClaimDueSchedules(ctx context.Context, limit int, lease time.Duration) ([]models.Schedule, error)
+	RecordScheduleRun(ctx context.Context, run models.ScheduleRun, next models.Schedule) error
+	ListTransactions(ctx context.Context, filter models.TransactionFilter) ([]models.Transactions, error)
+}
+
+type executor interface {
+	UpdateBalance(ctx context.Context, walletID uuid.UUID, operationType models.OperationType, amount int64, opts models.TxOptions) (models.Transactions, error)
+	ApplyBatch(ctx context.Context, items []models.BatchItem) ([]models.BatchItemResult, error)
+}
+
+// rejections - ошибки, с которыми запуск считается выполненным неуспешно и не повторяется.
+// Остальные ошибки временные: запуск повторится, когда истечет lease.
+var rejections = []error{
+	herrors.ErrNXUUID,
+	herrors.ErrInsufficientFunds,
+	herrors.ErrCurrencyMismatch,
+	herrors.ErrSameWallet,
+	herrors.ErrWalletFrozen,
+	herrors.ErrWalletClosed,
+	herrors.ErrLimitExceeded,
+	herrors.ErrNoRevenueWallet,
+}
+
+// Run периодически выполняет расписания, время запуска которых наступило, пока не отменен ctx.
+// Операция запуска проводится со ссылкой Schedule.ExternalReference, поэтому повтор после сбоя
+// не проведет ее второй раз.
+func Run(ctx context.Context, log *slog.Logger, repos schedulesRepos, exec executor, cfg config.Schedules) {
+	const op = "jobs.schedules.Run"
+
+	log = log.With(slog.String("op", op))
+
+	ticker := time.NewTicker(cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			schedules, err := repos.ClaimDueSchedules(ctx, cfg.BatchSize, cfg.Lease)
+			if err != nil {
+				log.Error("failed to claim due schedules", sl.Err(err))
+				continue
+			}
+
+			for _, schedule := range schedules {
+				run, err := execute(ctx, repos, exec, schedule)
+				if err != nil {
+					log.Error("failed to execute schedule", slog.String("schedule_id", schedule.ID.String()), sl.Err(err))
+					continue
+				}
+
+				if run.Status == models.RUN_FAILED {
+					log.Warn("scheduled operation rejected",
+						slog.String("schedule_id", schedule.ID.String()),
+						slog.String("error", run.Error),
+					)
+				}
+
+				if err := repos.RecordScheduleRun(ctx, run, recurrence.Advance(schedule, time.Now())); err != nil {
+					log.Error("failed to record schedule run", sl.Err(err))
+				}
+			}
+		}
+	}
+}
+
+func execute(ctx context.Context, repos schedulesRepos, exec executor, schedule models.Schedule) (models.ScheduleRun, error) {
+	run := models.ScheduleRun{
+		ScheduleID:   schedule.ID,
+		ScheduledFor: schedule.NextRunAt,
+		Status:       models.RUN_SUCCEEDED,
+	}
+
+	reference := schedule.ExternalReference()
+
+	var err error
+
+	if schedule.OperationType == models.TRANSFER {
+		var results []models.BatchItemResult
+
+		results, err = exec.ApplyBatch(ctx, []models.BatchItem{{
+			OperationType:     models.TRANSFER,
+			WalletID:          schedule.WalletID,
+			ToWalletID:        schedule.ToWalletID.UUID,
+			Amount:            schedule.Amount,
+			Description:       schedule.Description,
+			ExternalReference: reference,
+		}})
+		if err == nil {
+			run.TransferID = results[0].TransferID
+			run.TransactionID = uuid.NullUUID{UUID: results[0].Transactions[0].ID, Valid: true}
+		}
+	} else {
+		var transaction models.Transactions
+
+		transaction, err = exec.UpdateBalance(ctx, schedule.WalletID, schedule.OperationType, schedule.Amount, models.TxOptions{
+			Description:       schedule.Description,
+			ExternalReference: reference,
+		})
+		if err == nil {
+			run.TransactionID = uuid.NullUUID{UUID: transaction.ID, Valid: true}
+		}
+	}
+
+	if err == nil {
+		return run, nil
+	}
+
+	// Операция уже проведена прошлой попыткой, результат которой не успели записать
+	if errors.Is(err, herrors.ErrDuplicateExternalReference) {
+		transactions, err := repos.ListTransactions(ctx, models.TransactionFilter{
+			WalletID:          schedule.WalletID,
+			ExternalReference: reference,
+			Limit:             1,
+		})
+		if err != nil {
+			return models.ScheduleRun{}, err
+		}
+
+		if len(transactions) > 0 {
+			run.TransactionID = uuid.NullUUID{UUID: transactions[0].ID, Valid: true}
+			run.TransferID = transactions[0].TransferID
+		}
+
+		return run, nil
+	}
+
+	if rejection := rejectionOf(err); rejection != "" {
+		run.Status = models.RUN_FAILED
+		run.Error = rejection
+		return run, nil
+	}
+
+	return models.ScheduleRun{}, err
+}
+
+// rejectionOf возвращает причину отказа в операции или пустую строку для временной ошибки
+func rejectionOf(err error) string {
+	var limitErr *herrors.LimitError
+	if errors.As(err, &limitErr) {
+		return limitErr.Error()
+	}
+
+	for _, rejection := range rejections {
+		if errors.Is(err, rejection) {
+			return rejection.Error()
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,51 @@
+package schedules
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"wallets/internal/herrors"
+	"wallets/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRejectionOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "insufficient funds",
+			err:  fmt.Errorf("storage.UpdateBalance: %w", herrors.ErrInsufficientFunds),
+			want: herrors.ErrInsufficientFunds.Error(),
+		},
+		{
+			name: "limit exceeded",
+			err:  fmt.Errorf("storage.Transfer: %w", &herrors.LimitError{Limit: models.LimitDailyWithdrawal, Value: 500}),
+			want: "limit exceeded: daily_withdrawal (500)",
+		},
+		{
+			name: "no revenue wallet for fee",
+			err:  fmt.Errorf("storage.UpdateBalance: %w", herrors.ErrNoRevenueWallet),
+			want: herrors.ErrNoRevenueWallet.Error(),
+		},
+		{
+			name: "wallet busy",
+			err:  fmt.Errorf("storage.UpdateBalance: %w", herrors.ErrLockedWallet),
+			want: "",
+		},
+		{
+			name: "db error",
+			err:  errors.New("connection refused"),
+			want: "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, rejectionOf(tc.err))
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package snapshots
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"wallets/internal/config"
+	"wallets/internal/lib/sl"
+)
+
+type snapshotsTaker interface {
+	TakeBalanceSnapshots(ctx context.Context, lag time.Duration) (int64, error)
+}
+
+// Run периодически фиксирует суточные снимки балансов, пока не отменен ctx
+func Run(ctx context.Context, log *slog.Logger, repos snapshotsTaker, cfg config.Snapshots) {
+	const op = "jobs.snapshots.Run"
+
+	log = log.With(slog.String("op", op))
+
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			taken, err := repos.TakeBalanceSnapshots(ctx, cfg.Lag)
+			if err != nil {
+				log.Error("failed to take balance snapshots", sl.Err(err))
+				continue
+			}
+
+			if taken > 0 {
+				log.Info("balance snapshots taken", slog.Int64("wallets", taken))
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package webhooks
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"wallets/internal/config"
+	"wallets/internal/lib/sl"
+	"wallets/internal/models"
+	"wallets/internal/webhooks"
+
+	"github.com/gofrs/uuid"
+)
+
+type deliveriesRepos interface {
+	ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookTask, error)
+	RecordDeliveryAttempt(ctx context.Context, id uuid.UUID, result models.DeliveryResult) error
+}
+
+type sender interface {
+	Send(ctx context.Context, task models.WebhookTask) (int, error)
+}
+
+// Run периодически отправляет вебхуки, время попытки которых наступило, пока не отменен ctx.
+// Неудачные доставки повторяются с экспоненциальной задержкой, после cfg.MaxAttempts попыток
+// доставка помечается FAILED и отправляется повторно только вручную.
+func Run(ctx context.Context, log *slog.Logger, repos deliveriesRepos, client sender, cfg config.Webhooks) {
+	const op = "jobs.webhooks.Run"
+
+	log = log.With(slog.String("op", op))
+
+	ticker := time.NewTicker(cfg.PollInterval)
+	defer ticker.Stop()
+
+	// Пока идет отправка, доставки не достанутся другому экземпляру сервиса
+	lease := cfg.Timeout * time.Duration(cfg.BatchSize+1)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			tasks, err := repos.ClaimWebhookDeliveries(ctx, cfg.BatchSize, lease)
+			if err != nil {
+				log.Error("failed to claim webhook deliveries", sl.Err(err))
+				continue
+			}
+
+			for _, task := range tasks {
+				result := deliver(ctx, client, task, cfg)
+
+				if result.Status != models.DELIVERY_DELIVERED {
+					log.Warn("webhook delivery failed",
+						slog.String("delivery_id", task.Delivery.ID.String()),
+						slog.Int("attempt", task.Delivery.Attempts+1),
+						slog.String("status", string(result.Status)),
+						slog.String("error", result.Error),
+					)
+				}
+
+				if err := repos.RecordDeliveryAttempt(ctx, task.Delivery.ID, result); err != nil {
+					log.Error("failed to record webhook delivery attempt", sl.Err(err))
+				}
+			}
+		}
+	}
+}
+
+func deliver(ctx context.Context, client sender, task models.WebhookTask, cfg config.Webhooks) models.DeliveryResult {
+	code, err := client.Send(ctx, task)
+
+	var result models.DeliveryResult
+
+	// code равен 0, если ответа не было: таймаут, отказ в соединении и т.п.
+	if code != 0 {
+		result.StatusCode = &code
+	}
+
+	if err == nil {
+		result.Status = models.DELIVERY_DELIVERED
+		return result
+	}
+
+	result.Error = err.Error()
+
+	attempts := task.Delivery.Attempts + 1
+	if attempts >= cfg.MaxAttempts {
+		result.Status = models.DELIVERY_FAILED
+		return result
+	}
+
+	result.Status = models.DELIVERY_PENDING
+	result.RetryIn = webhooks.Backoff(attempts, cfg.BaseBackoff, cfg.MaxBackoff)
+
+	return result
+}
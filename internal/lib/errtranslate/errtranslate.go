@@ -34,6 +34,16 @@ func TranslateValidationErrors(err validator.ValidationErrors) FieldErrors {
 			message = fmt.Sprintf("%s must be a valid uuid4", field)
 		case "gte":
 			message = fmt.Sprintf("%s must be greater than or equal to %s", field, e.Param())
+		case "lte":
+			message = fmt.Sprintf("%s must be less than or equal to %s", field, e.Param())
+		case "iso4217":
+			message = fmt.Sprintf("%s must be a valid ISO 4217 currency code", field)
+		case "http_url":
+			message = fmt.Sprintf("%s must be a valid http or https url", field)
+		case "max":
+			message = fmt.Sprintf("%s must be at most %s characters long", field, e.Param())
+		case "min":
+			message = fmt.Sprintf("%s must contain at least %s elements", field, e.Param())
 		case "oneof":
 			message = fmt.Sprintf("%s must be in (%s)", field, e.Param())
 
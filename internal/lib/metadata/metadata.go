@@ -0,0 +1,58 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// MaxSize - предельный размер метаданных транзакции в байтах
+const MaxSize = 4096
+
+// Validate допускает только JSON объект ограниченного размера, null равнозначен отсутствию метаданных
+func Validate(metadata json.RawMessage) error {
+	if IsNull(metadata) {
+		return nil
+	}
+
+	if len(metadata) > MaxSize {
+		return fmt.Errorf("metadata must be at most %d bytes", MaxSize)
+	}
+
+	var object map[string]any
+	if err := json.Unmarshal(metadata, &object); err != nil {
+		return errors.New("metadata must be a JSON object")
+	}
+
+	return nil
+}
+
+// Normalize возвращает метаданные для сохранения: null и пустое значение превращаются в nil,
+// объект записывается без пробелов с ключами по алфавиту. Одинаковые по смыслу метаданные дают
+// одинаковые байты, поэтому по ним можно сравнивать повторы запроса.
+func Normalize(metadata json.RawMessage) json.RawMessage {
+	if IsNull(metadata) {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(metadata))
+	decoder.UseNumber()
+
+	var object map[string]any
+	if err := decoder.Decode(&object); err != nil {
+		return metadata
+	}
+
+	normalized, err := json.Marshal(object)
+	if err != nil {
+		return metadata
+	}
+
+	return normalized
+}
+
+func IsNull(raw json.RawMessage) bool {
+	trimmed := bytes.TrimSpace(raw)
+	return len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null"))
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "wallets"
+
+// Результаты запроса баланса из кэша
+const (
+	CacheHit  = "hit"
+	CacheMiss = "miss"
+)
+
+// Результаты попытки взять блокировку кошелька
+const (
+	LockAcquired = "acquired"
+	LockBusy     = "busy"
+	LockError    = "error"
+)
+
+var (
+	// Operations - проведенные операции по типу и валюте
+	Operations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "operations_total",
+		Help:      "Balance operations applied, by operation type and currency.",
+	}, []string{"operation", "currency"})
+
+	// OperationAmount - сумма проведенных операций в минорных единицах валюты
+	OperationAmount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "operation_amount_total",
+		Help:      "Total amount of applied balance operations in minor currency units, by operation type and currency.",
+	}, []string{"operation", "currency"})
+
+	// InsufficientFunds - операции, отклоненные из-за нехватки средств
+	InsufficientFunds = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "insufficient_funds_total",
+		Help:      "Operations rejected because of insufficient funds, by operation type.",
+	}, []string{"operation"})
+
+	// BalanceCache - обращения к кэшу балансов в Redis
+	BalanceCache = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "balance_cache",
+		Name:      "requests_total",
+		Help:      "Balance cache lookups, by result (hit or miss).",
+	}, []string{"result"})
+
+	// LockAcquireDuration - сколько заняла попытка взять блокировку кошелька, включая повторы
+	LockAcquireDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "wallet_lock",
+		Name:      "acquire_duration_seconds",
+		Help:      "Time spent acquiring a wallet lock in Redis including retries, by result.",
+		Buckets:   []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"result"})
+
+	// LockFailures - блокировка не взята за все попытки, запрос получил ErrLockedWallet
+	LockFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "wallet_lock",
+		Name:      "failures_total",
+		Help:      "Wallet lock attempts that gave up because the wallet stayed locked.",
+	})
+
+	// LockRetries - повторные попытки взять занятую блокировку
+	LockRetries = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "wallet_lock",
+		Name:      "retries_total",
+		Help:      "Retries of wallet lock acquisition after finding the wallet locked.",
+	})
+)
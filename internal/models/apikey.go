@@ -0,0 +1,42 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type Scope string
+
+const (
+	SCOPE_WALLET_READ   Scope = "wallet:read"
+	SCOPE_WALLET_WRITE  Scope = "wallet:write"
+	SCOPE_WALLET_CREATE Scope = "wallet:create"
+	SCOPE_WEBHOOKS      Scope = "webhooks:manage"
+	// SCOPE_ADMIN - администрирование кошельков и выпуск ключей
+	SCOPE_ADMIN Scope = "admin"
+)
+
+// APIKey - ключ доступа к API. Сам ключ не хранится, только его хэш.
+// Пустой WalletIDs - доступ ко всем кошелькам.
+type APIKey struct {
+	ID        uuid.UUID   `json:"id"`
+	Name      string      `json:"name"`
+	Scopes    []Scope     `json:"scopes"`
+	WalletIDs []uuid.UUID `json:"wallet_ids"`
+	// Key - ключ в открытом виде, отдается только при выпуске и ротации
+	Key       string     `json:"key,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	RotatedAt *time.Time `json:"rotated_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+func (k APIKey) HasScope(scope Scope) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+// AllowsWallet сообщает, может ли ключ работать с кошельком
+func (k APIKey) AllowsWallet(walletID uuid.UUID) bool {
+	return len(k.WalletIDs) == 0 || slices.Contains(k.WalletIDs, walletID)
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"encoding/json"
+
+	"github.com/gofrs/uuid"
+)
+
+// TRANSFER - операция пакета, которая проводится парой транзакций TRANSFER_OUT и TRANSFER_IN
+const TRANSFER OperationType = "TRANSFER"
+
+type BatchItemStatus string
+
+const (
+	// BATCH_ITEM_APPLIED - операция проведена, BATCH_ITEM_FAILED - на ней пакет остановился,
+	// BATCH_ITEM_ROLLED_BACK - операция не проведена, потому что пакет откатился целиком
+	BATCH_ITEM_APPLIED     BatchItemStatus = "APPLIED"
+	BATCH_ITEM_FAILED      BatchItemStatus = "FAILED"
+	BATCH_ITEM_ROLLED_BACK BatchItemStatus = "ROLLED_BACK"
+)
+
+// BatchItem - одна операция пакета: DEPOSIT или WITHDRAW по WalletID, либо TRANSFER из WalletID в ToWalletID
+type BatchItem struct {
+	OperationType OperationType
+	WalletID      uuid.UUID
+	ToWalletID    uuid.UUID
+	Amount        int64
+	// Currency - ожидаемая валюта кошелька, пустая если клиент ее не указал
+	Currency          string
+	Description       string
+	Metadata          json.RawMessage
+	ExternalReference string
+}
+
+// BatchItemResult - результат операции пакета. Перевод дает две транзакции, остальные операции - одну.
+type BatchItemResult struct {
+	Index        int             `json:"index"`
+	Status       BatchItemStatus `json:"status"`
+	TransferID   uuid.NullUUID   `json:"transfer_id,omitzero"`
+	Transactions []Transactions  `json:"transactions,omitempty"`
+	Fee          *Fee            `json:"fee,omitempty"`
+	Error        string          `json:"error,omitempty"`
+}
+
+// BatchWalletIDs возвращает все кошельки, которые затрагивает пакет, в порядке SortWalletIDs
+func BatchWalletIDs(items []BatchItem) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.WalletID)
+		if item.OperationType == TRANSFER {
+			ids = append(ids, item.ToWalletID)
+		}
+	}
+
+	return SortWalletIDs(ids...)
+}
@@ -0,0 +1,21 @@
+package models
+
+const DefaultCurrency = "RUB"
+
+// currencyExponents - валюты ISO 4217, у которых число знаков после запятой отличается от 2
+var currencyExponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// CurrencyExponent возвращает количество минорных разрядов валюты:
+// сумма 150000 в RUB с экспонентой 2 означает 1500.00
+func CurrencyExponent(code string) int {
+	if exp, ok := currencyExponents[code]; ok {
+		return exp
+	}
+
+	return 2
+}
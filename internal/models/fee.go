@@ -0,0 +1,12 @@
+package models
+
+import "github.com/gofrs/uuid"
+
+// Fee - комиссия за операцию: транзакция FEE на кошельке плательщика и зачисление на кошелек доходов
+type Fee struct {
+	TransactionID   uuid.UUID `json:"transaction_id"`
+	Amount          int64     `json:"amount"`
+	Currency        string    `json:"currency"`
+	Exponent        int       `json:"exponent"`
+	RevenueWalletID uuid.UUID `json:"revenue_wallet_id"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type HoldStatus string
+
+const (
+	HOLD_ACTIVE   HoldStatus = "ACTIVE"
+	HOLD_CAPTURED HoldStatus = "CAPTURED"
+	HOLD_VOIDED   HoldStatus = "VOIDED"
+	HOLD_EXPIRED  HoldStatus = "EXPIRED"
+)
+
+// Hold - резерв средств кошелька. Пока холд активен, его сумма уменьшает
+// доступный баланс, но не учетный.
+type Hold struct {
+	ID        uuid.UUID  `db:"id" json:"id"`
+	WalletID  uuid.UUID  `db:"wallet_id" json:"wallet_id"`
+	Amount    int64      `db:"amount" json:"amount"`
+	Captured  int64      `db:"captured" json:"captured"`
+	Currency  string     `db:"currency" json:"currency"`
+	Status    HoldStatus `db:"status" json:"status"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
+}
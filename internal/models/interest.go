@@ -0,0 +1,71 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// InterestAccrual - проценты, начисленные кошельку за один день на остаток на конец дня.
+// AmountMicros - в миллионных долях минорной единицы, в баланс они попадают только с выплатой.
+type InterestAccrual struct {
+	ID            uuid.UUID     `json:"id"`
+	WalletID      uuid.UUID     `json:"wallet_id"`
+	AccrualDate   time.Time     `json:"accrual_date"`
+	Balance       int64         `json:"balance"`
+	AnnualRateBps int64         `json:"annual_rate_bps"`
+	AmountMicros  int64         `json:"amount_micros"`
+	PayoutID      uuid.NullUUID `json:"payout_id,omitzero"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+type InterestPayoutStatus string
+
+const (
+	PAYOUT_PAID InterestPayoutStatus = "PAID"
+	// PAYOUT_REJECTED - зачисление отклонено, например из-за max_balance. Повторно выплата не проводится.
+	PAYOUT_REJECTED InterestPayoutStatus = "REJECTED"
+)
+
+// InterestPayout - выплата процентов, начисленных за месяц Period, операцией INTEREST.
+// Если сумма после округления нулевая или выплата отклонена, транзакции нет.
+type InterestPayout struct {
+	ID            uuid.UUID            `json:"id"`
+	WalletID      uuid.UUID            `json:"wallet_id"`
+	Period        time.Time            `json:"period"`
+	Amount        int64                `json:"amount"`
+	Currency      string               `json:"currency"`
+	Exponent      int                  `json:"exponent"`
+	Status        InterestPayoutStatus `json:"status"`
+	TransactionID uuid.NullUUID        `json:"transaction_id,omitzero"`
+	Error         string               `json:"error,omitempty"`
+	CreatedAt     time.Time            `json:"created_at"`
+}
+
+// ExternalReference - ссылка, с которой проводится транзакция выплаты
+func (p InterestPayout) ExternalReference() string {
+	return fmt.Sprintf("interest:%s", p.ID)
+}
+
+// InterestBalance - остаток кошелька на конец дня, на который начисляются проценты
+type InterestBalance struct {
+	WalletID uuid.UUID
+	Type     string
+	Balance  int64
+}
+
+// InterestDue - кошелек с невыплаченными начислениями за месяц Period
+type InterestDue struct {
+	WalletID uuid.UUID
+	Period   time.Time
+}
+
+// InterestAccrualFilter - выборка начислений кошелька по выплате и датам начисления, To не включается
+type InterestAccrualFilter struct {
+	WalletID uuid.UUID
+	PayoutID uuid.NullUUID
+	From     *time.Time
+	To       *time.Time
+	Limit    int
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Системные счета главной книги, по одному на валюту
+const (
+	// ACCOUNT_EXTERNAL_FUNDING - источник пополнений извне
+	ACCOUNT_EXTERNAL_FUNDING = "external_funding"
+	// ACCOUNT_PAYOUTS - выплаты за пределы сервиса
+	ACCOUNT_PAYOUTS = "payouts"
+	// ACCOUNT_TRANSFERS_CLEARING - транзитный счет переводов, после обеих частей перевода его остаток не меняется
+	ACCOUNT_TRANSFERS_CLEARING = "transfers_clearing"
+	// ACCOUNT_FEES_CLEARING - транзитный счет комиссий между кошельком плательщика и кошельком доходов
+	ACCOUNT_FEES_CLEARING = "fees_clearing"
+	// ACCOUNT_INTEREST_EXPENSE - расходы на проценты, выплаченные на остатки кошельков
+	ACCOUNT_INTEREST_EXPENSE = "interest_expense"
+)
+
+// LedgerPosting - проводка операции: с каким системным счетом она проводится и какой стороной в ней выступает кошелек
+type LedgerPosting struct {
+	Counterparty  string
+	WalletDebited bool
+}
+
+var ledgerPostings = map[OperationType]LedgerPosting{
+	DEPOSIT:         {Counterparty: ACCOUNT_EXTERNAL_FUNDING},
+	WITHDRAW:        {Counterparty: ACCOUNT_PAYOUTS, WalletDebited: true},
+	TRANSFER_IN:     {Counterparty: ACCOUNT_TRANSFERS_CLEARING},
+	TRANSFER_OUT:    {Counterparty: ACCOUNT_TRANSFERS_CLEARING, WalletDebited: true},
+	HOLD_CAPTURE:    {Counterparty: ACCOUNT_PAYOUTS, WalletDebited: true},
+	REVERSAL_DEBIT:  {Counterparty: ACCOUNT_EXTERNAL_FUNDING, WalletDebited: true},
+	REVERSAL_CREDIT: {Counterparty: ACCOUNT_PAYOUTS},
+	FEE:             {Counterparty: ACCOUNT_FEES_CLEARING, WalletDebited: true},
+	FEE_INCOME:      {Counterparty: ACCOUNT_FEES_CLEARING},
+	INTEREST:        {Counterparty: ACCOUNT_INTEREST_EXPENSE},
+}
+
+// PostingFor возвращает проводку для типа операции
+func PostingFor(operationType OperationType) (LedgerPosting, bool) {
+	posting, ok := ledgerPostings[operationType]
+	return posting, ok
+}
+
+// CreditOperations возвращает операции, увеличивающие баланс кошелька
+func CreditOperations() []OperationType {
+	operations := make([]OperationType, 0, len(ledgerPostings))
+	for operationType, posting := range ledgerPostings {
+		if !posting.WalletDebited {
+			operations = append(operations, operationType)
+		}
+	}
+
+	return operations
+}
+
+// LedgerEntry - запись главной книги: сумма списывается с DebitAccountID и зачисляется на CreditAccountID
+type LedgerEntry struct {
+	ID              int64     `json:"id"`
+	TransactionID   uuid.UUID `json:"transaction_id"`
+	DebitAccountID  uuid.UUID `json:"debit_account_id"`
+	CreditAccountID uuid.UUID `json:"credit_account_id"`
+	Amount          int64     `json:"amount"`
+	Currency        string    `json:"currency"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// LedgerAccountBalance - остаток счета: сумма зачислений за вычетом списаний
+type LedgerAccountBalance struct {
+	AccountID uuid.UUID `json:"account_id"`
+	Code      string    `json:"code"`
+	Currency  string    `json:"currency"`
+	Balance   int64     `json:"balance"`
+}
+
+// LedgerMismatch - кошелек, у которого wallets.balance разошелся с суммой записей главной книги
+type LedgerMismatch struct {
+	WalletID      uuid.UUID `json:"wallet_id"`
+	Balance       int64     `json:"balance"`
+	LedgerBalance int64     `json:"ledger_balance"`
+}
+
+type LedgerReport struct {
+	SystemAccounts []LedgerAccountBalance `json:"system_accounts"`
+	Mismatches     []LedgerMismatch       `json:"mismatches"`
+}
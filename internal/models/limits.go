@@ -0,0 +1,53 @@
+package models
+
+import "github.com/gofrs/uuid"
+
+const (
+	LimitMaxWithdrawal     = "max_withdrawal"
+	LimitDailyWithdrawal   = "daily_withdrawal"
+	LimitMonthlyWithdrawal = "monthly_withdrawal"
+	LimitMaxBalance        = "max_balance"
+)
+
+// Limits - лимиты кошелька в минорных единицах валюты, 0 означает отсутствие ограничения
+type Limits struct {
+	MaxWithdrawal     int64 `json:"max_withdrawal"`
+	DailyWithdrawal   int64 `json:"daily_withdrawal"`
+	MonthlyWithdrawal int64 `json:"monthly_withdrawal"`
+	MaxBalance        int64 `json:"max_balance"`
+}
+
+// LimitOverrides - лимиты, переопределенные для конкретного кошелька.
+// nil означает значение по умолчанию из конфига.
+type LimitOverrides struct {
+	MaxWithdrawal     *int64 `json:"max_withdrawal"`
+	DailyWithdrawal   *int64 `json:"daily_withdrawal"`
+	MonthlyWithdrawal *int64 `json:"monthly_withdrawal"`
+	MaxBalance        *int64 `json:"max_balance"`
+}
+
+func (o LimitOverrides) Apply(defaults Limits) Limits {
+	limits := defaults
+
+	if o.MaxWithdrawal != nil {
+		limits.MaxWithdrawal = *o.MaxWithdrawal
+	}
+	if o.DailyWithdrawal != nil {
+		limits.DailyWithdrawal = *o.DailyWithdrawal
+	}
+	if o.MonthlyWithdrawal != nil {
+		limits.MonthlyWithdrawal = *o.MonthlyWithdrawal
+	}
+	if o.MaxBalance != nil {
+		limits.MaxBalance = *o.MaxBalance
+	}
+
+	return limits
+}
+
+// WalletLimits - переопределения кошелька и итоговые действующие лимиты
+type WalletLimits struct {
+	WalletID  uuid.UUID      `json:"wallet_id"`
+	Overrides LimitOverrides `json:"overrides"`
+	Effective Limits         `json:"effective"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	EventBalanceChanged = "wallet.balance_changed"
+	// EventStatusChanged - содержимое события WalletStatusChange
+	EventStatusChanged = "wallet.status_changed"
+)
+
+// OutboxEvent - событие, записанное в outbox в одной транзакции с изменением баланса
+type OutboxEvent struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	WalletID  uuid.UUID       `json:"wallet_id"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// BalanceChanged - содержимое события wallet.balance_changed
+type BalanceChanged struct {
+	TransactionID uuid.UUID     `json:"transaction_id"`
+	WalletID      uuid.UUID     `json:"wallet_id"`
+	OperationType OperationType `json:"operation_type"`
+	Amount        int64         `json:"amount"`
+	Currency      string        `json:"currency"`
+	Exponent      int           `json:"exponent"`
+	// Balance - учетный баланс после операции, Available - за вычетом холдов
+	Balance           int64     `json:"balance"`
+	Available         int64     `json:"available"`
+	ExternalReference string    `json:"external_reference,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type DriftKind string
+
+const (
+	// DRIFT_BALANCE - wallets.balance расходится с суммой транзакций кошелька
+	DRIFT_BALANCE DriftKind = "BALANCE"
+	// DRIFT_HELD - wallets.held расходится с суммой активных холдов
+	DRIFT_HELD DriftKind = "HELD"
+	// DRIFT_CACHE - кэш Redis расходится с базой
+	DRIFT_CACHE DriftKind = "CACHE"
+)
+
+// WalletRecount - сохраненное состояние кошелька и значения, пересчитанные по транзакциям и холдам
+type WalletRecount struct {
+	Wallet          Wallet
+	ComputedBalance int64
+	ComputedHeld    int64
+}
+
+// Drift - одно найденное расхождение. Expected - значение источника истины, Actual - расходящееся с ним.
+type Drift struct {
+	WalletID uuid.UUID `json:"wallet_id"`
+	Kind     DriftKind `json:"kind"`
+	Field    string    `json:"field"`
+	Expected any       `json:"expected"`
+	Actual   any       `json:"actual"`
+	Repaired bool      `json:"repaired"`
+}
+
+type ReconcileReport struct {
+	StartedAt        time.Time `json:"started_at"`
+	FinishedAt       time.Time `json:"finished_at"`
+	Wallets          int       `json:"wallets"`
+	LedgerMismatches int       `json:"ledger_mismatches"`
+	CacheDrifts      int       `json:"cache_drifts"`
+	CacheRepaired    int       `json:"cache_repaired"`
+	Drifts           []Drift   `json:"drifts"`
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type ScheduleStatus string
+
+const (
+	SCHEDULE_ACTIVE    ScheduleStatus = "ACTIVE"
+	SCHEDULE_PAUSED    ScheduleStatus = "PAUSED"
+	SCHEDULE_CANCELLED ScheduleStatus = "CANCELLED"
+	// SCHEDULE_COMPLETED - разовое расписание выполнено
+	SCHEDULE_COMPLETED ScheduleStatus = "COMPLETED"
+)
+
+// ScheduleTransitions - из каких статусов расписание можно перевести в данный
+var ScheduleTransitions = map[ScheduleStatus][]ScheduleStatus{
+	SCHEDULE_PAUSED:    {SCHEDULE_ACTIVE},
+	SCHEDULE_ACTIVE:    {SCHEDULE_PAUSED},
+	SCHEDULE_CANCELLED: {SCHEDULE_ACTIVE, SCHEDULE_PAUSED},
+}
+
+// Recurrence - период повторения расписания, пустой у разовой операции
+type Recurrence string
+
+const (
+	RECURRENCE_DAILY   Recurrence = "DAILY"
+	RECURRENCE_WEEKLY  Recurrence = "WEEKLY"
+	RECURRENCE_MONTHLY Recurrence = "MONTHLY"
+)
+
+// Schedule - отложенная или регулярная операция DEPOSIT, WITHDRAW или TRANSFER.
+// Occurrence - номер запуска NextRunAt, считая от StartAt с нуля.
+type Schedule struct {
+	ID            uuid.UUID      `json:"id"`
+	WalletID      uuid.UUID      `json:"wallet_id"`
+	ToWalletID    uuid.NullUUID  `json:"to_wallet_id,omitzero"`
+	OperationType OperationType  `json:"operation_type"`
+	Amount        int64          `json:"amount"`
+	Description   string         `json:"description,omitempty"`
+	Recurrence    Recurrence     `json:"recurrence,omitempty"`
+	StartAt       time.Time      `json:"start_at"`
+	NextRunAt     time.Time      `json:"next_run_at"`
+	Occurrence    int            `json:"occurrence"`
+	Status        ScheduleStatus `json:"status"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+// ExternalReference - ссылка, с которой проводится запуск NextRunAt.
+// Она уникальна в пределах кошелька, поэтому повтор запуска не проведет операцию дважды.
+func (s Schedule) ExternalReference() string {
+	return fmt.Sprintf("schedule:%s:%d", s.ID, s.Occurrence)
+}
+
+type ScheduleRunStatus string
+
+const (
+	RUN_SUCCEEDED ScheduleRunStatus = "SUCCEEDED"
+	// RUN_FAILED - операция отклонена, например из-за нехватки средств. Повторно запуск не выполняется.
+	RUN_FAILED ScheduleRunStatus = "FAILED"
+)
+
+// ScheduleRun - результат одного запуска расписания
+type ScheduleRun struct {
+	ID            uuid.UUID         `json:"id"`
+	ScheduleID    uuid.UUID         `json:"schedule_id"`
+	ScheduledFor  time.Time         `json:"scheduled_for"`
+	Status        ScheduleRunStatus `json:"status"`
+	TransactionID uuid.NullUUID     `json:"transaction_id,omitzero"`
+	TransferID    uuid.NullUUID     `json:"transfer_id,omitzero"`
+	Error         string            `json:"error,omitempty"`
+	ExecutedAt    time.Time         `json:"executed_at"`
+}
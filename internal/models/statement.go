@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Statement - шапка выписки по кошельку за период [From, To)
+type Statement struct {
+	WalletID       uuid.UUID
+	Currency       string
+	Exponent       int
+	From           time.Time
+	To             time.Time
+	OpeningBalance int64
+}
+
+// StatementLine - операция выписки. Amount со знаком: списания отрицательные.
+// Balance - баланс кошелька после операции.
+type StatementLine struct {
+	ID            uuid.UUID     `json:"id"`
+	OperationType OperationType `json:"operation_type"`
+	Amount        int64         `json:"amount"`
+	Balance       int64         `json:"balance"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+// StatementWriter принимает выписку построчно, не накапливая ее в памяти
+type StatementWriter interface {
+	Begin(statement Statement) error
+	Line(line StatementLine) error
+	End(closingBalance int64) error
+}
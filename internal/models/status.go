@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type WalletStatus string
+
+const (
+	// WALLET_ACTIVE - все операции разрешены
+	WALLET_ACTIVE WalletStatus = "ACTIVE"
+	// WALLET_FROZEN - списания запрещены, зачисления разрешены
+	WALLET_FROZEN WalletStatus = "FROZEN"
+	// WALLET_CLOSED - кошелек закрыт навсегда, операции запрещены
+	WALLET_CLOSED WalletStatus = "CLOSED"
+)
+
+// WalletStatusChange - запись журнала смены статуса кошелька
+type WalletStatusChange struct {
+	ID         uuid.UUID    `db:"id" json:"id"`
+	WalletID   uuid.UUID    `db:"wallet_id" json:"wallet_id"`
+	FromStatus WalletStatus `db:"from_status" json:"from_status"`
+	ToStatus   WalletStatus `db:"to_status" json:"to_status"`
+	ChangedBy  string       `db:"changed_by" json:"changed_by"`
+	Reason     string       `db:"reason" json:"reason"`
+	CreatedAt  time.Time    `db:"created_at" json:"created_at"`
+}
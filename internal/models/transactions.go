@@ -1,20 +1,94 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/gofrs/uuid"
 )
 
 const (
-	DEPOSIT  OperationType = "DEPOSIT"
-	WITHDRAW OperationType = "WITHDRAW"
+	DEPOSIT      OperationType = "DEPOSIT"
+	WITHDRAW     OperationType = "WITHDRAW"
+	TRANSFER_IN  OperationType = "TRANSFER_IN"
+	TRANSFER_OUT OperationType = "TRANSFER_OUT"
+	HOLD_CAPTURE OperationType = "HOLD_CAPTURE"
+	// REVERSAL_DEBIT отменяет DEPOSIT и списывает средства, REVERSAL_CREDIT отменяет WITHDRAW и возвращает их
+	REVERSAL_DEBIT  OperationType = "REVERSAL_DEBIT"
+	REVERSAL_CREDIT OperationType = "REVERSAL_CREDIT"
+	// FEE списывает комиссию за операцию, FEE_INCOME зачисляет ее на кошелек доходов
+	FEE        OperationType = "FEE"
+	FEE_INCOME OperationType = "FEE_INCOME"
+	// INTEREST зачисляет проценты, начисленные на остаток за месяц
+	INTEREST OperationType = "INTEREST"
 )
 
 type Transactions struct {
 	ID            uuid.UUID `db:"id"`
 	WalletID      uuid.UUID `db:"wallet_id"`
 	OperationType `db:"operation_type"`
-	Amount        int64     `db:"amount"`
-	Created_at    time.Time `db:"created_at"`
+	Amount        int64         `db:"amount"`
+	Currency      string        `db:"currency"`
+	Exponent      int           `db:"-"`
+	TransferID    uuid.NullUUID `db:"transfer_id" json:"TransferID,omitzero"`
+	HoldID        uuid.NullUUID `db:"hold_id" json:"HoldID,omitzero"`
+	ReversalOf    uuid.NullUUID `db:"reversal_of" json:"ReversalOf,omitzero"`
+	// FeeOf - операция, за которую списана или зачислена комиссия
+	FeeOf uuid.NullUUID `db:"fee_of" json:"FeeOf,omitzero"`
+	// Description, Metadata и ExternalReference передает клиент, чтобы связать операцию со своими системами
+	Description       string          `db:"description" json:"Description,omitempty"`
+	Metadata          json.RawMessage `db:"metadata" json:"Metadata,omitempty"`
+	ExternalReference string          `db:"external_reference" json:"ExternalReference,omitempty"`
+	Created_at        time.Time       `db:"created_at"`
+	// Fee - комиссия, взятая за эту операцию. Заполняется только в ответе на саму операцию.
+	Fee *Fee `db:"-" json:"Fee,omitempty"`
+	// Replayed - операция не проведена заново, а возвращена повтором запроса с тем же Idempotency-Key
+	Replayed bool `db:"-" json:"-"`
+}
+
+// TxOptions - необязательные параметры операции над балансом
+type TxOptions struct {
+	// IdempotencyKey - ключ из заголовка Idempotency-Key, пустой если клиент его не передал
+	IdempotencyKey string
+	// RequestHash - отпечаток тела запроса, с которым был получен IdempotencyKey
+	RequestHash string
+	// Currency - ожидаемая валюта кошелька, пустая если клиент ее не указал
+	Currency string
+	// Description, Metadata и ExternalReference сохраняются в транзакции как есть.
+	// ExternalReference уникален в пределах кошелька.
+	Description       string
+	Metadata          json.RawMessage
+	ExternalReference string
+}
+
+// Transfer - пара связанных транзакций перевода между кошельками
+type Transfer struct {
+	ID       uuid.UUID    `json:"id"`
+	Amount   int64        `json:"amount"`
+	Currency string       `json:"currency"`
+	Exponent int          `json:"exponent"`
+	Debit    Transactions `json:"debit"`
+	Credit   Transactions `json:"credit"`
+	Fee      *Fee         `json:"fee,omitempty"`
+}
+
+// TransactionFilter - фильтры выборки истории операций кошелька.
+// Пустые поля не участвуют в фильтрации.
+type TransactionFilter struct {
+	WalletID      uuid.UUID
+	OperationType OperationType
+	MinAmount     *int64
+	MaxAmount     *int64
+	// ExternalReference - точное совпадение со ссылкой клиента
+	ExternalReference string
+	From              *time.Time
+	To                *time.Time
+	After             *TransactionCursor
+	Limit             int
+}
+
+// TransactionCursor - позиция последней отданной записи при сортировке по (created_at, id) по убыванию
+type TransactionCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
 }
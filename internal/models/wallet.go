@@ -1,12 +1,55 @@
 package models
 
 import (
+	"bytes"
+	"slices"
+
 	"github.com/gofrs/uuid"
 )
 
 type OperationType string
 
+// DefaultWalletType - тип кошелька, если при создании он не указан
+const DefaultWalletType = "standard"
+
 type Wallet struct {
-	ID      uuid.UUID `db:"id"`
-	Balance int64     `db:"balance"`
+	ID uuid.UUID `db:"id"`
+	// Balance - учетный (ledger) баланс
+	Balance  int64        `db:"balance"`
+	Held     int64        `db:"held"`
+	Currency string       `db:"currency"`
+	Status   WalletStatus `db:"status"`
+	// OverdraftLimit - кредитная линия: на сколько баланс может уйти в минус
+	OverdraftLimit int64 `db:"overdraft_limit"`
+	// OwnerID - внешний идентификатор владельца, пустой у кошельков без владельца
+	OwnerID string `db:"owner_id"`
+	// Type - тип кошелька, по нему выбираются тарифы комиссий
+	Type string `db:"type"`
+}
+
+// Available - собственные средства кошелька за вычетом активных холдов.
+// При использовании кредитной линии значение отрицательное.
+func (w Wallet) Available() int64 {
+	return w.Balance - w.Held
+}
+
+// Spendable - сумма, которую можно списать с учетом кредитной линии
+func (w Wallet) Spendable() int64 {
+	return w.Available() + w.OverdraftLimit
+}
+
+// CreditHeadroom - неиспользованный остаток кредитной линии
+func (w Wallet) CreditHeadroom() int64 {
+	return min(w.OverdraftLimit, w.Spendable())
+}
+
+// SortWalletIDs возвращает уникальные id кошельков в детерминированном порядке.
+// Блокировки нескольких кошельков берутся только в этом порядке.
+func SortWalletIDs(ids ...uuid.UUID) []uuid.UUID {
+	sorted := slices.Clone(ids)
+	slices.SortFunc(sorted, func(a, b uuid.UUID) int {
+		return bytes.Compare(a.Bytes(), b.Bytes())
+	})
+
+	return slices.Compact(sorted)
 }
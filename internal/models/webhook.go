@@ -0,0 +1,71 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type DeliveryStatus string
+
+const (
+	DELIVERY_PENDING   DeliveryStatus = "PENDING"
+	DELIVERY_DELIVERED DeliveryStatus = "DELIVERED"
+	// DELIVERY_FAILED - исчерпаны попытки, доставить можно только вручную
+	DELIVERY_FAILED DeliveryStatus = "FAILED"
+)
+
+// WebhookSubscription - подписка на события. Без WalletID получает события всех кошельков.
+type WebhookSubscription struct {
+	ID       uuid.UUID     `json:"id"`
+	URL      string        `json:"url"`
+	WalletID uuid.NullUUID `json:"wallet_id,omitzero"`
+	// Secret - ключ HMAC-SHA256 подписи, отдается только при создании подписки
+	Secret    string    `json:"secret,omitempty"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookDelivery - запись журнала доставки одного события одной подписке
+type WebhookDelivery struct {
+	ID             uuid.UUID      `json:"id"`
+	SubscriptionID uuid.UUID      `json:"subscription_id"`
+	EventID        int64          `json:"event_id"`
+	EventType      string         `json:"event_type"`
+	Status         DeliveryStatus `json:"status"`
+	Attempts       int            `json:"attempts"`
+	NextAttemptAt  time.Time      `json:"next_attempt_at"`
+	LastStatusCode *int           `json:"last_status_code"`
+	LastError      *string        `json:"last_error"`
+	CreatedAt      time.Time      `json:"created_at"`
+	DeliveredAt    *time.Time     `json:"delivered_at"`
+}
+
+// WebhookTask - доставка, готовая к отправке, вместе с адресом, секретом и телом события
+type WebhookTask struct {
+	Delivery WebhookDelivery
+	URL      string
+	Secret   string
+	Event    OutboxEvent
+}
+
+// WebhookPayload - тело запроса, которое получает подписчик
+type WebhookPayload struct {
+	DeliveryID uuid.UUID       `json:"delivery_id"`
+	EventID    int64           `json:"event_id"`
+	Type       string          `json:"type"`
+	WalletID   uuid.UUID       `json:"wallet_id"`
+	Data       json.RawMessage `json:"data"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// DeliveryResult - итог одной попытки доставки
+type DeliveryResult struct {
+	Status DeliveryStatus
+	// StatusCode - код ответа подписчика, nil если ответа не было
+	StatusCode *int
+	Error      string
+	// RetryIn - задержка до следующей попытки, если Status остается DELIVERY_PENDING
+	RetryIn time.Duration
+}
@@ -0,0 +1,58 @@
+package filesink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"wallets/internal/models"
+)
+
+// Sink дописывает события outbox в локальный файл, по одному JSON на строку
+type Sink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func New(path string) (*Sink, error) {
+	const op = "outbox.filesink.New"
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Sink{file: file}, nil
+}
+
+func (s *Sink) Name() string {
+	return "file"
+}
+
+func (s *Sink) Publish(ctx context.Context, event models.OutboxEvent) error {
+	const op = "outbox.filesink.Publish"
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Событие считается доставленным только после записи на диск
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Sink) Close() error {
+	return s.file.Close()
+}
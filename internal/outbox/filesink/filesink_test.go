@@ -0,0 +1,56 @@
+package filesink
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"wallets/internal/models"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublish(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	// Файл дописывается, а не перезаписывается при перезапуске
+	require.NoError(t, os.WriteFile(path, []byte(`{"id":1}`+"\n"), 0o644))
+
+	sink, err := New(path)
+	require.NoError(t, err)
+
+	walletID, _ := uuid.NewV4()
+	event := models.OutboxEvent{
+		ID:        2,
+		Type:      models.EventBalanceChanged,
+		WalletID:  walletID,
+		Payload:   json.RawMessage(`{"amount":100}`),
+		CreatedAt: time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	require.NoError(t, sink.Publish(context.Background(), event))
+	require.NoError(t, sink.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	require.Len(t, lines, 2)
+
+	var written models.OutboxEvent
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &written))
+	assert.Equal(t, event, written)
+}
+
+func TestPublishClosedFile(t *testing.T) {
+	sink, err := New(filepath.Join(t.TempDir(), "events.jsonl"))
+	require.NoError(t, err)
+	require.NoError(t, sink.Close())
+
+	assert.Error(t, sink.Publish(context.Background(), models.OutboxEvent{ID: 1}))
+}
@@ -0,0 +1,49 @@
+package redisstream
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"wallets/internal/config"
+	"wallets/internal/models"
+)
+
+type streamAdder interface {
+	AddToStream(ctx context.Context, stream string, maxLen int64, values map[string]any) error
+}
+
+// Sink публикует события outbox в Redis Stream, одна запись на событие
+type Sink struct {
+	client streamAdder
+	stream string
+	maxLen int64
+}
+
+func New(client streamAdder, cfg config.OutboxRedisStream) *Sink {
+	return &Sink{
+		client: client,
+		stream: cfg.Stream,
+		maxLen: cfg.MaxLen,
+	}
+}
+
+func (s *Sink) Name() string {
+	return "redis_stream"
+}
+
+func (s *Sink) Publish(ctx context.Context, event models.OutboxEvent) error {
+	const op = "outbox.redisstream.Publish"
+
+	err := s.client.AddToStream(ctx, s.stream, s.maxLen, map[string]any{
+		"event_id":   event.ID,
+		"type":       event.Type,
+		"wallet_id":  event.WalletID.String(),
+		"payload":    string(event.Payload),
+		"created_at": event.CreatedAt.Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package redisstream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+	"wallets/internal/config"
+	"wallets/internal/models"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockStreamAdder struct {
+	mock.Mock
+}
+
+func (m *mockStreamAdder) AddToStream(ctx context.Context, stream string, maxLen int64, values map[string]any) error {
+	args := m.Called(ctx, stream, maxLen, values)
+	return args.Error(0)
+}
+
+func TestPublish(t *testing.T) {
+	walletID, _ := uuid.NewV4()
+
+	event := models.OutboxEvent{
+		ID:        42,
+		Type:      models.EventBalanceChanged,
+		WalletID:  walletID,
+		Payload:   json.RawMessage(`{"amount":100}`),
+		CreatedAt: time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	values := map[string]any{
+		"event_id":   int64(42),
+		"type":       models.EventBalanceChanged,
+		"wallet_id":  walletID.String(),
+		"payload":    `{"amount":100}`,
+		"created_at": "2025-04-01T12:00:00Z",
+	}
+
+	tests := []struct {
+		name      string
+		mockError error
+	}{
+		{
+			name: "Success",
+		},
+		{
+			name:      "redis error",
+			mockError: errors.New("connection refused"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := new(mockStreamAdder)
+			client.On("AddToStream", mock.Anything, "wallet-events", int64(1000), values).Return(tc.mockError).Once()
+
+			sink := New(client, config.OutboxRedisStream{Enabled: true, Stream: "wallet-events", MaxLen: 1000})
+
+			err := sink.Publish(context.Background(), event)
+			if tc.mockError != nil {
+				assert.ErrorIs(t, err, tc.mockError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			client.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package webhooksink
+
+import (
+	"context"
+	"fmt"
+	"wallets/internal/models"
+)
+
+type deliveriesEnqueuer interface {
+	EnqueueWebhookDeliveries(ctx context.Context, event models.OutboxEvent) (int64, error)
+}
+
+// Sink раскладывает события outbox по доставкам вебхуков. Сама отправка выполняется jobs/webhooks.
+type Sink struct {
+	repos deliveriesEnqueuer
+}
+
+func New(repos deliveriesEnqueuer) *Sink {
+	return &Sink{repos: repos}
+}
+
+func (s *Sink) Name() string {
+	return "webhooks"
+}
+
+func (s *Sink) Publish(ctx context.Context, event models.OutboxEvent) error {
+	const op = "outbox.webhooksink.Publish"
+
+	if _, err := s.repos.EnqueueWebhookDeliveries(ctx, event); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
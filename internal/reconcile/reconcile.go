@@ -0,0 +1,176 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+	"wallets/internal/models"
+
+	"github.com/gofrs/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+type walletsRecounter interface {
+	RecountWallets(ctx context.Context, after uuid.UUID, limit int) ([]models.WalletRecount, error)
+	GetBalance(ctx context.Context, walletID uuid.UUID) (models.Wallet, error)
+}
+
+type balanceCache interface {
+	GetCachedBalance(ctx context.Context, walletID uuid.UUID) (models.Wallet, error)
+	InvalidateCache(ctx context.Context, walletID uuid.UUID)
+}
+
+type Options struct {
+	// BatchSize - сколько кошельков пересчитывать за один запрос
+	BatchSize int
+	// RepairCache - удалять из Redis записи, расходящиеся с базой
+	RepairCache bool
+}
+
+// Run сверяет каждый кошелек: wallets.balance с суммой транзакций, wallets.held с активными холдами
+// и запись wallet:v2:<id> в Redis с базой. Отсутствие записи в кэше расхождением не считается.
+func Run(ctx context.Context, log *slog.Logger, db walletsRecounter, cache balanceCache, opts Options) (models.ReconcileReport, error) {
+	const op = "reconcile.Run"
+
+	log = log.With(slog.String("op", op))
+
+	report := models.ReconcileReport{
+		StartedAt: time.Now(),
+		Drifts:    []models.Drift{},
+	}
+
+	after := uuid.Nil
+
+	for {
+		recounts, err := db.RecountWallets(ctx, after, opts.BatchSize)
+		if err != nil {
+			return report, fmt.Errorf("%s: %w", op, err)
+		}
+
+		for _, recount := range recounts {
+			wallet := recount.Wallet
+
+			report.Wallets++
+
+			if recount.ComputedBalance != wallet.Balance {
+				report.LedgerMismatches++
+				report.Drifts = append(report.Drifts, models.Drift{
+					WalletID: wallet.ID,
+					Kind:     models.DRIFT_BALANCE,
+					Field:    "balance",
+					Expected: recount.ComputedBalance,
+					Actual:   wallet.Balance,
+				})
+			}
+
+			if recount.ComputedHeld != wallet.Held {
+				report.LedgerMismatches++
+				report.Drifts = append(report.Drifts, models.Drift{
+					WalletID: wallet.ID,
+					Kind:     models.DRIFT_HELD,
+					Field:    "held",
+					Expected: recount.ComputedHeld,
+					Actual:   wallet.Held,
+				})
+			}
+
+			drifts, err := checkCache(ctx, db, cache, wallet)
+			if err != nil {
+				return report, fmt.Errorf("%s: %w", op, err)
+			}
+
+			if len(drifts) > 0 {
+				report.CacheDrifts++
+
+				if opts.RepairCache {
+					cache.InvalidateCache(ctx, wallet.ID)
+					report.CacheRepaired++
+
+					for i := range drifts {
+						drifts[i].Repaired = true
+					}
+				}
+
+				report.Drifts = append(report.Drifts, drifts...)
+			}
+		}
+
+		if len(recounts) < opts.BatchSize {
+			break
+		}
+
+		after = recounts[len(recounts)-1].Wallet.ID
+
+		log.Debug("wallets reconciled", slog.Int("wallets", report.Wallets))
+	}
+
+	report.FinishedAt = time.Now()
+
+	return report, nil
+}
+
+// checkCache сравнивает кэш с базой. Кошелек мог измениться после пересчета,
+// поэтому при расхождении он перечитывается из базы и сравнивается повторно.
+func checkCache(ctx context.Context, db walletsRecounter, cache balanceCache, wallet models.Wallet) ([]models.Drift, error) {
+	cached, err := cache.GetCachedBalance(ctx, wallet.ID)
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		// Запись есть, но не читается - она испорчена и тоже подлежит удалению
+		return []models.Drift{{
+			WalletID: wallet.ID,
+			Kind:     models.DRIFT_CACHE,
+			Field:    "entry",
+			Expected: "readable entry",
+			Actual:   err.Error(),
+		}}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	drifts := compareCached(wallet, cached)
+	if len(drifts) == 0 {
+		return nil, nil
+	}
+
+	fresh, err := db.GetBalance(ctx, wallet.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return compareCached(fresh, cached), nil
+}
+
+func compareCached(wallet, cached models.Wallet) []models.Drift {
+	var drifts []models.Drift
+
+	add := func(field string, expected, actual any) {
+		if expected != actual {
+			drifts = append(drifts, models.Drift{
+				WalletID: wallet.ID,
+				Kind:     models.DRIFT_CACHE,
+				Field:    field,
+				Expected: expected,
+				Actual:   actual,
+			})
+		}
+	}
+
+	add("balance", wallet.Balance, cached.Balance)
+	add("held", wallet.Held, cached.Held)
+	add("currency", wallet.Currency, cached.Currency)
+	add("status", wallet.Status, cached.Status)
+	add("overdraft_limit", wallet.OverdraftLimit, cached.OverdraftLimit)
+	add("owner_id", wallet.OwnerID, cached.OwnerID)
+	add("type", wallet.Type, cached.Type)
+
+	return drifts
+}
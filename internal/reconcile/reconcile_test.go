@@ -0,0 +1,99 @@
+package reconcile
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"testing"
+	"wallets/internal/models"
+
+	"github.com/gofrs/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockRecounter struct {
+	mock.Mock
+}
+
+func (m *mockRecounter) RecountWallets(ctx context.Context, after uuid.UUID, limit int) ([]models.WalletRecount, error) {
+	args := m.Called(ctx, after, limit)
+	return args.Get(0).([]models.WalletRecount), args.Error(1)
+}
+
+func (m *mockRecounter) GetBalance(ctx context.Context, walletID uuid.UUID) (models.Wallet, error) {
+	args := m.Called(ctx, walletID)
+	return args.Get(0).(models.Wallet), args.Error(1)
+}
+
+type mockCache struct {
+	mock.Mock
+}
+
+func (m *mockCache) GetCachedBalance(ctx context.Context, walletID uuid.UUID) (models.Wallet, error) {
+	args := m.Called(ctx, walletID)
+	return args.Get(0).(models.Wallet), args.Error(1)
+}
+
+func (m *mockCache) InvalidateCache(ctx context.Context, walletID uuid.UUID) {
+	m.Called(ctx, walletID)
+}
+
+func TestRun(t *testing.T) {
+	clean, _ := uuid.NewV4()
+	drifted, _ := uuid.NewV4()
+	stale, _ := uuid.NewV4()
+	corrupt, _ := uuid.NewV4()
+	updated, _ := uuid.NewV4()
+
+	wallet := func(id uuid.UUID, balance int64) models.Wallet {
+		return models.Wallet{ID: id, Balance: balance, Currency: "RUB", Status: models.WALLET_ACTIVE}
+	}
+
+	db := new(mockRecounter)
+	cache := new(mockCache)
+
+	db.On("RecountWallets", mock.Anything, uuid.Nil, 3).Return([]models.WalletRecount{
+		{Wallet: wallet(clean, 100), ComputedBalance: 100},
+		{Wallet: wallet(drifted, 100), ComputedBalance: 90},
+		{Wallet: wallet(stale, 100), ComputedBalance: 100},
+	}, nil).Once()
+	db.On("RecountWallets", mock.Anything, stale, 3).Return([]models.WalletRecount{
+		{Wallet: wallet(corrupt, 100), ComputedBalance: 100},
+		{Wallet: wallet(updated, 100), ComputedBalance: 100},
+	}, nil).Once()
+
+	cache.On("GetCachedBalance", mock.Anything, clean).Return(models.Wallet{}, redis.Nil)
+	cache.On("GetCachedBalance", mock.Anything, drifted).Return(models.Wallet{}, redis.Nil)
+	cache.On("GetCachedBalance", mock.Anything, stale).Return(wallet(stale, 50), nil)
+	cache.On("GetCachedBalance", mock.Anything, corrupt).Return(models.Wallet{}, &strconv.NumError{Func: "ParseInt", Num: "", Err: strconv.ErrSyntax})
+	// Кошелек изменился после пересчета: кэш уже свежее, чем прочитанная строка
+	cache.On("GetCachedBalance", mock.Anything, updated).Return(wallet(updated, 120), nil)
+
+	db.On("GetBalance", mock.Anything, stale).Return(wallet(stale, 100), nil)
+	db.On("GetBalance", mock.Anything, updated).Return(wallet(updated, 120), nil)
+
+	cache.On("InvalidateCache", mock.Anything, stale).Once()
+	cache.On("InvalidateCache", mock.Anything, corrupt).Once()
+
+	log := slog.New(slog.DiscardHandler)
+
+	report, err := Run(context.Background(), log, db, cache, Options{BatchSize: 3, RepairCache: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, report.Wallets)
+	assert.Equal(t, 1, report.LedgerMismatches)
+	assert.Equal(t, 2, report.CacheDrifts)
+	assert.Equal(t, 2, report.CacheRepaired)
+
+	require.Len(t, report.Drifts, 3)
+	assert.Equal(t, models.Drift{WalletID: drifted, Kind: models.DRIFT_BALANCE, Field: "balance", Expected: int64(90), Actual: int64(100)}, report.Drifts[0])
+	assert.Equal(t, models.Drift{WalletID: stale, Kind: models.DRIFT_CACHE, Field: "balance", Expected: int64(100), Actual: int64(50), Repaired: true}, report.Drifts[1])
+	assert.Equal(t, corrupt, report.Drifts[2].WalletID)
+	assert.Equal(t, "entry", report.Drifts[2].Field)
+
+	db.AssertExpectations(t)
+	cache.AssertExpectations(t)
+}
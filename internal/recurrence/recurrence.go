@@ -0,0 +1,47 @@
+package recurrence
+
+import (
+	"time"
+	"wallets/internal/models"
+)
+
+// Occurrence возвращает n-й запуск (с нуля) расписания, начатого в start.
+// Ежемесячные запуски сохраняют день месяца start, в коротких месяцах переносятся на последний день.
+func Occurrence(start time.Time, recurrence models.Recurrence, n int) time.Time {
+	switch recurrence {
+	case models.RECURRENCE_DAILY:
+		return start.AddDate(0, 0, n)
+
+	case models.RECURRENCE_WEEKLY:
+		return start.AddDate(0, 0, 7*n)
+
+	case models.RECURRENCE_MONTHLY:
+		year, month, day := start.Date()
+
+		first := time.Date(year, month+time.Month(n), 1, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+		last := first.AddDate(0, 1, -1).Day()
+
+		return first.AddDate(0, 0, min(day, last)-1)
+	}
+
+	return start
+}
+
+// Advance возвращает расписание после запуска NextRunAt. Запуски, пропущенные до now, не догоняются:
+// следующим становится первый запуск позже now. Разовое расписание завершается.
+func Advance(schedule models.Schedule, now time.Time) models.Schedule {
+	if schedule.Recurrence == "" {
+		schedule.Status = models.SCHEDULE_COMPLETED
+		return schedule
+	}
+
+	n := schedule.Occurrence + 1
+	for !Occurrence(schedule.StartAt, schedule.Recurrence, n).After(now) {
+		n++
+	}
+
+	schedule.Occurrence = n
+	schedule.NextRunAt = Occurrence(schedule.StartAt, schedule.Recurrence, n)
+
+	return schedule
+}
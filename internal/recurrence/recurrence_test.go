@@ -0,0 +1,102 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+	"wallets/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOccurrence(t *testing.T) {
+	start := time.Date(2025, 1, 31, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		start      time.Time
+		recurrence models.Recurrence
+		n          int
+		expected   time.Time
+	}{
+		{
+			name:       "one-off",
+			start:      start,
+			recurrence: "",
+			n:          3,
+			expected:   start,
+		},
+		{
+			name:       "daily",
+			start:      start,
+			recurrence: models.RECURRENCE_DAILY,
+			n:          1,
+			expected:   time.Date(2025, 2, 1, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "weekly",
+			start:      start,
+			recurrence: models.RECURRENCE_WEEKLY,
+			n:          2,
+			expected:   time.Date(2025, 2, 14, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "monthly on the 1st",
+			start:      time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC),
+			recurrence: models.RECURRENCE_MONTHLY,
+			n:          3,
+			expected:   time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "monthly clamps to short month",
+			start:      start,
+			recurrence: models.RECURRENCE_MONTHLY,
+			n:          1,
+			expected:   time.Date(2025, 2, 28, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "monthly keeps the day after a short month",
+			start:      start,
+			recurrence: models.RECURRENCE_MONTHLY,
+			n:          2,
+			expected:   time.Date(2025, 3, 31, 9, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Occurrence(tc.start, tc.recurrence, tc.n))
+		})
+	}
+}
+
+func TestAdvance(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("one-off completes", func(t *testing.T) {
+		schedule := models.Schedule{StartAt: start, NextRunAt: start, Status: models.SCHEDULE_ACTIVE}
+
+		next := Advance(schedule, start.Add(time.Minute))
+
+		assert.Equal(t, models.SCHEDULE_COMPLETED, next.Status)
+		assert.Equal(t, start, next.NextRunAt)
+	})
+
+	t.Run("next occurrence", func(t *testing.T) {
+		schedule := models.Schedule{StartAt: start, NextRunAt: start, Recurrence: models.RECURRENCE_MONTHLY, Status: models.SCHEDULE_ACTIVE}
+
+		next := Advance(schedule, start.Add(time.Minute))
+
+		assert.Equal(t, models.SCHEDULE_ACTIVE, next.Status)
+		assert.Equal(t, 1, next.Occurrence)
+		assert.Equal(t, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), next.NextRunAt)
+	})
+
+	t.Run("missed occurrences are skipped", func(t *testing.T) {
+		schedule := models.Schedule{StartAt: start, NextRunAt: start, Recurrence: models.RECURRENCE_DAILY, Status: models.SCHEDULE_ACTIVE}
+
+		next := Advance(schedule, time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC))
+
+		assert.Equal(t, 10, next.Occurrence)
+		assert.Equal(t, time.Date(2025, 1, 11, 0, 0, 0, 0, time.UTC), next.NextRunAt)
+	})
+}
@@ -0,0 +1,71 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"time"
+	"wallets/internal/models"
+)
+
+// Строки входящего и исходящего остатка в CSV
+const (
+	csvOpeningBalance = "OPENING_BALANCE"
+	csvClosingBalance = "CLOSING_BALANCE"
+)
+
+var csvHeader = []string{"date", "transaction_id", "operation_type", "amount", "currency", "balance"}
+
+type csvWriter struct {
+	w         *csv.Writer
+	statement models.Statement
+}
+
+func newCSVWriter(out io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(out)}
+}
+
+func (s *csvWriter) Begin(statement models.Statement) error {
+	s.statement = statement
+
+	if err := s.w.Write(csvHeader); err != nil {
+		return err
+	}
+
+	return s.w.Write(s.balanceRow(statement.From, csvOpeningBalance, statement.OpeningBalance))
+}
+
+func (s *csvWriter) Line(line models.StatementLine) error {
+	return s.w.Write([]string{
+		line.CreatedAt.Format(time.RFC3339Nano),
+		line.ID.String(),
+		string(line.OperationType),
+		FormatAmount(line.Amount, s.statement.Exponent),
+		s.statement.Currency,
+		FormatAmount(line.Balance, s.statement.Exponent),
+	})
+}
+
+func (s *csvWriter) End(closingBalance int64) error {
+	if err := s.w.Write(s.balanceRow(s.statement.To, csvClosingBalance, closingBalance)); err != nil {
+		return err
+	}
+
+	s.w.Flush()
+	return s.w.Error()
+}
+
+func (s *csvWriter) Flush() error {
+	s.w.Flush()
+	return s.w.Error()
+}
+
+func (s *csvWriter) balanceRow(date time.Time, kind string, balance int64) []string {
+	return []string{
+		date.Format(time.RFC3339Nano),
+		"",
+		kind,
+		"",
+		s.statement.Currency,
+		FormatAmount(balance, s.statement.Exponent),
+	}
+}
@@ -0,0 +1,82 @@
+package statement
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+	"wallets/internal/models"
+
+	"github.com/gofrs/uuid"
+)
+
+// jsonHeader - начало документа до массива операций. Суммы в минорных единицах, как и в остальном API.
+type jsonHeader struct {
+	WalletID       uuid.UUID `json:"wallet_id"`
+	Currency       string    `json:"currency"`
+	Exponent       int       `json:"exponent"`
+	From           time.Time `json:"from"`
+	To             time.Time `json:"to"`
+	OpeningBalance int64     `json:"opening_balance"`
+}
+
+type jsonWriter struct {
+	w     *bufio.Writer
+	lines int
+}
+
+func newJSONWriter(out io.Writer) *jsonWriter {
+	return &jsonWriter{w: bufio.NewWriter(out)}
+}
+
+// Begin пишет поля шапки и открывает массив transactions. Документ закрывается в End,
+// поэтому поля шапки дописываются в открытый объект без закрывающей скобки.
+func (s *jsonWriter) Begin(statement models.Statement) error {
+	header, err := json.Marshal(jsonHeader{
+		WalletID:       statement.WalletID,
+		Currency:       statement.Currency,
+		Exponent:       statement.Exponent,
+		From:           statement.From,
+		To:             statement.To,
+		OpeningBalance: statement.OpeningBalance,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.w.Write(header[:len(header)-1])
+	_, err = s.w.WriteString(`,"transactions":[`)
+	return err
+}
+
+func (s *jsonWriter) Line(line models.StatementLine) error {
+	data, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+
+	if s.lines > 0 {
+		s.w.WriteByte(',')
+	}
+	s.lines++
+
+	_, err = s.w.Write(data)
+	return err
+}
+
+func (s *jsonWriter) End(closingBalance int64) error {
+	data, err := json.Marshal(closingBalance)
+	if err != nil {
+		return err
+	}
+
+	s.w.WriteString(`],"closing_balance":`)
+	s.w.Write(data)
+	s.w.WriteString("}\n")
+
+	return s.w.Flush()
+}
+
+func (s *jsonWriter) Flush() error {
+	return s.w.Flush()
+}
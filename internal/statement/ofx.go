@@ -0,0 +1,108 @@
+package statement
+
+import (
+	"bufio"
+	"encoding/xml"
+	"io"
+	"time"
+	"wallets/internal/models"
+)
+
+// Выписка в OFX 2.2 как банковский счет: ACCTID - идентификатор кошелька.
+// Баланса после каждой операции в OFX нет, исходящий остаток передается в LEDGERBAL.
+const (
+	ofxBankID      = "WALLETS"
+	ofxAccountType = "CHECKING"
+	ofxDateLayout  = "20060102150405.000[0:GMT]"
+)
+
+const ofxHeader = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+`
+
+type ofxTransaction struct {
+	XMLName xml.Name `xml:"STMTTRN"`
+	Type    string   `xml:"TRNTYPE"`
+	Posted  string   `xml:"DTPOSTED"`
+	Amount  string   `xml:"TRNAMT"`
+	FITID   string   `xml:"FITID"`
+	Name    string   `xml:"NAME"`
+	Memo    string   `xml:"MEMO"`
+}
+
+type ofxWriter struct {
+	w         *bufio.Writer
+	enc       *xml.Encoder
+	statement models.Statement
+}
+
+func newOFXWriter(out io.Writer) *ofxWriter {
+	w := bufio.NewWriter(out)
+	return &ofxWriter{w: w, enc: xml.NewEncoder(w)}
+}
+
+func (s *ofxWriter) Begin(statement models.Statement) error {
+	s.statement = statement
+
+	s.w.WriteString(ofxHeader)
+	s.w.WriteString("<OFX><SIGNONMSGSRSV1><SONRS><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>")
+	s.writeElement("DTSERVER", ofxDate(time.Now()))
+	s.w.WriteString("<LANGUAGE>ENG</LANGUAGE></SONRS></SIGNONMSGSRSV1>")
+	s.w.WriteString("<BANKMSGSRSV1><STMTTRNRS><TRNUID>0</TRNUID><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS><STMTRS>")
+	s.writeElement("CURDEF", statement.Currency)
+	s.w.WriteString("<BANKACCTFROM>")
+	s.writeElement("BANKID", ofxBankID)
+	s.writeElement("ACCTID", statement.WalletID.String())
+	s.writeElement("ACCTTYPE", ofxAccountType)
+	s.w.WriteString("</BANKACCTFROM><BANKTRANLIST>")
+	s.writeElement("DTSTART", ofxDate(statement.From))
+	s.writeElement("DTEND", ofxDate(statement.To))
+
+	return s.enc.Flush()
+}
+
+func (s *ofxWriter) Line(line models.StatementLine) error {
+	trnType := "CREDIT"
+	if line.Amount < 0 {
+		trnType = "DEBIT"
+	}
+
+	return s.enc.Encode(ofxTransaction{
+		Type:   trnType,
+		Posted: ofxDate(line.CreatedAt),
+		Amount: FormatAmount(line.Amount, s.statement.Exponent),
+		FITID:  line.ID.String(),
+		Name:   string(line.OperationType),
+		Memo:   "balance " + FormatAmount(line.Balance, s.statement.Exponent),
+	})
+}
+
+func (s *ofxWriter) End(closingBalance int64) error {
+	s.w.WriteString("</BANKTRANLIST><LEDGERBAL>")
+	s.writeElement("BALAMT", FormatAmount(closingBalance, s.statement.Exponent))
+	s.writeElement("DTASOF", ofxDate(s.statement.To))
+	s.w.WriteString("</LEDGERBAL></STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>\n")
+
+	if err := s.enc.Flush(); err != nil {
+		return err
+	}
+
+	return s.w.Flush()
+}
+
+func (s *ofxWriter) Flush() error {
+	if err := s.enc.Flush(); err != nil {
+		return err
+	}
+
+	return s.w.Flush()
+}
+
+func (s *ofxWriter) writeElement(name, value string) {
+	s.enc.EncodeElement(value, xml.StartElement{Name: xml.Name{Local: name}})
+	s.enc.Flush()
+}
+
+func ofxDate(t time.Time) string {
+	return t.UTC().Format(ofxDateLayout)
+}
@@ -0,0 +1,77 @@
+// Package statement сериализует выписку по кошельку в форматы для бухгалтерии
+package statement
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"wallets/internal/models"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+	FormatOFX  = "ofx"
+)
+
+var ErrUnknownFormat = errors.New("unknown statement format")
+
+// Writer - построчный writer выписки. Flush сбрасывает в out то, что накопилось в буфере.
+type Writer interface {
+	models.StatementWriter
+	Flush() error
+}
+
+// NewWriter возвращает построчный writer выписки в формате format.
+// Вывод буферизуется и сбрасывается в out по мере заполнения буфера, в Flush и в End.
+func NewWriter(format string, out io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(out), nil
+	case FormatJSON:
+		return newJSONWriter(out), nil
+	case FormatOFX:
+		return newOFXWriter(out), nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// ContentType возвращает MIME тип формата
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatOFX:
+		return "application/x-ofx"
+	default:
+		return "application/json; charset=utf-8"
+	}
+}
+
+// FormatAmount переводит сумму в минорных единицах в десятичную строку: 12345, 2 -> "123.45"
+func FormatAmount(amount int64, exponent int) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+	}
+
+	digits := strconv.FormatUint(absAmount(amount), 10)
+	if exponent <= 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+func absAmount(amount int64) uint64 {
+	if amount < 0 {
+		return uint64(-(amount + 1)) + 1
+	}
+	return uint64(amount)
+}
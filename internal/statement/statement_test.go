@@ -0,0 +1,86 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"testing"
+	"time"
+	"wallets/internal/models"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		amount   int64
+		exponent int
+		expected string
+	}{
+		{12345, 2, "123.45"},
+		{-5, 2, "-0.05"},
+		{0, 2, "0.00"},
+		{700, 0, "700"},
+		{1, 3, "0.001"},
+		{-9223372036854775808, 2, "-92233720368547758.08"},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.expected, FormatAmount(tc.amount, tc.exponent))
+	}
+}
+
+func TestWriters(t *testing.T) {
+	walletID, _ := uuid.NewV4()
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	statement := models.Statement{WalletID: walletID, Currency: "RUB", Exponent: 2, From: from, To: from.AddDate(0, 1, 0), OpeningBalance: 1000}
+
+	write := func(t *testing.T, format string, lines int) []byte {
+		var out bytes.Buffer
+
+		w, err := NewWriter(format, &out)
+		require.NoError(t, err)
+
+		require.NoError(t, w.Begin(statement))
+		balance := statement.OpeningBalance
+		for i := range lines {
+			id, _ := uuid.NewV4()
+			balance += 100
+			require.NoError(t, w.Line(models.StatementLine{ID: id, OperationType: models.DEPOSIT, Amount: 100, Balance: balance, CreatedAt: from.Add(time.Duration(i) * time.Hour)}))
+		}
+		require.NoError(t, w.End(balance))
+
+		return out.Bytes()
+	}
+
+	for _, lines := range []int{0, 3} {
+		var doc struct {
+			OpeningBalance int64                  `json:"opening_balance"`
+			Transactions   []models.StatementLine `json:"transactions"`
+			ClosingBalance int64                  `json:"closing_balance"`
+		}
+		require.NoError(t, json.Unmarshal(write(t, FormatJSON, lines), &doc))
+		assert.Len(t, doc.Transactions, lines)
+		assert.Equal(t, int64(1000+100*lines), doc.ClosingBalance)
+
+		csv := write(t, FormatCSV, lines)
+		assert.Equal(t, lines+3, bytes.Count(csv, []byte("\n")))
+
+		// OFX 2.x - корректный XML
+		dec := xml.NewDecoder(bytes.NewReader(write(t, FormatOFX, lines)))
+		for {
+			_, err := dec.Token()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+		}
+	}
+
+	_, err := NewWriter("xlsx", io.Discard)
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"wallets/internal/herrors"
+	"wallets/internal/models"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	tableAPIKeys = "api_keys"
+
+	apiKeyColumns = "id, name, scopes, wallet_ids, created_at, rotated_at, revoked_at"
+)
+
+func (r *PostgresRepos) CreateAPIKey(ctx context.Context, name string, scopes []models.Scope, walletIDs []uuid.UUID, keyHash string) (models.APIKey, error) {
+	const op = "storage.Postgres.CreateAPIKey"
+
+	query := fmt.Sprintf(`INSERT INTO %s (name, key_hash, scopes, wallet_ids) VALUES ($1, $2, $3, $4::uuid[])
+		RETURNING %s`, tableAPIKeys, apiKeyColumns)
+
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, name, keyHash, scopeStrings(scopes), uuidStrings(walletIDs)))
+	if err != nil {
+		return models.APIKey{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return key, nil
+}
+
+// RotateAPIKey заменяет хэш действующего ключа: старый ключ перестает работать сразу,
+// идентификатор, права и ограничения по кошелькам сохраняются
+func (r *PostgresRepos) RotateAPIKey(ctx context.Context, id uuid.UUID, keyHash string) (models.APIKey, error) {
+	const op = "storage.Postgres.RotateAPIKey"
+
+	query := fmt.Sprintf(`UPDATE %s SET key_hash = $2, rotated_at = now()
+		WHERE id = $1 AND revoked_at IS NULL
+		RETURNING %s`, tableAPIKeys, apiKeyColumns)
+
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, id, keyHash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = herrors.ErrAPIKeyNotFound
+		}
+		return models.APIKey{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return key, nil
+}
+
+func (r *PostgresRepos) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	const op = "storage.Postgres.RevokeAPIKey"
+
+	query := fmt.Sprintf("UPDATE %s SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL", tableAPIKeys)
+
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, herrors.ErrAPIKeyNotFound)
+	}
+
+	return nil
+}
+
+// FindAPIKey ищет действующий ключ по хэшу
+func (r *PostgresRepos) FindAPIKey(ctx context.Context, keyHash string) (models.APIKey, error) {
+	const op = "storage.Postgres.FindAPIKey"
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE key_hash = $1 AND revoked_at IS NULL", apiKeyColumns, tableAPIKeys)
+
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, keyHash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = herrors.ErrAPIKeyNotFound
+		}
+		return models.APIKey{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return key, nil
+}
+
+func scanAPIKey(row scanner) (models.APIKey, error) {
+	key := models.APIKey{}
+
+	// database/sql не умеет сканировать массивы, их разбирает pgtype
+	arrays := pgtype.NewMap()
+	var scopes, walletIDs []string
+
+	if err := row.Scan(&key.ID, &key.Name, arrays.SQLScanner(&scopes), arrays.SQLScanner(&walletIDs),
+		&key.CreatedAt, &key.RotatedAt, &key.RevokedAt); err != nil {
+		return models.APIKey{}, err
+	}
+
+	key.Scopes = make([]models.Scope, 0, len(scopes))
+	for _, scope := range scopes {
+		key.Scopes = append(key.Scopes, models.Scope(scope))
+	}
+
+	key.WalletIDs = make([]uuid.UUID, 0, len(walletIDs))
+	for _, walletID := range walletIDs {
+		id, err := uuid.FromString(walletID)
+		if err != nil {
+			return models.APIKey{}, err
+		}
+		key.WalletIDs = append(key.WalletIDs, id)
+	}
+
+	return key, nil
+}
+
+func scopeStrings(scopes []models.Scope) []string {
+	values := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		values = append(values, string(scope))
+	}
+	return values
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	values := make([]string, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, id.String())
+	}
+	return values
+}
@@ -0,0 +1,188 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"wallets/internal/herrors"
+	"wallets/internal/models"
+
+	"github.com/gofrs/uuid"
+)
+
+// ApplyBatch проводит операции пакета по порядку в одной транзакции: либо все, либо ни одной.
+// Все затронутые кошельки и кошельки доходов для комиссий блокируются заранее в порядке models.SortWalletIDs.
+// Ошибка отдельной операции возвращается как *herrors.BatchItemError с ее индексом.
+func (r *PostgresRepos) ApplyBatch(ctx context.Context, items []models.BatchItem) ([]models.BatchItemResult, error) {
+	const op = "storage.Postgres.ApplyBatch"
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer tx.Rollback()
+
+	revenueIDs, err := r.feeRevenueWallets(ctx, tx, items)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	wallets := make(map[uuid.UUID]*models.Wallet)
+	for _, id := range models.SortWalletIDs(append(models.BatchWalletIDs(items), revenueIDs...)...) {
+		wallet, err := lockWallet(ctx, tx, id)
+		if err != nil {
+			if index := batchItemIndex(items, id); index >= 0 {
+				err = &herrors.BatchItemError{Index: index, Err: err}
+			} else {
+				err = revenueWalletError(err)
+			}
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		wallets[id] = &wallet
+	}
+
+	results := make([]models.BatchItemResult, 0, len(items))
+	for i, item := range items {
+		result, err := r.applyBatchItem(ctx, tx, wallets, item)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, &herrors.BatchItemError{Index: i, Err: err})
+		}
+
+		result.Index = i
+		results = append(results, result)
+	}
+
+	if err := saveBalances(ctx, tx, wallets); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return results, nil
+}
+
+// applyBatchItem проводит одну операцию пакета по кошелькам, заблокированным в ApplyBatch
+func (r *PostgresRepos) applyBatchItem(ctx context.Context, tx *sql.Tx, wallets map[uuid.UUID]*models.Wallet, item models.BatchItem) (models.BatchItemResult, error) {
+	opts := models.TxOptions{
+		Description:       item.Description,
+		Metadata:          item.Metadata,
+		ExternalReference: item.ExternalReference,
+	}
+
+	from := wallets[item.WalletID]
+
+	if item.Currency != "" && item.Currency != from.Currency {
+		return models.BatchItemResult{}, herrors.ErrCurrencyMismatch
+	}
+
+	if item.OperationType != models.TRANSFER {
+		if err := r.applyOperation(ctx, tx, from, item.OperationType, item.Amount); err != nil {
+			return models.BatchItemResult{}, err
+		}
+
+		transaction, err := insertTransaction(ctx, tx, models.Transactions{
+			WalletID:      from.ID,
+			OperationType: item.OperationType,
+			Amount:        item.Amount,
+			Currency:      from.Currency,
+		}, opts)
+		if err != nil {
+			return models.BatchItemResult{}, err
+		}
+
+		if err := recordBalanceChanged(ctx, tx, transaction, *from); err != nil {
+			return models.BatchItemResult{}, err
+		}
+
+		var fee *models.Fee
+		if item.OperationType == models.WITHDRAW {
+			fee, err = r.chargeFee(ctx, tx, wallets, models.WITHDRAW, transaction)
+			if err != nil {
+				return models.BatchItemResult{}, err
+			}
+		}
+
+		return models.BatchItemResult{
+			Status:       models.BATCH_ITEM_APPLIED,
+			Transactions: []models.Transactions{transaction},
+			Fee:          fee,
+		}, nil
+	}
+
+	if item.WalletID == item.ToWalletID {
+		return models.BatchItemResult{}, herrors.ErrSameWallet
+	}
+
+	to := wallets[item.ToWalletID]
+
+	if err := r.applyTransfer(ctx, tx, from, to, item.Amount); err != nil {
+		return models.BatchItemResult{}, err
+	}
+
+	transferID, err := uuid.NewV4()
+	if err != nil {
+		return models.BatchItemResult{}, err
+	}
+
+	link := uuid.NullUUID{UUID: transferID, Valid: true}
+
+	// Описание и метаданные достаются обеим сторонам перевода, а ссылка клиента только списанию:
+	// она уникальна в пределах кошелька
+	debit, err := insertTransaction(ctx, tx, models.Transactions{
+		WalletID:      from.ID,
+		OperationType: models.TRANSFER_OUT,
+		Amount:        item.Amount,
+		Currency:      from.Currency,
+		TransferID:    link,
+	}, opts)
+	if err != nil {
+		return models.BatchItemResult{}, err
+	}
+
+	opts.ExternalReference = ""
+
+	credit, err := insertTransaction(ctx, tx, models.Transactions{
+		WalletID:      to.ID,
+		OperationType: models.TRANSFER_IN,
+		Amount:        item.Amount,
+		Currency:      to.Currency,
+		TransferID:    link,
+	}, opts)
+	if err != nil {
+		return models.BatchItemResult{}, err
+	}
+
+	if err := recordBalanceChanged(ctx, tx, debit, *from); err != nil {
+		return models.BatchItemResult{}, err
+	}
+
+	if err := recordBalanceChanged(ctx, tx, credit, *to); err != nil {
+		return models.BatchItemResult{}, err
+	}
+
+	fee, err := r.chargeFee(ctx, tx, wallets, models.TRANSFER, debit)
+	if err != nil {
+		return models.BatchItemResult{}, err
+	}
+
+	return models.BatchItemResult{
+		Status:       models.BATCH_ITEM_APPLIED,
+		TransferID:   link,
+		Transactions: []models.Transactions{debit, credit},
+		Fee:          fee,
+	}, nil
+}
+
+// batchItemIndex возвращает индекс первой операции пакета, затрагивающей кошелек, или -1
+func batchItemIndex(items []models.BatchItem, walletID uuid.UUID) int {
+	for i, item := range items {
+		if item.WalletID == walletID || (item.OperationType == models.TRANSFER && item.ToWalletID == walletID) {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,227 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"wallets/internal/herrors"
+	"wallets/internal/models"
+
+	"github.com/gofrs/uuid"
+)
+
+// FeeRevenueWallets возвращает кошельки доходов, на которые операции items могут зачислить комиссию.
+// Storage блокирует их в Redis вместе с кошельками операций.
+func (r *PostgresRepos) FeeRevenueWallets(ctx context.Context, items []models.BatchItem) ([]uuid.UUID, error) {
+	const op = "storage.Postgres.FeeRevenueWallets"
+
+	ids, err := r.feeRevenueWallets(ctx, r.db, items)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ids, nil
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// feeRevenueWallets определяет кошельки доходов по типу и валюте плательщиков. Они не меняются
+// после создания кошелька, поэтому читаются без блокировки, до первой заблокированной строки.
+// Неизвестные кошельки пропускаются: ошибку вернет их блокировка.
+func (r *PostgresRepos) feeRevenueWallets(ctx context.Context, q queryer, items []models.BatchItem) ([]uuid.UUID, error) {
+	payers := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.OperationType == models.WITHDRAW || item.OperationType == models.TRANSFER {
+			payers = append(payers, item.WalletID.String())
+		}
+	}
+
+	if len(payers) == 0 {
+		return nil, nil
+	}
+
+	query := fmt.Sprintf("SELECT id, type, currency FROM %s WHERE id = ANY($1::uuid[])", tableWallets)
+	rows, err := q.QueryContext(ctx, query, payers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type payer struct {
+		walletType string
+		currency   string
+	}
+
+	wallets := make(map[uuid.UUID]payer, len(payers))
+	for rows.Next() {
+		var id uuid.UUID
+		var p payer
+		if err := rows.Scan(&id, &p.walletType, &p.currency); err != nil {
+			return nil, err
+		}
+		wallets[id] = p
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var ids []uuid.UUID
+	for _, item := range items {
+		p, ok := wallets[item.WalletID]
+		if !ok || r.fees.Fee(p.walletType, item.OperationType, item.Amount) <= 0 {
+			continue
+		}
+
+		if id, ok := r.fees.RevenueWallet(p.currency); ok && id != item.WalletID {
+			ids = append(ids, id)
+		}
+	}
+
+	return models.SortWalletIDs(ids...), nil
+}
+
+// lockWallets блокирует кошельки операции вместе с кошельками доходов revenueIDs в порядке
+// models.SortWalletIDs, чтобы встречные операции не уходили в deadlock. Отсутствующий кошелек
+// доходов - ошибка конфигурации ErrNoRevenueWallet.
+func lockWallets(ctx context.Context, tx *sql.Tx, walletIDs, revenueIDs []uuid.UUID) (map[uuid.UUID]*models.Wallet, error) {
+	wallets := make(map[uuid.UUID]*models.Wallet, len(walletIDs)+len(revenueIDs))
+
+	for _, id := range models.SortWalletIDs(slices.Concat(walletIDs, revenueIDs)...) {
+		wallet, err := lockWallet(ctx, tx, id)
+		if err != nil {
+			if !slices.Contains(walletIDs, id) {
+				err = revenueWalletError(err)
+			}
+			return nil, err
+		}
+		wallets[id] = &wallet
+	}
+
+	return wallets, nil
+}
+
+func revenueWalletError(err error) error {
+	if errors.Is(err, herrors.ErrNXUUID) {
+		return herrors.ErrNoRevenueWallet
+	}
+
+	return err
+}
+
+// chargeFee берет комиссию за операцию main по тарифу operationType: списывает ее транзакцией FEE
+// с кошелька main и зачисляет транзакцией FEE_INCOME на кошелек доходов в той же валюте.
+// wallets - кошельки, заблокированные в транзакции вместе с кошельками доходов из feeRevenueWallets.
+// Балансы сохраняет вызывающий. Без комиссии возвращает nil.
+func (r *PostgresRepos) chargeFee(ctx context.Context, tx *sql.Tx, wallets map[uuid.UUID]*models.Wallet, operationType models.OperationType, main models.Transactions) (*models.Fee, error) {
+	payer := wallets[main.WalletID]
+
+	amount := r.fees.Fee(payer.Type, operationType, main.Amount)
+	if amount <= 0 {
+		return nil, nil
+	}
+
+	revenueID, ok := r.fees.RevenueWallet(payer.Currency)
+	if !ok {
+		return nil, herrors.ErrNoRevenueWallet
+	}
+
+	// Кошелек доходов не платит комиссию сам себе
+	if revenueID == payer.ID {
+		return nil, nil
+	}
+
+	if payer.Spendable() < amount {
+		return nil, herrors.ErrInsufficientFunds
+	}
+
+	revenue, ok := wallets[revenueID]
+	if !ok {
+		return nil, fmt.Errorf("revenue wallet %s is not locked", revenueID)
+	}
+
+	// Ошибки кошелька доходов - ошибка конфигурации, а не клиента
+	if revenue.Currency != payer.Currency || ensureCanCredit(*revenue) != nil {
+		return nil, herrors.ErrNoRevenueWallet
+	}
+
+	// max_balance кошелька доходов не проверяется: он не ограничивается этим лимитом, см. effectiveLimits
+	payer.Balance -= amount
+	revenue.Balance += amount
+
+	link := uuid.NullUUID{UUID: main.ID, Valid: true}
+
+	debit, err := insertTransaction(ctx, tx, models.Transactions{
+		WalletID:      payer.ID,
+		OperationType: models.FEE,
+		Amount:        amount,
+		Currency:      payer.Currency,
+		FeeOf:         link,
+	}, models.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	credit, err := insertTransaction(ctx, tx, models.Transactions{
+		WalletID:      revenue.ID,
+		OperationType: models.FEE_INCOME,
+		Amount:        amount,
+		Currency:      revenue.Currency,
+		FeeOf:         link,
+	}, models.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := recordBalanceChanged(ctx, tx, debit, *payer); err != nil {
+		return nil, err
+	}
+
+	if err := recordBalanceChanged(ctx, tx, credit, *revenue); err != nil {
+		return nil, err
+	}
+
+	return &models.Fee{
+		TransactionID:   debit.ID,
+		Amount:          amount,
+		Currency:        payer.Currency,
+		Exponent:        models.CurrencyExponent(payer.Currency),
+		RevenueWalletID: revenue.ID,
+	}, nil
+}
+
+// findFee возвращает комиссию, взятую за транзакцию, или nil, если ее не было
+func findFee(ctx context.Context, q queryRower, transactionID uuid.UUID) (*models.Fee, error) {
+	fee := models.Fee{}
+
+	query := fmt.Sprintf(`SELECT f.id, f.amount, f.currency, i.wallet_id
+		FROM %[1]s f JOIN %[1]s i ON i.fee_of = f.fee_of AND i.operation_type = $3
+		WHERE f.fee_of = $1 AND f.operation_type = $2`, tableTransaction)
+	row := q.QueryRowContext(ctx, query, transactionID, models.FEE, models.FEE_INCOME)
+
+	if err := row.Scan(&fee.TransactionID, &fee.Amount, &fee.Currency, &fee.RevenueWalletID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	fee.Exponent = models.CurrencyExponent(fee.Currency)
+
+	return &fee, nil
+}
+
+// saveBalances сохраняет все кошельки, заблокированные в транзакции
+func saveBalances(ctx context.Context, tx *sql.Tx, wallets map[uuid.UUID]*models.Wallet) error {
+	for _, wallet := range wallets {
+		if err := saveBalance(ctx, tx, *wallet); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,314 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"wallets/internal/herrors"
+	"wallets/internal/models"
+
+	"github.com/gofrs/uuid"
+)
+
+const holdColumns = "id, wallet_id, amount, captured, currency, status, expires_at, created_at, updated_at"
+
+func (r *PostgresRepos) CreateHold(ctx context.Context, walletID uuid.UUID, amount int64, ttl time.Duration) (models.Hold, error) {
+	const op = "storage.Postgres.CreateHold"
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return models.Hold{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer tx.Rollback()
+
+	wallet, err := lockWallet(ctx, tx, walletID)
+	if err != nil {
+		return models.Hold{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := ensureCanDebit(wallet); err != nil {
+		return models.Hold{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if wallet.Spendable() < amount {
+		return models.Hold{}, fmt.Errorf("%s: %w", op, herrors.ErrInsufficientFunds)
+	}
+
+	// Холд сверх лимитов списания не смог бы быть списан и держал бы средства до истечения
+	if err := r.checkDebitLimits(ctx, tx, wallet.ID, amount); err != nil {
+		return models.Hold{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	wallet.Held += amount
+
+	if err := saveBalance(ctx, tx, wallet); err != nil {
+		return models.Hold{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (wallet_id, amount, currency, expires_at)
+		VALUES ($1, $2, $3, now() + make_interval(secs => $4))
+		RETURNING %s`, tableHolds, holdColumns)
+	row := tx.QueryRowContext(ctx, query, walletID, amount, wallet.Currency, ttl.Seconds())
+
+	hold, err := scanHold(row)
+	if err != nil {
+		return models.Hold{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Hold{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return hold, nil
+}
+
+func (r *PostgresRepos) GetHold(ctx context.Context, holdID uuid.UUID) (models.Hold, error) {
+	const op = "storage.Postgres.GetHold"
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = $1", holdColumns, tableHolds)
+	row := r.db.QueryRowContext(ctx, query, holdID)
+
+	hold, err := scanHold(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = herrors.ErrHoldNotFound
+		}
+		return models.Hold{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return hold, nil
+}
+
+// CaptureHold списывает amount из холда и закрывает его, остаток резерва освобождается.
+// amount == 0 означает списание всей суммы холда.
+func (r *PostgresRepos) CaptureHold(ctx context.Context, holdID uuid.UUID, amount int64) (models.Hold, models.Transactions, error) {
+	const op = "storage.Postgres.CaptureHold"
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return models.Hold{}, models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer tx.Rollback()
+
+	wallet, hold, err := lockActiveHold(ctx, tx, holdID)
+	if err != nil {
+		return models.Hold{}, models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := ensureCanDebit(wallet); err != nil {
+		return models.Hold{}, models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if amount == 0 {
+		amount = hold.Amount
+	}
+
+	if amount > hold.Amount {
+		return models.Hold{}, models.Transactions{}, fmt.Errorf("%s: %w", op, herrors.ErrCaptureExceedsHold)
+	}
+
+	if err := r.checkDebitLimits(ctx, tx, wallet.ID, amount); err != nil {
+		return models.Hold{}, models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	wallet.Held -= hold.Amount
+	wallet.Balance -= amount
+
+	if err := saveBalance(ctx, tx, wallet); err != nil {
+		return models.Hold{}, models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	hold, err = closeHold(ctx, tx, holdID, models.HOLD_CAPTURED, amount)
+	if err != nil {
+		return models.Hold{}, models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	transaction, err := insertTransaction(ctx, tx, models.Transactions{
+		WalletID:      wallet.ID,
+		OperationType: models.HOLD_CAPTURE,
+		Amount:        amount,
+		Currency:      wallet.Currency,
+		HoldID:        uuid.NullUUID{UUID: holdID, Valid: true},
+	}, models.TxOptions{})
+	if err != nil {
+		return models.Hold{}, models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := recordBalanceChanged(ctx, tx, transaction, wallet); err != nil {
+		return models.Hold{}, models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Hold{}, models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return hold, transaction, nil
+}
+
+func (r *PostgresRepos) VoidHold(ctx context.Context, holdID uuid.UUID) (models.Hold, error) {
+	const op = "storage.Postgres.VoidHold"
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return models.Hold{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer tx.Rollback()
+
+	wallet, hold, err := lockActiveHold(ctx, tx, holdID)
+	if err != nil {
+		return models.Hold{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	wallet.Held -= hold.Amount
+
+	if err := saveBalance(ctx, tx, wallet); err != nil {
+		return models.Hold{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	hold, err = closeHold(ctx, tx, holdID, models.HOLD_VOIDED, 0)
+	if err != nil {
+		return models.Hold{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Hold{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return hold, nil
+}
+
+// ListExpiredHoldWallets возвращает id кошельков, у которых есть просроченные активные холды
+func (r *PostgresRepos) ListExpiredHoldWallets(ctx context.Context) ([]uuid.UUID, error) {
+	const op = "storage.Postgres.ListExpiredHoldWallets"
+
+	query := fmt.Sprintf("SELECT DISTINCT wallet_id FROM %s WHERE status = $1 AND expires_at <= now()", tableHolds)
+
+	rows, err := r.db.QueryContext(ctx, query, models.HOLD_ACTIVE)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer rows.Close()
+
+	var walletIDs []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		walletIDs = append(walletIDs, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return walletIDs, nil
+}
+
+// ExpireWalletHolds закрывает просроченные холды кошелька, освобождает их резерв и
+// возвращает число закрытых холдов
+func (r *PostgresRepos) ExpireWalletHolds(ctx context.Context, walletID uuid.UUID) (int, error) {
+	const op = "storage.Postgres.ExpireWalletHolds"
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer tx.Rollback()
+
+	wallet, err := lockWallet(ctx, tx, walletID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var expired int
+	var released int64
+
+	query := fmt.Sprintf(`WITH expired AS (
+			UPDATE %s SET status = $1, updated_at = now()
+			WHERE wallet_id = $2 AND status = $3 AND expires_at <= now()
+			RETURNING amount
+		)
+		SELECT COUNT(*), COALESCE(SUM(amount), 0) FROM expired`, tableHolds)
+
+	err = tx.QueryRowContext(ctx, query, models.HOLD_EXPIRED, walletID, models.HOLD_ACTIVE).Scan(&expired, &released)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if expired == 0 {
+		return 0, nil
+	}
+
+	wallet.Held -= released
+
+	if err := saveBalance(ctx, tx, wallet); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return expired, nil
+}
+
+// lockActiveHold блокирует кошелек холда, затем сам холд, и проверяет, что холд еще активен.
+// Кошелек всегда блокируется раньше холда, как и в остальных операциях над балансом.
+func lockActiveHold(ctx context.Context, tx *sql.Tx, holdID uuid.UUID) (models.Wallet, models.Hold, error) {
+	var walletID uuid.UUID
+
+	query := fmt.Sprintf("SELECT wallet_id FROM %s WHERE id = $1", tableHolds)
+	if err := tx.QueryRowContext(ctx, query, holdID).Scan(&walletID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = herrors.ErrHoldNotFound
+		}
+		return models.Wallet{}, models.Hold{}, err
+	}
+
+	wallet, err := lockWallet(ctx, tx, walletID)
+	if err != nil {
+		return models.Wallet{}, models.Hold{}, err
+	}
+
+	var expired bool
+
+	query = fmt.Sprintf("SELECT %s, expires_at <= now() FROM %s WHERE id = $1 FOR UPDATE", holdColumns, tableHolds)
+	hold, err := scanHold(tx.QueryRowContext(ctx, query, holdID), &expired)
+	if err != nil {
+		return models.Wallet{}, models.Hold{}, err
+	}
+
+	if hold.Status != models.HOLD_ACTIVE || expired {
+		return models.Wallet{}, models.Hold{}, herrors.ErrHoldNotActive
+	}
+
+	return wallet, hold, nil
+}
+
+func closeHold(ctx context.Context, tx *sql.Tx, holdID uuid.UUID, status models.HoldStatus, captured int64) (models.Hold, error) {
+	query := fmt.Sprintf(`UPDATE %s SET status = $1, captured = $2, updated_at = now()
+		WHERE id = $3 RETURNING %s`, tableHolds, holdColumns)
+
+	return scanHold(tx.QueryRowContext(ctx, query, status, captured, holdID))
+}
+
+func scanHold(row scanner, extra ...any) (models.Hold, error) {
+	hold := models.Hold{}
+
+	dest := []any{&hold.ID, &hold.WalletID, &hold.Amount, &hold.Captured, &hold.Currency,
+		&hold.Status, &hold.ExpiresAt, &hold.CreatedAt, &hold.UpdatedAt}
+
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return models.Hold{}, err
+	}
+
+	return hold, nil
+}
@@ -0,0 +1,367 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"wallets/internal/herrors"
+	"wallets/internal/interest"
+	"wallets/internal/models"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	tableInterestAccruals = "interest_accruals"
+	tableInterestPayouts  = "interest_payouts"
+
+	interestAccrualColumns = "id, wallet_id, accrual_date, balance, annual_rate_bps, amount_micros, payout_id, created_at"
+	interestPayoutColumns  = "id, wallet_id, period, amount, currency, status, transaction_id, COALESCE(error, ''), created_at"
+
+	interestPayoutPeriodKey = "interest_payouts_wallet_id_period_key"
+)
+
+// ListInterestBalances возвращает остатки на конец суток day кошельков типов walletTypes, за которые
+// еще нет начисления. Остаток считается, как в GetBalanceAsOf, от ближайшего снимка. Закрытые кошельки
+// и кошельки, которым проценты за месяц day уже выплачены, пропускаются.
+func (r *PostgresRepos) ListInterestBalances(ctx context.Context, day time.Time, walletTypes []string) ([]models.InterestBalance, error) {
+	const op = "storage.Postgres.ListInterestBalances"
+
+	query := fmt.Sprintf(`SELECT w.id, w.type,
+			COALESCE(s.balance, 0) + COALESCE((
+				SELECT SUM(CASE WHEN t.operation_type = ANY($3) THEN t.amount ELSE -t.amount END)
+				FROM %[2]s t
+				WHERE t.wallet_id = w.id AND t.created_at < $2 AND (s.taken_at IS NULL OR t.created_at > s.taken_at)
+			), 0)
+		FROM %[1]s w
+		LEFT JOIN LATERAL (
+			SELECT taken_at, balance FROM %[3]s
+			WHERE wallet_id = w.id AND taken_at < $2
+			ORDER BY taken_at DESC LIMIT 1
+		) s ON true
+		WHERE w.type = ANY($4) AND w.status <> '%[6]s'
+			AND NOT EXISTS (SELECT 1 FROM %[4]s a WHERE a.wallet_id = w.id AND a.accrual_date = $1::date)
+			AND NOT EXISTS (SELECT 1 FROM %[5]s p WHERE p.wallet_id = w.id AND p.period = date_trunc('month', $1::date))`,
+		tableWallets, tableTransaction, tableBalanceSnapshots, tableInterestAccruals, tableInterestPayouts, models.WALLET_CLOSED)
+
+	day = day.UTC()
+
+	rows, err := r.db.QueryContext(ctx, query, day, day.AddDate(0, 0, 1), creditOperations, walletTypes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer rows.Close()
+
+	balances := []models.InterestBalance{}
+	for rows.Next() {
+		var balance models.InterestBalance
+
+		if err := rows.Scan(&balance.WalletID, &balance.Type, &balance.Balance); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		balances = append(balances, balance)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return balances, nil
+}
+
+// RecordInterestAccruals сохраняет начисления. Начисление за день, которое уже есть, не перезаписывается,
+// поэтому повторный запуск за те же сутки ничего не меняет. Возвращает число новых начислений.
+func (r *PostgresRepos) RecordInterestAccruals(ctx context.Context, accruals []models.InterestAccrual) (int64, error) {
+	const op = "storage.Postgres.RecordInterestAccruals"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer tx.Rollback()
+
+	// Начисление за месяц, который уже выплачен, не сохраняется: его некуда было бы выплатить
+	query := fmt.Sprintf(`INSERT INTO %[1]s (wallet_id, accrual_date, balance, annual_rate_bps, amount_micros)
+		SELECT $1, $2::date, $3, $4, $5
+		WHERE NOT EXISTS (SELECT 1 FROM %[2]s p WHERE p.wallet_id = $1 AND p.period = date_trunc('month', $2::date))
+		ON CONFLICT (wallet_id, accrual_date) DO NOTHING`, tableInterestAccruals, tableInterestPayouts)
+
+	var recorded int64
+	for _, accrual := range accruals {
+		res, err := tx.ExecContext(ctx, query, accrual.WalletID, accrual.AccrualDate.UTC(), accrual.Balance,
+			accrual.AnnualRateBps, accrual.AmountMicros)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+
+		n, err := res.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+
+		recorded += n
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return recorded, nil
+}
+
+// ListInterestDue возвращает кошельки и месяцы с невыплаченными начислениями до before, старые месяцы первыми.
+// Месяцы, за которые выплата уже записана, пропускаются, чтобы они не занимали пачку на каждом запуске.
+func (r *PostgresRepos) ListInterestDue(ctx context.Context, before time.Time, limit int) ([]models.InterestDue, error) {
+	const op = "storage.Postgres.ListInterestDue"
+
+	query := fmt.Sprintf(`SELECT a.wallet_id, date_trunc('month', a.accrual_date)::date AS period
+		FROM %[1]s a
+		WHERE a.payout_id IS NULL AND a.accrual_date < $1::date
+			AND NOT EXISTS (SELECT 1 FROM %[2]s p
+				WHERE p.wallet_id = a.wallet_id AND p.period = date_trunc('month', a.accrual_date))
+		GROUP BY a.wallet_id, period
+		ORDER BY period, a.wallet_id
+		LIMIT $2`, tableInterestAccruals, tableInterestPayouts)
+
+	rows, err := r.db.QueryContext(ctx, query, before.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer rows.Close()
+
+	due := []models.InterestDue{}
+	for rows.Next() {
+		var d models.InterestDue
+
+		if err := rows.Scan(&d.WalletID, &d.Period); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		due = append(due, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return due, nil
+}
+
+// PayInterest выплачивает невыплаченные начисления кошелька за месяц period одной операцией INTEREST
+// и связывает их с выплатой. Если выплачивать нечего или проценты за месяц уже выплачены,
+// возвращает herrors.ErrNoInterestDue. Если кошелек закрыт или зачисление превысит max_balance, выплата
+// записывается со статусом REJECTED без транзакции, и начисления за месяц больше не выплачиваются.
+func (r *PostgresRepos) PayInterest(ctx context.Context, walletID uuid.UUID, period time.Time) (models.InterestPayout, error) {
+	const op = "storage.Postgres.PayInterest"
+
+	payoutID, err := uuid.NewV4()
+	if err != nil {
+		return models.InterestPayout{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return models.InterestPayout{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer tx.Rollback()
+
+	// Блокировка кошелька не дает двум экземплярам сервиса выплатить одни и те же начисления
+	wallet, err := lockWallet(ctx, tx, walletID)
+	if err != nil {
+		return models.InterestPayout{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	period = interest.Period(period.UTC())
+	next := period.AddDate(0, 1, 0)
+
+	var micros, accruals int64
+
+	query := fmt.Sprintf(`SELECT COALESCE(SUM(amount_micros), 0), COUNT(*) FROM %s
+		WHERE wallet_id = $1 AND payout_id IS NULL AND accrual_date >= $2::date AND accrual_date < $3::date`,
+		tableInterestAccruals)
+	if err := tx.QueryRowContext(ctx, query, walletID, period, next).Scan(&micros, &accruals); err != nil {
+		return models.InterestPayout{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if accruals == 0 {
+		return models.InterestPayout{}, fmt.Errorf("%s: %w", op, herrors.ErrNoInterestDue)
+	}
+
+	payout := models.InterestPayout{
+		ID:       payoutID,
+		WalletID: walletID,
+		Period:   period,
+		Amount:   interest.Payout(micros),
+		Currency: wallet.Currency,
+		Exponent: models.CurrencyExponent(wallet.Currency),
+		Status:   models.PAYOUT_PAID,
+	}
+
+	if payout.Amount > 0 {
+		err := ensureCanCredit(wallet)
+		if err == nil {
+			wallet.Balance += payout.Amount
+			err = r.checkBalanceLimit(ctx, tx, wallet)
+		}
+
+		switch {
+		case rejectsPayout(err):
+			// Начисления закрываются отклоненной выплатой, иначе задача повторяла бы ее на каждом запуске
+			payout.Status = models.PAYOUT_REJECTED
+			payout.Error = err.Error()
+
+		case err != nil:
+			return models.InterestPayout{}, fmt.Errorf("%s: %w", op, err)
+
+		default:
+			transaction, err := insertTransaction(ctx, tx, models.Transactions{
+				WalletID:      walletID,
+				OperationType: models.INTEREST,
+				Amount:        payout.Amount,
+				Currency:      wallet.Currency,
+			}, models.TxOptions{ExternalReference: payout.ExternalReference()})
+			if err != nil {
+				return models.InterestPayout{}, fmt.Errorf("%s: %w", op, err)
+			}
+
+			if err := recordBalanceChanged(ctx, tx, transaction, wallet); err != nil {
+				return models.InterestPayout{}, fmt.Errorf("%s: %w", op, err)
+			}
+
+			if err := saveBalance(ctx, tx, wallet); err != nil {
+				return models.InterestPayout{}, fmt.Errorf("%s: %w", op, err)
+			}
+
+			payout.TransactionID = uuid.NullUUID{UUID: transaction.ID, Valid: true}
+		}
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %s (id, wallet_id, period, amount, currency, status, transaction_id, error)
+		VALUES ($1, $2, $3::date, $4, $5, $6, $7, NULLIF($8, ''))
+		RETURNING created_at`, tableInterestPayouts)
+	err = tx.QueryRowContext(ctx, query, payout.ID, walletID, period, payout.Amount, payout.Currency, payout.Status,
+		payout.TransactionID, payout.Error).Scan(&payout.CreatedAt)
+	if err != nil {
+		// Проценты за месяц выплачиваются один раз; ListInterestDue такой месяц больше не возвращает
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation && pgErr.ConstraintName == interestPayoutPeriodKey {
+			err = herrors.ErrNoInterestDue
+		}
+		return models.InterestPayout{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query = fmt.Sprintf(`UPDATE %s SET payout_id = $1
+		WHERE wallet_id = $2 AND payout_id IS NULL AND accrual_date >= $3::date AND accrual_date < $4::date`,
+		tableInterestAccruals)
+	if _, err := tx.ExecContext(ctx, query, payout.ID, walletID, period, next); err != nil {
+		return models.InterestPayout{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.InterestPayout{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return payout, nil
+}
+
+// rejectsPayout сообщает, что выплату нельзя зачислить и ее нужно записать отклоненной, а не повторять
+func rejectsPayout(err error) bool {
+	return errors.Is(err, herrors.ErrWalletClosed) || errors.Is(err, herrors.ErrLimitExceeded)
+}
+
+// ListInterestPayouts возвращает выплаты процентов кошельку, последние первыми
+func (r *PostgresRepos) ListInterestPayouts(ctx context.Context, walletID uuid.UUID, limit int) ([]models.InterestPayout, error) {
+	const op = "storage.Postgres.ListInterestPayouts"
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE wallet_id = $1 ORDER BY period DESC, created_at DESC LIMIT $2",
+		interestPayoutColumns, tableInterestPayouts)
+
+	rows, err := r.db.QueryContext(ctx, query, walletID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer rows.Close()
+
+	payouts := []models.InterestPayout{}
+	for rows.Next() {
+		var payout models.InterestPayout
+
+		err := rows.Scan(&payout.ID, &payout.WalletID, &payout.Period, &payout.Amount, &payout.Currency,
+			&payout.Status, &payout.TransactionID, &payout.Error, &payout.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		payout.Exponent = models.CurrencyExponent(payout.Currency)
+		payouts = append(payouts, payout)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return payouts, nil
+}
+
+// ListInterestAccruals возвращает начисления кошелька по дням в порядке дат
+func (r *PostgresRepos) ListInterestAccruals(ctx context.Context, filter models.InterestAccrualFilter) ([]models.InterestAccrual, error) {
+	const op = "storage.Postgres.ListInterestAccruals"
+
+	conds := []string{"wallet_id = $1"}
+	args := []any{filter.WalletID}
+
+	addCond := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.PayoutID.Valid {
+		addCond("payout_id = $%d", filter.PayoutID.UUID)
+	}
+	if filter.From != nil {
+		addCond("accrual_date >= $%d::date", filter.From.UTC())
+	}
+	if filter.To != nil {
+		addCond("accrual_date < $%d::date", filter.To.UTC())
+	}
+
+	args = append(args, filter.Limit)
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s ORDER BY accrual_date LIMIT $%d",
+		interestAccrualColumns, tableInterestAccruals, strings.Join(conds, " AND "), len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer rows.Close()
+
+	accruals := []models.InterestAccrual{}
+	for rows.Next() {
+		var accrual models.InterestAccrual
+
+		err := rows.Scan(&accrual.ID, &accrual.WalletID, &accrual.AccrualDate, &accrual.Balance, &accrual.AnnualRateBps,
+			&accrual.AmountMicros, &accrual.PayoutID, &accrual.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		accruals = append(accruals, accrual)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return accruals, nil
+}
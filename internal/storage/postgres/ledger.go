@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"wallets/internal/herrors"
+	"wallets/internal/models"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	tableLedgerAccounts = "ledger_accounts"
+	tableLedgerEntries  = "ledger_entries"
+
+	// accountBalances - остатки всех счетов главной книги: зачисления минус списания
+	accountBalances = `SELECT account_id, SUM(amount) AS balance FROM (
+			SELECT credit_account_id AS account_id, amount FROM ledger_entries
+			UNION ALL
+			SELECT debit_account_id, -amount FROM ledger_entries
+		) AS movements GROUP BY account_id`
+)
+
+// createWalletAccount открывает счет главной книги для нового кошелька. Счет имеет тот же id, что и кошелек.
+func createWalletAccount(ctx context.Context, tx *sql.Tx, walletID uuid.UUID, currency string) error {
+	query := fmt.Sprintf("INSERT INTO %s (id, wallet_id, currency) VALUES ($1, $1, $2)", tableLedgerAccounts)
+	_, err := tx.ExecContext(ctx, query, walletID, currency)
+
+	return err
+}
+
+// postLedgerEntry проводит транзакцию кошелька по главной книге между счетом кошелька и системным счетом
+func postLedgerEntry(ctx context.Context, tx *sql.Tx, t models.Transactions) error {
+	posting, ok := models.PostingFor(t.OperationType)
+	if !ok {
+		return herrors.ErrNoLedgerPosting
+	}
+
+	systemID, err := systemAccount(ctx, tx, posting.Counterparty, t.Currency)
+	if err != nil {
+		return err
+	}
+
+	debit, credit := systemID, t.WalletID
+	if posting.WalletDebited {
+		debit, credit = t.WalletID, systemID
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (transaction_id, debit_account_id, credit_account_id, amount, currency, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`, tableLedgerEntries)
+	_, err = tx.ExecContext(ctx, query, t.ID, debit, credit, t.Amount, t.Currency, t.Created_at)
+
+	return err
+}
+
+// systemAccount возвращает системный счет в валюте, открывая его при первом обращении
+func systemAccount(ctx context.Context, tx *sql.Tx, code, currency string) (uuid.UUID, error) {
+	var id uuid.UUID
+
+	query := fmt.Sprintf("SELECT id FROM %s WHERE code = $1 AND currency = $2", tableLedgerAccounts)
+	err := tx.QueryRowContext(ctx, query, code, currency).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return uuid.UUID{}, err
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %s (code, currency) VALUES ($1, $2)
+		ON CONFLICT (code, currency) DO UPDATE SET code = EXCLUDED.code
+		RETURNING id`, tableLedgerAccounts)
+	if err := tx.QueryRowContext(ctx, query, code, currency).Scan(&id); err != nil {
+		return uuid.UUID{}, err
+	}
+
+	return id, nil
+}
+
+// CheckLedger сверяет wallets.balance с суммой записей главной книги и возвращает остатки системных счетов
+func (r *PostgresRepos) CheckLedger(ctx context.Context) (models.LedgerReport, error) {
+	const op = "storage.Postgres.CheckLedger"
+
+	report := models.LedgerReport{
+		SystemAccounts: []models.LedgerAccountBalance{},
+		Mismatches:     []models.LedgerMismatch{},
+	}
+
+	query := fmt.Sprintf(`SELECT a.id, a.code, a.currency, COALESCE(b.balance, 0)
+		FROM %s a LEFT JOIN (%s) AS b ON b.account_id = a.id
+		WHERE a.code IS NOT NULL ORDER BY a.currency, a.code`, tableLedgerAccounts, accountBalances)
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return models.LedgerReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var account models.LedgerAccountBalance
+		if err := rows.Scan(&account.AccountID, &account.Code, &account.Currency, &account.Balance); err != nil {
+			return models.LedgerReport{}, fmt.Errorf("%s: %w", op, err)
+		}
+		report.SystemAccounts = append(report.SystemAccounts, account)
+	}
+
+	if err := rows.Err(); err != nil {
+		return models.LedgerReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query = fmt.Sprintf(`SELECT w.id, w.balance, COALESCE(b.balance, 0)
+		FROM %s w LEFT JOIN (%s) AS b ON b.account_id = w.id
+		WHERE w.balance <> COALESCE(b.balance, 0) ORDER BY w.id`, tableWallets, accountBalances)
+
+	rows, err = r.db.QueryContext(ctx, query)
+	if err != nil {
+		return models.LedgerReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var mismatch models.LedgerMismatch
+		if err := rows.Scan(&mismatch.WalletID, &mismatch.Balance, &mismatch.LedgerBalance); err != nil {
+			return models.LedgerReport{}, fmt.Errorf("%s: %w", op, err)
+		}
+		report.Mismatches = append(report.Mismatches, mismatch)
+	}
+
+	if err := rows.Err(); err != nil {
+		return models.LedgerReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,178 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"wallets/internal/herrors"
+	"wallets/internal/models"
+
+	"github.com/gofrs/uuid"
+)
+
+const tableWalletLimits = "wallet_limits"
+
+// debitOperations - операции, которые учитываются в дневном и месячном лимите списаний
+var debitOperations = []string{string(models.WITHDRAW), string(models.TRANSFER_OUT), string(models.HOLD_CAPTURE)}
+
+func (r *PostgresRepos) GetLimits(ctx context.Context, walletID uuid.UUID) (models.WalletLimits, error) {
+	const op = "storage.Postgres.GetLimits"
+
+	var exists bool
+
+	query := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE id = $1)", tableWallets)
+	if err := r.db.QueryRowContext(ctx, query, walletID).Scan(&exists); err != nil {
+		return models.WalletLimits{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !exists {
+		return models.WalletLimits{}, fmt.Errorf("%s: %w", op, herrors.ErrNXUUID)
+	}
+
+	overrides, err := loadLimitOverrides(ctx, r.db, walletID)
+	if err != nil {
+		return models.WalletLimits{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return models.WalletLimits{
+		WalletID:  walletID,
+		Overrides: overrides,
+		Effective: r.effectiveLimits(walletID, overrides),
+	}, nil
+}
+
+// SetLimits полностью заменяет переопределения лимитов кошелька
+func (r *PostgresRepos) SetLimits(ctx context.Context, walletID uuid.UUID, overrides models.LimitOverrides) (models.WalletLimits, error) {
+	const op = "storage.Postgres.SetLimits"
+
+	if r.fees.IsRevenueWallet(walletID) && overrides.MaxBalance != nil && *overrides.MaxBalance > 0 {
+		return models.WalletLimits{}, fmt.Errorf("%s: %w", op, herrors.ErrRevenueWalletMaxBalance)
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (wallet_id, max_withdrawal, daily_withdrawal, monthly_withdrawal, max_balance)
+		SELECT id, $2, $3, $4, $5 FROM %s WHERE id = $1
+		ON CONFLICT (wallet_id) DO UPDATE SET
+			max_withdrawal = EXCLUDED.max_withdrawal,
+			daily_withdrawal = EXCLUDED.daily_withdrawal,
+			monthly_withdrawal = EXCLUDED.monthly_withdrawal,
+			max_balance = EXCLUDED.max_balance,
+			updated_at = now()`, tableWalletLimits, tableWallets)
+
+	res, err := r.db.ExecContext(ctx, query, walletID, overrides.MaxWithdrawal, overrides.DailyWithdrawal,
+		overrides.MonthlyWithdrawal, overrides.MaxBalance)
+	if err != nil {
+		return models.WalletLimits{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return models.WalletLimits{}, fmt.Errorf("%s: %w", op, herrors.ErrNXUUID)
+	}
+
+	return models.WalletLimits{
+		WalletID:  walletID,
+		Overrides: overrides,
+		Effective: r.effectiveLimits(walletID, overrides),
+	}, nil
+}
+
+// effectiveLimits применяет переопределения кошелька к лимитам по умолчанию.
+// Кошельки доходов не ограничиваются max_balance: иначе заполненный кошелек доходов
+// отклонял бы операции клиентов с комиссией.
+func (r *PostgresRepos) effectiveLimits(walletID uuid.UUID, overrides models.LimitOverrides) models.Limits {
+	limits := overrides.Apply(r.limits)
+
+	if r.fees.IsRevenueWallet(walletID) {
+		limits.MaxBalance = 0
+	}
+
+	return limits
+}
+
+// checkDebitLimits проверяет лимиты списания amount с кошелька, заблокированного lockWallet
+func (r *PostgresRepos) checkDebitLimits(ctx context.Context, tx *sql.Tx, walletID uuid.UUID, amount int64) error {
+	overrides, err := loadLimitOverrides(ctx, tx, walletID)
+	if err != nil {
+		return err
+	}
+
+	limits := overrides.Apply(r.limits)
+
+	if limits.MaxWithdrawal > 0 && amount > limits.MaxWithdrawal {
+		return &herrors.LimitError{Limit: models.LimitMaxWithdrawal, Value: limits.MaxWithdrawal}
+	}
+
+	if limits.DailyWithdrawal == 0 && limits.MonthlyWithdrawal == 0 {
+		return nil
+	}
+
+	var daily, monthly int64
+
+	query := fmt.Sprintf(`SELECT
+			COALESCE(SUM(amount) FILTER (WHERE created_at >= date_trunc('day', now())), 0),
+			COALESCE(SUM(amount), 0)
+		FROM %s
+		WHERE wallet_id = $1 AND operation_type = ANY($2) AND created_at >= date_trunc('month', now())`, tableTransaction)
+	if err := tx.QueryRowContext(ctx, query, walletID, debitOperations).Scan(&daily, &monthly); err != nil {
+		return err
+	}
+
+	if limits.DailyWithdrawal > 0 && daily+amount > limits.DailyWithdrawal {
+		return &herrors.LimitError{Limit: models.LimitDailyWithdrawal, Value: limits.DailyWithdrawal}
+	}
+
+	if limits.MonthlyWithdrawal > 0 && monthly+amount > limits.MonthlyWithdrawal {
+		return &herrors.LimitError{Limit: models.LimitMonthlyWithdrawal, Value: limits.MonthlyWithdrawal}
+	}
+
+	return nil
+}
+
+// checkBalanceLimit проверяет, что новый баланс кошелька не превышает max_balance
+func (r *PostgresRepos) checkBalanceLimit(ctx context.Context, tx *sql.Tx, wallet models.Wallet) error {
+	overrides, err := loadLimitOverrides(ctx, tx, wallet.ID)
+	if err != nil {
+		return err
+	}
+
+	limits := r.effectiveLimits(wallet.ID, overrides)
+
+	if limits.MaxBalance > 0 && wallet.Balance > limits.MaxBalance {
+		return &herrors.LimitError{Limit: models.LimitMaxBalance, Value: limits.MaxBalance}
+	}
+
+	return nil
+}
+
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func loadLimitOverrides(ctx context.Context, q queryRower, walletID uuid.UUID) (models.LimitOverrides, error) {
+	var maxWithdrawal, daily, monthly, maxBalance sql.NullInt64
+
+	query := fmt.Sprintf(`SELECT max_withdrawal, daily_withdrawal, monthly_withdrawal, max_balance
+		FROM %s WHERE wallet_id = $1`, tableWalletLimits)
+	err := q.QueryRowContext(ctx, query, walletID).Scan(&maxWithdrawal, &daily, &monthly, &maxBalance)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.LimitOverrides{}, nil
+	}
+	if err != nil {
+		return models.LimitOverrides{}, err
+	}
+
+	return models.LimitOverrides{
+		MaxWithdrawal:     nullInt64(maxWithdrawal),
+		DailyWithdrawal:   nullInt64(daily),
+		MonthlyWithdrawal: nullInt64(monthly),
+		MaxBalance:        nullInt64(maxBalance),
+	}, nil
+}
+
+func nullInt64(v sql.NullInt64) *int64 {
+	if !v.Valid {
+		return nil
+	}
+
+	return &v.Int64
+}
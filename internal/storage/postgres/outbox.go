@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"wallets/internal/models"
+
+	"github.com/gofrs/uuid"
+)
+
+const tableOutbox = "outbox"
+
+// recordBalanceChanged пишет событие об изменении баланса в outbox той же транзакцией,
+// что и сама операция, поэтому событие появляется только после ее коммита
+func recordBalanceChanged(ctx context.Context, tx *sql.Tx, t models.Transactions, wallet models.Wallet) error {
+	payload, err := json.Marshal(models.BalanceChanged{
+		TransactionID:     t.ID,
+		WalletID:          t.WalletID,
+		OperationType:     t.OperationType,
+		Amount:            t.Amount,
+		Currency:          t.Currency,
+		Exponent:          models.CurrencyExponent(t.Currency),
+		Balance:           wallet.Balance,
+		Available:         wallet.Available(),
+		ExternalReference: t.ExternalReference,
+		CreatedAt:         t.Created_at,
+	})
+	if err != nil {
+		return err
+	}
+
+	return insertOutboxEvent(ctx, tx, models.EventBalanceChanged, t.WalletID, payload)
+}
+
+func recordStatusChanged(ctx context.Context, tx *sql.Tx, change models.WalletStatusChange) error {
+	payload, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+
+	return insertOutboxEvent(ctx, tx, models.EventStatusChanged, change.WalletID, payload)
+}
+
+func insertOutboxEvent(ctx context.Context, tx *sql.Tx, eventType string, walletID uuid.UUID, payload []byte) error {
+	query := fmt.Sprintf("INSERT INTO %s (event_type, wallet_id, payload) VALUES ($1, $2, $3)", tableOutbox)
+	if _, err := tx.ExecContext(ctx, query, eventType, walletID, payload); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// LockOutbox берет advisory lock публикации outbox на выделенном соединении. Пока он взят, другие
+// экземпляры сервиса пропускают публикацию: события уходят в приемники по порядку и по одному разу.
+// Если lock занят, возвращает false. Lock снимается возвращенной функцией или обрывом соединения.
+func (r *PostgresRepos) LockOutbox(ctx context.Context) (func(), bool, error) {
+	const op = "storage.Postgres.LockOutbox"
+
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", tableOutbox).Scan(&locked); err != nil {
+		conn.Close()
+		return nil, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !locked {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	unlock := func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(hashtext($1))", tableOutbox); err != nil {
+			// Соединение с незакрытым lock нельзя возвращать в пул: закрываем его, lock снимет база
+			conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+		conn.Close()
+	}
+
+	return unlock, true, nil
+}
+
+// PendingEvents возвращает неопубликованные события в порядке записи
+func (r *PostgresRepos) PendingEvents(ctx context.Context, limit int) ([]models.OutboxEvent, error) {
+	const op = "storage.Postgres.PendingEvents"
+
+	query := fmt.Sprintf(`SELECT id, event_type, wallet_id, payload, created_at FROM %s
+		WHERE published_at IS NULL ORDER BY id LIMIT $1`, tableOutbox)
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer rows.Close()
+
+	events := make([]models.OutboxEvent, 0, limit)
+	for rows.Next() {
+		var (
+			event   models.OutboxEvent
+			payload []byte
+		)
+
+		if err := rows.Scan(&event.ID, &event.Type, &event.WalletID, &payload, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		event.Payload = payload
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return events, nil
+}
+
+func (r *PostgresRepos) MarkEventsPublished(ctx context.Context, ids []int64) error {
+	const op = "storage.Postgres.MarkEventsPublished"
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET published_at = now() WHERE id = ANY($1)", tableOutbox)
+	if _, err := r.db.ExecContext(ctx, query, ids); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
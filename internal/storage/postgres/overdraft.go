@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"wallets/internal/herrors"
+	"wallets/internal/models"
+
+	"github.com/gofrs/uuid"
+)
+
+// SetOverdraftLimit меняет кредитную линию кошелька. Уменьшить ее ниже уже
+// использованного кредита нельзя.
+func (r *PostgresRepos) SetOverdraftLimit(ctx context.Context, walletID uuid.UUID, limit int64) (models.Wallet, error) {
+	const op = "storage.Postgres.SetOverdraftLimit"
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return models.Wallet{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer tx.Rollback()
+
+	wallet, err := lockWallet(ctx, tx, walletID)
+	if err != nil {
+		return models.Wallet{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if wallet.Status == models.WALLET_CLOSED {
+		return models.Wallet{}, fmt.Errorf("%s: %w", op, herrors.ErrWalletClosed)
+	}
+
+	if wallet.Available()+limit < 0 {
+		return models.Wallet{}, fmt.Errorf("%s: %w", op, herrors.ErrOverdraftInUse)
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET overdraft_limit = $1 WHERE id = $2", tableWallets)
+	if _, err := tx.ExecContext(ctx, query, limit, walletID); err != nil {
+		return models.Wallet{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Wallet{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	wallet.OverdraftLimit = limit
+
+	return wallet, nil
+}
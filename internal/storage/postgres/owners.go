@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"wallets/internal/herrors"
+	"wallets/internal/models"
+)
+
+// ensureNoOwnerWallet проверяет, что у владельца еще нет кошелька в валюте. Advisory lock по владельцу
+// держится до конца транзакции, поэтому параллельные создания кошельков одного владельца выполняются по очереди.
+func ensureNoOwnerWallet(ctx context.Context, tx *sql.Tx, ownerID, currency string) error {
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext($1), hashtext($2))", tableWallets, ownerID); err != nil {
+		return err
+	}
+
+	var exists bool
+
+	query := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE owner_id = $1 AND currency = $2)", tableWallets)
+	if err := tx.QueryRowContext(ctx, query, ownerID, currency).Scan(&exists); err != nil {
+		return err
+	}
+
+	if exists {
+		return herrors.ErrOwnerWalletExists
+	}
+
+	return nil
+}
+
+func (r *PostgresRepos) ListOwnerWallets(ctx context.Context, ownerID string) ([]models.Wallet, error) {
+	const op = "storage.Postgres.ListOwnerWallets"
+
+	query := fmt.Sprintf("SELECT %s, id FROM %s WHERE owner_id = $1 ORDER BY currency, id", walletColumns, tableWallets)
+
+	rows, err := r.db.QueryContext(ctx, query, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer rows.Close()
+
+	wallets := []models.Wallet{}
+	for rows.Next() {
+		var wallet models.Wallet
+		if err := scanWallet(rows, &wallet, &wallet.ID); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		wallets = append(wallets, wallet)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return wallets, nil
+}
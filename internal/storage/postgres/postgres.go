@@ -3,80 +3,168 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 	"wallets/internal/config"
+	"wallets/internal/fees"
 	"wallets/internal/herrors"
 	"wallets/internal/models"
 
+	"github.com/XSAM/otelsql"
 	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
 const (
 	tableWallets     = "wallets"
 	tableTransaction = "transactions"
+	tableHolds       = "holds"
+
+	walletColumns      = "balance, held, currency, status, overdraft_limit, COALESCE(owner_id, ''), type"
+	transactionColumns = "id, wallet_id, operation_type, amount, currency, transfer_id, hold_id, reversal_of, created_at, " +
+		"COALESCE(description, ''), metadata, COALESCE(external_reference, ''), fee_of"
+
+	pgUniqueViolation      = "23505"
+	pgSerializationFailure = "40001"
+	idempotencyKeyIndex    = "transactions_wallet_idempotency_key_idx"
+	externalReferenceIndex = "transactions_wallet_external_reference_idx"
 )
 
 type PostgresRepos struct {
 	db *sqlx.DB
+	// limits - лимиты кошельков по умолчанию
+	limits models.Limits
+	// oneWalletPerCurrency - у владельца не больше одного кошелька в каждой валюте
+	oneWalletPerCurrency bool
+	// fees - тарифы комиссий за списания и переводы
+	fees fees.Schedule
 }
 
-func New(storage config.Storage) (*PostgresRepos, error) {
+func New(storage config.Storage, limits config.Limits, owners config.Owners, fees fees.Schedule) (*PostgresRepos, error) {
 	const op = "storage.Postgres.New"
 
 	connStr := fmt.Sprintf("user=%s password=%s host=%s port=%s dbname=%s sslmode=%s",
 		storage.User, storage.Password, storage.Host, storage.Port, storage.Name, storage.SSLMode)
-	db, err := sqlx.Open("pgx", connStr)
+	// каждый SQL-запрос пишется отдельным спаном, служебные вызовы драйвера пропускаются
+	sqlDB, err := otelsql.Open("pgx", connStr,
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitConnPrepare:      true,
+			OmitRows:             true,
+		}),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
+	db := sqlx.NewDb(sqlDB, "pgx")
+
 	if err := db.Ping(); err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return &PostgresRepos{db: db}, nil
+	return &PostgresRepos{
+		db:                   db,
+		limits:               models.Limits(limits),
+		oneWalletPerCurrency: owners.OneWalletPerCurrency,
+		fees:                 fees,
+	}, nil
+
+}
+
+// Ping проверяет соединение с базой для проверки готовности сервиса
+func (r *PostgresRepos) Ping(ctx context.Context) error {
+	const op = "storage.Postgres.Ping"
+
+	if err := r.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
 
+	return nil
 }
 
-func (r *PostgresRepos) CreateWallet(ctx context.Context, balance int64) (uuid.UUID, error) {
+func (r *PostgresRepos) CreateWallet(ctx context.Context, balance int64, currency, ownerID, walletType string) (uuid.UUID, error) {
 	const op = "storage.Postgres.CreateWallet"
 	var walletID uuid.UUID
 
-	query := fmt.Sprintf("INSERT INTO %s (balance) VALUES ($1) RETURNING id", tableWallets)
-	row := r.db.QueryRowContext(ctx, query, balance)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer tx.Rollback()
+
+	if ownerID != "" && r.oneWalletPerCurrency {
+		if err := ensureNoOwnerWallet(ctx, tx, ownerID, currency); err != nil {
+			return uuid.UUID{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (balance, currency, owner_id, type) VALUES ($1, $2, NULLIF($3, ''), $4) RETURNING id", tableWallets)
+	row := tx.QueryRowContext(ctx, query, balance, currency, ownerID, walletType)
 
 	if err := row.Scan(&walletID); err != nil {
 		return uuid.UUID{}, fmt.Errorf("%s: %w", op, err)
 	}
 
+	if err := createWalletAccount(ctx, tx, walletID, currency); err != nil {
+		return uuid.UUID{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Начальный баланс проводим обычным пополнением, чтобы он был виден в истории и главной книге
+	if balance > 0 {
+		transaction, err := insertTransaction(ctx, tx, models.Transactions{
+			WalletID:      walletID,
+			OperationType: models.DEPOSIT,
+			Amount:        balance,
+			Currency:      currency,
+		}, models.TxOptions{})
+		if err != nil {
+			return uuid.UUID{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		wallet := models.Wallet{ID: walletID, Balance: balance, Currency: currency, OwnerID: ownerID, Type: walletType}
+		if err := recordBalanceChanged(ctx, tx, transaction, wallet); err != nil {
+			return uuid.UUID{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return uuid.UUID{}, fmt.Errorf("%s: %w", op, err)
+	}
+
 	return walletID, nil
 
 }
 
-func (r *PostgresRepos) GetBalance(ctx context.Context, walletID uuid.UUID) (int64, error) {
+func (r *PostgresRepos) GetBalance(ctx context.Context, walletID uuid.UUID) (models.Wallet, error) {
 	const op = "storage.Postgres.GetBalance"
-	var balance int64
+	wallet := models.Wallet{ID: walletID}
 
-	query := fmt.Sprintf("SELECT balance FROM %s WHERE id=$1", tableWallets)
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id=$1", walletColumns, tableWallets)
 	row := r.db.QueryRowContext(ctx, query, walletID)
 
-	if err := row.Scan(&balance); err != nil {
+	if err := scanWallet(row, &wallet); err != nil {
 
 		if errors.Is(err, sql.ErrNoRows) {
 			err = herrors.ErrNXUUID
 		}
 
-		return 0, fmt.Errorf("%s: %w", op, err)
+		return models.Wallet{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return balance, nil
+	return wallet, nil
 
 }
 
-func (r *PostgresRepos) UpdateBalance(ctx context.Context, walletID uuid.UUID, operationType models.OperationType, amount int64) (models.Transactions, error) {
+func (r *PostgresRepos) UpdateBalance(ctx context.Context, walletID uuid.UUID, operationType models.OperationType, amount int64, opts models.TxOptions) (models.Transactions, error) {
 	const op = "storage.Postgres.UpdateBalance"
 	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
 	if err != nil {
@@ -85,55 +173,471 @@ func (r *PostgresRepos) UpdateBalance(ctx context.Context, walletID uuid.UUID, o
 
 	defer tx.Rollback()
 
-	var balance int64
-	getQuery := fmt.Sprintf("SELECT balance FROM %s WHERE id = $1 FOR UPDATE", tableWallets)
-	row := tx.QueryRowContext(ctx, getQuery, walletID)
+	if opts.IdempotencyKey != "" {
+		original, err := replayIdempotent(ctx, tx, walletID, opts)
+		if err == nil {
+			return original, nil
+		}
 
-	if err := row.Scan(&balance); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			err = herrors.ErrNXUUID
+		if !errors.Is(err, sql.ErrNoRows) {
+			return models.Transactions{}, fmt.Errorf("%s: %w", op, err)
 		}
+	}
+
+	revenueIDs, err := r.feeRevenueWallets(ctx, tx, []models.BatchItem{{WalletID: walletID, OperationType: operationType, Amount: amount}})
+	if err != nil {
 		return models.Transactions{}, fmt.Errorf("%s: %w", op, err)
 	}
 
+	wallets, err := lockWallets(ctx, tx, []uuid.UUID{walletID}, revenueIDs)
+	if err != nil {
+		return models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	wallet := wallets[walletID]
+
+	if opts.Currency != "" && opts.Currency != wallet.Currency {
+		return models.Transactions{}, fmt.Errorf("%s: %w", op, herrors.ErrCurrencyMismatch)
+	}
+
+	if err := r.applyOperation(ctx, tx, wallet, operationType, amount); err != nil {
+		return models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	transaction, err := insertTransaction(ctx, tx, models.Transactions{
+		WalletID:      walletID,
+		OperationType: operationType,
+		Amount:        amount,
+		Currency:      wallet.Currency,
+	}, opts)
+	if err != nil {
+		// Параллельный запрос с тем же ключом успел записать транзакцию раньше:
+		// транзакция уже прервана, поэтому исходная запись перечитывается вне ее
+		if opts.IdempotencyKey != "" && isIdempotencyRace(err) {
+			tx.Rollback()
+
+			original, replayErr := replayIdempotent(ctx, r.db, walletID, opts)
+			if replayErr == nil {
+				return original, nil
+			}
+
+			if !errors.Is(replayErr, sql.ErrNoRows) {
+				err = replayErr
+			}
+		}
+		return models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := recordBalanceChanged(ctx, tx, transaction, *wallet); err != nil {
+		return models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if operationType == models.WITHDRAW {
+		transaction.Fee, err = r.chargeFee(ctx, tx, wallets, models.WITHDRAW, transaction)
+		if err != nil {
+			return models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := saveBalances(ctx, tx, wallets); err != nil {
+		return models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return transaction, nil
+}
+
+func (r *PostgresRepos) Transfer(ctx context.Context, fromID, toID uuid.UUID, amount int64) (models.Transfer, error) {
+	const op = "storage.Postgres.Transfer"
+
+	if fromID == toID {
+		return models.Transfer{}, fmt.Errorf("%s: %w", op, herrors.ErrSameWallet)
+	}
+
+	transferID, err := uuid.NewV4()
+	if err != nil {
+		return models.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return models.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer tx.Rollback()
+
+	revenueIDs, err := r.feeRevenueWallets(ctx, tx, []models.BatchItem{{WalletID: fromID, ToWalletID: toID, OperationType: models.TRANSFER, Amount: amount}})
+	if err != nil {
+		return models.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Блокируем кошельки всегда в одном порядке, чтобы встречные переводы не уходили в deadlock
+	wallets, err := lockWallets(ctx, tx, []uuid.UUID{fromID, toID}, revenueIDs)
+	if err != nil {
+		return models.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	from, to := wallets[fromID], wallets[toID]
+
+	if err := r.applyTransfer(ctx, tx, from, to, amount); err != nil {
+		return models.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	link := uuid.NullUUID{UUID: transferID, Valid: true}
+
+	debit, err := insertTransaction(ctx, tx, models.Transactions{
+		WalletID:      fromID,
+		OperationType: models.TRANSFER_OUT,
+		Amount:        amount,
+		Currency:      from.Currency,
+		TransferID:    link,
+	}, models.TxOptions{})
+	if err != nil {
+		return models.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	credit, err := insertTransaction(ctx, tx, models.Transactions{
+		WalletID:      toID,
+		OperationType: models.TRANSFER_IN,
+		Amount:        amount,
+		Currency:      to.Currency,
+		TransferID:    link,
+	}, models.TxOptions{})
+	if err != nil {
+		return models.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := recordBalanceChanged(ctx, tx, debit, *from); err != nil {
+		return models.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := recordBalanceChanged(ctx, tx, credit, *to); err != nil {
+		return models.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	fee, err := r.chargeFee(ctx, tx, wallets, models.TRANSFER, debit)
+	if err != nil {
+		return models.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := saveBalances(ctx, tx, wallets); err != nil {
+		return models.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return models.Transfer{
+		ID:       transferID,
+		Amount:   amount,
+		Currency: from.Currency,
+		Exponent: models.CurrencyExponent(from.Currency),
+		Debit:    debit,
+		Credit:   credit,
+		Fee:      fee,
+	}, nil
+}
+
+func (r *PostgresRepos) ListTransactions(ctx context.Context, filter models.TransactionFilter) ([]models.Transactions, error) {
+	const op = "storage.Postgres.ListTransactions"
+
+	query, args := transactionsQuery(filter)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer rows.Close()
+
+	transactions := make([]models.Transactions, 0, filter.Limit)
+	for rows.Next() {
+		transaction, err := scanTransaction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return transactions, nil
+}
+
+// transactionsQuery строит запрос страницы транзакций по фильтру. created_at хранится в UTC без зоны,
+// поэтому границы периода и курсор приводятся к UTC: иначе смещение из запроса теряется при передаче в базу.
+func transactionsQuery(filter models.TransactionFilter) (string, []any) {
+	conds := []string{"wallet_id = $1"}
+	args := []any{filter.WalletID}
+
+	addCond := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.OperationType != "" {
+		addCond("operation_type = $%d", filter.OperationType)
+	}
+	if filter.MinAmount != nil {
+		addCond("amount >= $%d", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		addCond("amount <= $%d", *filter.MaxAmount)
+	}
+	if filter.ExternalReference != "" {
+		addCond("external_reference = $%d", filter.ExternalReference)
+	}
+	if filter.From != nil {
+		addCond("created_at >= $%d", filter.From.UTC())
+	}
+	if filter.To != nil {
+		addCond("created_at < $%d", filter.To.UTC())
+	}
+	if filter.After != nil {
+		args = append(args, filter.After.CreatedAt.UTC(), filter.After.ID)
+		conds = append(conds, fmt.Sprintf("(created_at, id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+
+	args = append(args, filter.Limit)
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s ORDER BY created_at DESC, id DESC LIMIT $%d",
+		transactionColumns, tableTransaction, strings.Join(conds, " AND "), len(args))
+
+	return query, args
+}
+
+// ExpireIdempotencyKeys освобождает ключи идемпотентности транзакций старше ttl
+func (r *PostgresRepos) ExpireIdempotencyKeys(ctx context.Context, ttl time.Duration) (int64, error) {
+	const op = "storage.Postgres.ExpireIdempotencyKeys"
+
+	query := fmt.Sprintf(`UPDATE %s SET idempotency_key = NULL, request_hash = NULL
+		WHERE idempotency_key IS NOT NULL AND created_at < now() - make_interval(secs => $1)`, tableTransaction)
+	res, err := r.db.ExecContext(ctx, query, ttl.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	expired, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return expired, nil
+}
+
+// applyOperation проводит пополнение или списание по заблокированному кошельку в памяти,
+// проверив его статус, доступные средства и лимиты. Сохраняет баланс вызывающий.
+func (r *PostgresRepos) applyOperation(ctx context.Context, tx *sql.Tx, wallet *models.Wallet, operationType models.OperationType, amount int64) error {
 	switch operationType {
 	case models.DEPOSIT:
-		balance += amount
+		if err := ensureCanCredit(*wallet); err != nil {
+			return err
+		}
+
+		wallet.Balance += amount
+
+		return r.checkBalanceLimit(ctx, tx, *wallet)
 
 	case models.WITHDRAW:
-		if balance < amount {
-			return models.Transactions{}, fmt.Errorf("%s: %w", op, herrors.ErrInsufficientFunds)
+		if err := ensureCanDebit(*wallet); err != nil {
+			return err
+		}
+
+		if wallet.Spendable() < amount {
+			return herrors.ErrInsufficientFunds
 		}
 
-		balance -= amount
-	default:
-		return models.Transactions{}, fmt.Errorf("%s: %w", op, herrors.ErrUnknownOperation)
+		if err := r.checkDebitLimits(ctx, tx, wallet.ID, amount); err != nil {
+			return err
+		}
+
+		wallet.Balance -= amount
+
+		return nil
+	}
+
+	return herrors.ErrUnknownOperation
+}
+
+// applyTransfer переносит amount между заблокированными кошельками в памяти по тем же правилам
+func (r *PostgresRepos) applyTransfer(ctx context.Context, tx *sql.Tx, from, to *models.Wallet, amount int64) error {
+	if from.Currency != to.Currency {
+		return herrors.ErrCurrencyMismatch
+	}
+
+	if err := ensureCanDebit(*from); err != nil {
+		return err
+	}
+
+	if err := ensureCanCredit(*to); err != nil {
+		return err
 	}
 
-	updateQuery := fmt.Sprintf("UPDATE %s SET balance = $1 WHERE id = $2", tableWallets)
-	row = tx.QueryRowContext(ctx, updateQuery, balance, walletID)
-	if err := row.Err(); err != nil {
+	if from.Spendable() < amount {
+		return herrors.ErrInsufficientFunds
+	}
+
+	if err := r.checkDebitLimits(ctx, tx, from.ID, amount); err != nil {
+		return err
+	}
+
+	from.Balance -= amount
+	to.Balance += amount
+
+	return r.checkBalanceLimit(ctx, tx, *to)
+}
+
+// lockWallet читает кошелек, блокируя строку до конца транзакции
+func lockWallet(ctx context.Context, tx *sql.Tx, walletID uuid.UUID) (models.Wallet, error) {
+	wallet := models.Wallet{ID: walletID}
 
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = $1 FOR UPDATE", walletColumns, tableWallets)
+	row := tx.QueryRowContext(ctx, query, walletID)
+
+	if err := scanWallet(row, &wallet); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			err = herrors.ErrNXUUID
 		}
+		return models.Wallet{}, err
+	}
 
-		return models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	return wallet, nil
+}
+
+// saveBalance сохраняет учетный баланс и сумму холдов кошелька, заблокированного lockWallet
+func saveBalance(ctx context.Context, tx *sql.Tx, wallet models.Wallet) error {
+	query := fmt.Sprintf("UPDATE %s SET balance = $1, held = $2 WHERE id = $3", tableWallets)
+	res, err := tx.ExecContext(ctx, query, wallet.Balance, wallet.Held, wallet.ID)
+	if err != nil {
+		return err
 	}
 
-	transaction := models.Transactions{}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return herrors.ErrNXUUID
+	}
 
-	transationQuery := fmt.Sprintf("INSERT INTO %s (wallet_id, operation_type, amount) VALUES ($1, $2, $3) RETURNING id, wallet_id, operation_type, amount, created_at", tableTransaction)
-	row = tx.QueryRowContext(ctx, transationQuery, walletID, operationType, amount)
+	return nil
+}
+
+func insertTransaction(ctx context.Context, tx *sql.Tx, t models.Transactions, opts models.TxOptions) (models.Transactions, error) {
+	query := fmt.Sprintf(`INSERT INTO %s (wallet_id, operation_type, amount, currency, transfer_id, hold_id, reversal_of,
+			idempotency_key, request_hash, description, metadata, external_reference, fee_of)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), NULLIF($9, ''), NULLIF($10, ''), $11, NULLIF($12, ''), $13)
+		RETURNING %s`, tableTransaction, transactionColumns)
+
+	var metadata any
+	if len(opts.Metadata) > 0 {
+		metadata = []byte(opts.Metadata)
+	}
 
-	if err := row.Scan(&transaction.ID, &transaction.WalletID, &transaction.OperationType, &transaction.Amount, &transaction.Created_at); err != nil {
+	row := tx.QueryRowContext(ctx, query, t.WalletID, t.OperationType, t.Amount, t.Currency, t.TransferID, t.HoldID,
+		t.ReversalOf, opts.IdempotencyKey, opts.RequestHash, opts.Description, metadata, opts.ExternalReference, t.FeeOf)
 
+	transaction, err := scanTransaction(row)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			switch pgErr.ConstraintName {
+			case idempotencyKeyIndex:
+				err = herrors.ErrIdempotencyConflict
+			case externalReferenceIndex:
+				err = herrors.ErrDuplicateExternalReference
+			}
+		}
 		return models.Transactions{}, err
 	}
 
-	if err := tx.Commit(); err != nil {
+	if err := postLedgerEntry(ctx, tx, transaction); err != nil {
 		return models.Transactions{}, err
 	}
 
 	return transaction, nil
 }
+
+// replayIdempotent возвращает транзакцию, ранее созданную в кошельке walletID с ключом opts.IdempotencyKey,
+// вместе с ее комиссией. Если ключ использован с другим запросом, возвращает herrors.ErrIdempotencyConflict,
+// если ключ свободен - sql.ErrNoRows.
+func replayIdempotent(ctx context.Context, q queryRower, walletID uuid.UUID, opts models.TxOptions) (models.Transactions, error) {
+	original, hash, err := findByIdempotencyKey(ctx, q, walletID, opts.IdempotencyKey)
+	if err != nil {
+		return models.Transactions{}, err
+	}
+
+	if hash != opts.RequestHash {
+		return models.Transactions{}, herrors.ErrIdempotencyConflict
+	}
+
+	original.Fee, err = findFee(ctx, q, original.ID)
+	if err != nil {
+		return models.Transactions{}, err
+	}
+
+	original.Replayed = true
+
+	return original, nil
+}
+
+// findByIdempotencyKey возвращает транзакцию, ранее созданную в кошельке с этим ключом, и отпечаток ее запроса
+func findByIdempotencyKey(ctx context.Context, q queryRower, walletID uuid.UUID, key string) (models.Transactions, string, error) {
+	var hash string
+
+	query := fmt.Sprintf("SELECT %s, request_hash FROM %s WHERE wallet_id = $1 AND idempotency_key = $2",
+		transactionColumns, tableTransaction)
+	row := q.QueryRowContext(ctx, query, walletID, key)
+
+	transaction, err := scanTransaction(row, &hash)
+	if err != nil {
+		return models.Transactions{}, "", err
+	}
+
+	return transaction, hash, nil
+}
+
+// isIdempotencyRace сообщает, что вставка не удалась из-за параллельной транзакции с тем же ключом идемпотентности.
+// Сериализуемая транзакция, уже искавшая ключ, получает вместо нарушения уникальности ошибку сериализации.
+func isIdempotencyRace(err error) bool {
+	if errors.Is(err, herrors.ErrIdempotencyConflict) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgSerializationFailure
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanWallet(row scanner, wallet *models.Wallet, extra ...any) error {
+	dest := []any{&wallet.Balance, &wallet.Held, &wallet.Currency, &wallet.Status, &wallet.OverdraftLimit, &wallet.OwnerID, &wallet.Type}
+
+	return row.Scan(append(dest, extra...)...)
+}
+
+func scanTransaction(row scanner, extra ...any) (models.Transactions, error) {
+	transaction := models.Transactions{}
+
+	// pgx переиспользует буфер строки, поэтому JSONB копируется через отдельный срез
+	var metadata []byte
+
+	dest := []any{&transaction.ID, &transaction.WalletID, &transaction.OperationType, &transaction.Amount,
+		&transaction.Currency, &transaction.TransferID, &transaction.HoldID, &transaction.ReversalOf, &transaction.Created_at,
+		&transaction.Description, &metadata, &transaction.ExternalReference, &transaction.FeeOf}
+
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return models.Transactions{}, err
+	}
+
+	if metadata != nil {
+		transaction.Metadata = json.RawMessage(metadata)
+	}
+
+	transaction.Exponent = models.CurrencyExponent(transaction.Currency)
+
+	return transaction, nil
+}
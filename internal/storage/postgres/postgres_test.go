@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+	"wallets/internal/herrors"
+	"wallets/internal/models"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionsQueryUsesUTC(t *testing.T) {
+	walletID, _ := uuid.NewV4()
+	txID, _ := uuid.NewV4()
+
+	msk := time.FixedZone("MSK", 3*60*60)
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, msk)
+	to := time.Date(2026, 2, 1, 0, 0, 0, 0, msk)
+	after := time.Date(2026, 1, 15, 12, 0, 0, 0, msk)
+
+	query, args := transactionsQuery(models.TransactionFilter{
+		WalletID: walletID,
+		From:     &from,
+		To:       &to,
+		After:    &models.TransactionCursor{CreatedAt: after, ID: txID},
+		Limit:    10,
+	})
+
+	assert.Contains(t, query, "created_at >= $2 AND created_at < $3 AND (created_at, id) < ($4, $5)")
+	assert.Contains(t, query, "LIMIT $6")
+
+	assert.Equal(t, []any{
+		walletID,
+		time.Date(2025, 12, 31, 21, 0, 0, 0, time.UTC),
+		time.Date(2026, 1, 31, 21, 0, 0, 0, time.UTC),
+		time.Date(2026, 1, 15, 9, 0, 0, 0, time.UTC),
+		txID,
+		10,
+	}, args)
+}
+
+func TestRejectsPayout(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "wallet closed", err: fmt.Errorf("op: %w", herrors.ErrWalletClosed), want: true},
+		{name: "max balance", err: fmt.Errorf("op: %w", herrors.ErrLimitExceeded), want: true},
+		{name: "other", err: errors.New("connection reset"), want: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, rejectsPayout(tc.err))
+		})
+	}
+}
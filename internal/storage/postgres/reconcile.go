@@ -0,0 +1,54 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"wallets/internal/models"
+
+	"github.com/gofrs/uuid"
+)
+
+// RecountWallets пересчитывает баланс кошельков по всем их транзакциям и сумму холдов по активным холдам.
+// Кошельки отдаются по возрастанию id начиная после after.
+func (r *PostgresRepos) RecountWallets(ctx context.Context, after uuid.UUID, limit int) ([]models.WalletRecount, error) {
+	const op = "storage.Postgres.RecountWallets"
+
+	query := fmt.Sprintf(`SELECT w.id, w.balance, w.held, w.currency, w.status, w.overdraft_limit, COALESCE(w.owner_id, ''), w.type,
+			COALESCE((
+				SELECT SUM(CASE WHEN t.operation_type = ANY($3) THEN t.amount ELSE -t.amount END)
+				FROM %[2]s t WHERE t.wallet_id = w.id
+			), 0),
+			COALESCE((
+				SELECT SUM(h.amount) FROM %[3]s h WHERE h.wallet_id = w.id AND h.status = '%[4]s'
+			), 0)
+		FROM %[1]s w
+		WHERE w.id > $1
+		ORDER BY w.id
+		LIMIT $2`, tableWallets, tableTransaction, tableHolds, models.HOLD_ACTIVE)
+
+	rows, err := r.db.QueryContext(ctx, query, after, limit, creditOperations)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer rows.Close()
+
+	recounts := make([]models.WalletRecount, 0, limit)
+	for rows.Next() {
+		var recount models.WalletRecount
+
+		wallet := &recount.Wallet
+		if err := rows.Scan(&wallet.ID, &wallet.Balance, &wallet.Held, &wallet.Currency, &wallet.Status,
+			&wallet.OverdraftLimit, &wallet.OwnerID, &wallet.Type, &recount.ComputedBalance, &recount.ComputedHeld); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		recounts = append(recounts, recount)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return recounts, nil
+}
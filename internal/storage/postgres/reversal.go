@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"wallets/internal/herrors"
+	"wallets/internal/models"
+
+	"github.com/gofrs/uuid"
+)
+
+func (r *PostgresRepos) GetTransaction(ctx context.Context, txID uuid.UUID) (models.Transactions, error) {
+	const op = "storage.Postgres.GetTransaction"
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = $1", transactionColumns, tableTransaction)
+	transaction, err := scanTransaction(r.db.QueryRowContext(ctx, query, txID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = herrors.ErrTransactionNotFound
+		}
+		return models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return transaction, nil
+}
+
+// ReverseTransaction записывает компенсирующую операцию для DEPOSIT или WITHDRAW.
+// amount == 0 означает отмену всего еще не отмененного остатка.
+func (r *PostgresRepos) ReverseTransaction(ctx context.Context, txID uuid.UUID, amount int64) (models.Transactions, error) {
+	const op = "storage.Postgres.ReverseTransaction"
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer tx.Rollback()
+
+	var walletID uuid.UUID
+
+	query := fmt.Sprintf("SELECT wallet_id FROM %s WHERE id = $1", tableTransaction)
+	if err := tx.QueryRowContext(ctx, query, txID).Scan(&walletID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = herrors.ErrTransactionNotFound
+		}
+		return models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	wallet, err := lockWallet(ctx, tx, walletID)
+	if err != nil {
+		return models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Блокировка исходной записи не дает двум отменам одновременно посчитать один и тот же остаток
+	query = fmt.Sprintf("SELECT %s FROM %s WHERE id = $1 FOR UPDATE", transactionColumns, tableTransaction)
+	original, err := scanTransaction(tx.QueryRowContext(ctx, query, txID))
+	if err != nil {
+		return models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var reversalType models.OperationType
+
+	switch original.OperationType {
+	case models.DEPOSIT:
+		reversalType = models.REVERSAL_DEBIT
+	case models.WITHDRAW:
+		reversalType = models.REVERSAL_CREDIT
+	default:
+		return models.Transactions{}, fmt.Errorf("%s: %w", op, herrors.ErrNotReversible)
+	}
+
+	var reversed int64
+
+	query = fmt.Sprintf("SELECT COALESCE(SUM(amount), 0) FROM %s WHERE reversal_of = $1", tableTransaction)
+	if err := tx.QueryRowContext(ctx, query, txID).Scan(&reversed); err != nil {
+		return models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	remaining := original.Amount - reversed
+
+	if amount == 0 {
+		amount = remaining
+	}
+
+	if amount <= 0 || amount > remaining {
+		return models.Transactions{}, fmt.Errorf("%s: %w", op, herrors.ErrReversalExceedsAmount)
+	}
+
+	switch reversalType {
+	case models.REVERSAL_DEBIT:
+		if err := ensureCanDebit(wallet); err != nil {
+			return models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if wallet.Available() < amount {
+			return models.Transactions{}, fmt.Errorf("%s: %w", op, herrors.ErrFundsAlreadySpent)
+		}
+		wallet.Balance -= amount
+
+	case models.REVERSAL_CREDIT:
+		if err := ensureCanCredit(wallet); err != nil {
+			return models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		wallet.Balance += amount
+
+		if err := r.checkBalanceLimit(ctx, tx, wallet); err != nil {
+			return models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := saveBalance(ctx, tx, wallet); err != nil {
+		return models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	transaction, err := insertTransaction(ctx, tx, models.Transactions{
+		WalletID:      walletID,
+		OperationType: reversalType,
+		Amount:        amount,
+		Currency:      original.Currency,
+		ReversalOf:    uuid.NullUUID{UUID: txID, Valid: true},
+	}, models.TxOptions{})
+	if err != nil {
+		return models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := recordBalanceChanged(ctx, tx, transaction, wallet); err != nil {
+		return models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return transaction, nil
+}
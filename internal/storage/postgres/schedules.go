@@ -0,0 +1,251 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"wallets/internal/herrors"
+	"wallets/internal/models"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	tableSchedules    = "schedules"
+	tableScheduleRuns = "schedule_runs"
+
+	scheduleColumns = `id, wallet_id, to_wallet_id, operation_type, amount, COALESCE(description, ''),
+		COALESCE(recurrence, ''), start_at, next_run_at, occurrence, status, created_at, updated_at`
+	scheduleRunColumns = "id, schedule_id, scheduled_for, status, transaction_id, transfer_id, COALESCE(error, ''), executed_at"
+)
+
+// CreateSchedule сохраняет расписание с первым запуском в StartAt
+func (r *PostgresRepos) CreateSchedule(ctx context.Context, schedule models.Schedule) (models.Schedule, error) {
+	const op = "storage.Postgres.CreateSchedule"
+
+	query := fmt.Sprintf(`INSERT INTO %s (wallet_id, to_wallet_id, operation_type, amount, description, recurrence,
+			start_at, next_run_at)
+		SELECT $1, $2::uuid, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7, $7
+		WHERE EXISTS (SELECT 1 FROM %[2]s WHERE id = $1)
+			AND ($2::uuid IS NULL OR EXISTS (SELECT 1 FROM %[2]s WHERE id = $2::uuid))
+		RETURNING %s`, tableSchedules, tableWallets, scheduleColumns)
+	row := r.db.QueryRowContext(ctx, query, schedule.WalletID, schedule.ToWalletID, schedule.OperationType,
+		schedule.Amount, schedule.Description, schedule.Recurrence, schedule.StartAt.UTC())
+
+	created, err := scanSchedule(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = herrors.ErrNXUUID
+		}
+		return models.Schedule{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return created, nil
+}
+
+func (r *PostgresRepos) GetSchedule(ctx context.Context, id uuid.UUID) (models.Schedule, error) {
+	const op = "storage.Postgres.GetSchedule"
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = $1", scheduleColumns, tableSchedules)
+	row := r.db.QueryRowContext(ctx, query, id)
+
+	schedule, err := scanSchedule(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = herrors.ErrScheduleNotFound
+		}
+		return models.Schedule{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return schedule, nil
+}
+
+// ListWalletSchedules возвращает расписания операций кошелька, новые сначала
+func (r *PostgresRepos) ListWalletSchedules(ctx context.Context, walletID uuid.UUID) ([]models.Schedule, error) {
+	const op = "storage.Postgres.ListWalletSchedules"
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE wallet_id = $1 ORDER BY created_at DESC, id DESC",
+		scheduleColumns, tableSchedules)
+
+	rows, err := r.db.QueryContext(ctx, query, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer rows.Close()
+
+	schedules := []models.Schedule{}
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return schedules, nil
+}
+
+// SetScheduleStatus приостанавливает, возобновляет или отменяет расписание по models.ScheduleTransitions
+func (r *PostgresRepos) SetScheduleStatus(ctx context.Context, id uuid.UUID, status models.ScheduleStatus) (models.Schedule, error) {
+	const op = "storage.Postgres.SetScheduleStatus"
+
+	from := make([]string, 0, len(models.ScheduleTransitions[status]))
+	for _, s := range models.ScheduleTransitions[status] {
+		from = append(from, string(s))
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET status = $2, updated_at = now() WHERE id = $1 AND status = ANY($3) RETURNING %s",
+		tableSchedules, scheduleColumns)
+	row := r.db.QueryRowContext(ctx, query, id, status, from)
+
+	schedule, err := scanSchedule(row)
+	if err == nil {
+		return schedule, nil
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return models.Schedule{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err := r.GetSchedule(ctx, id); err != nil {
+		return models.Schedule{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return models.Schedule{}, fmt.Errorf("%s: %w", op, herrors.ErrInvalidScheduleTransition)
+}
+
+// ClaimDueSchedules забирает активные расписания, время запуска которых наступило, и закрепляет их на lease,
+// чтобы тот же запуск не выполнил параллельно другой экземпляр сервиса
+func (r *PostgresRepos) ClaimDueSchedules(ctx context.Context, limit int, lease time.Duration) ([]models.Schedule, error) {
+	const op = "storage.Postgres.ClaimDueSchedules"
+
+	query := fmt.Sprintf(`WITH due AS (
+			SELECT id AS due_id FROM %[1]s
+			WHERE status = $1 AND next_run_at <= now() AND (locked_until IS NULL OR locked_until <= now())
+			ORDER BY next_run_at LIMIT $2 FOR UPDATE SKIP LOCKED
+		)
+		UPDATE %[1]s s SET locked_until = now() + make_interval(secs => $3)
+		FROM due WHERE s.id = due.due_id
+		RETURNING %[2]s`, tableSchedules, scheduleColumns)
+
+	rows, err := r.db.QueryContext(ctx, query, models.SCHEDULE_ACTIVE, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer rows.Close()
+
+	schedules := []models.Schedule{}
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return schedules, nil
+}
+
+// RecordScheduleRun сохраняет результат запуска run.ScheduledFor и переводит расписание к next.
+// Если расписание успели приостановить или отменить, его статус сохраняется.
+func (r *PostgresRepos) RecordScheduleRun(ctx context.Context, run models.ScheduleRun, next models.Schedule) error {
+	const op = "storage.Postgres.RecordScheduleRun"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`INSERT INTO %s (schedule_id, scheduled_for, status, transaction_id, transfer_id, error)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''))
+		ON CONFLICT (schedule_id, scheduled_for) DO NOTHING`, tableScheduleRuns)
+	_, err = tx.ExecContext(ctx, query, run.ScheduleID, run.ScheduledFor.UTC(), run.Status, run.TransactionID,
+		run.TransferID, run.Error)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	query = fmt.Sprintf(`UPDATE %s SET
+			next_run_at = $3,
+			occurrence = $4,
+			status = CASE WHEN status = '%s' THEN $5 ELSE status END,
+			locked_until = NULL,
+			updated_at = now()
+		WHERE id = $1 AND next_run_at = $2`, tableSchedules, models.SCHEDULE_ACTIVE)
+	_, err = tx.ExecContext(ctx, query, run.ScheduleID, run.ScheduledFor.UTC(), next.NextRunAt.UTC(), next.Occurrence,
+		next.Status)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ListScheduleRuns возвращает журнал запусков расписания, последние сначала
+func (r *PostgresRepos) ListScheduleRuns(ctx context.Context, scheduleID uuid.UUID, limit int) ([]models.ScheduleRun, error) {
+	const op = "storage.Postgres.ListScheduleRuns"
+
+	if _, err := r.GetSchedule(ctx, scheduleID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE schedule_id = $1 ORDER BY scheduled_for DESC LIMIT $2",
+		scheduleRunColumns, tableScheduleRuns)
+
+	rows, err := r.db.QueryContext(ctx, query, scheduleID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer rows.Close()
+
+	runs := []models.ScheduleRun{}
+	for rows.Next() {
+		var run models.ScheduleRun
+
+		err := rows.Scan(&run.ID, &run.ScheduleID, &run.ScheduledFor, &run.Status, &run.TransactionID,
+			&run.TransferID, &run.Error, &run.ExecutedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		runs = append(runs, run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return runs, nil
+}
+
+func scanSchedule(row scanner) (models.Schedule, error) {
+	schedule := models.Schedule{}
+
+	err := row.Scan(&schedule.ID, &schedule.WalletID, &schedule.ToWalletID, &schedule.OperationType, &schedule.Amount,
+		&schedule.Description, &schedule.Recurrence, &schedule.StartAt, &schedule.NextRunAt, &schedule.Occurrence,
+		&schedule.Status, &schedule.CreatedAt, &schedule.UpdatedAt)
+	if err != nil {
+		return models.Schedule{}, err
+	}
+
+	return schedule, nil
+}
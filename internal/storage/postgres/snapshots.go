@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"wallets/internal/herrors"
+	"wallets/internal/models"
+
+	"github.com/gofrs/uuid"
+)
+
+const tableBalanceSnapshots = "balance_snapshots"
+
+// creditOperations - операции, увеличивающие баланс. Остальные операции его уменьшают.
+var creditOperations = func() []string {
+	operations := []string{}
+	for _, operationType := range models.CreditOperations() {
+		operations = append(operations, string(operationType))
+	}
+	return operations
+}()
+
+// TakeBalanceSnapshots фиксирует балансы кошельков на начало текущих суток. Граница суток берется
+// с отставанием lag, чтобы успели закоммититься транзакции, начатые до нее. Снимок создается только
+// для кошельков, у которых с предыдущего снимка были операции; повторный запуск ничего не меняет.
+func (r *PostgresRepos) TakeBalanceSnapshots(ctx context.Context, lag time.Duration) (int64, error) {
+	const op = "storage.Postgres.TakeBalanceSnapshots"
+
+	query := fmt.Sprintf(`WITH cutoff AS (
+			SELECT date_trunc('day', now()::timestamp - make_interval(secs => $1)) AS taken_at
+		), last AS (
+			SELECT w.id AS wallet_id, s.taken_at, s.balance
+			FROM %[3]s w
+			CROSS JOIN cutoff
+			LEFT JOIN LATERAL (
+				SELECT taken_at, balance FROM %[1]s
+				WHERE wallet_id = w.id AND taken_at < cutoff.taken_at
+				ORDER BY taken_at DESC LIMIT 1
+			) s ON true
+		)
+		INSERT INTO %[1]s (wallet_id, taken_at, balance)
+		SELECT last.wallet_id, cutoff.taken_at,
+			COALESCE(last.balance, 0) + SUM(CASE WHEN t.operation_type = ANY($2) THEN t.amount ELSE -t.amount END)
+		FROM cutoff
+		CROSS JOIN last
+		JOIN %[2]s t ON t.wallet_id = last.wallet_id AND t.created_at <= cutoff.taken_at
+			AND (last.taken_at IS NULL OR t.created_at > last.taken_at)
+		GROUP BY last.wallet_id, cutoff.taken_at, last.balance
+		ON CONFLICT (wallet_id, taken_at) DO NOTHING`, tableBalanceSnapshots, tableTransaction, tableWallets)
+
+	res, err := r.db.ExecContext(ctx, query, lag.Seconds(), creditOperations)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return n, nil
+}
+
+// GetBalanceAsOf восстанавливает состояние кошелька на момент asOf: баланс считается от ближайшего
+// снимка по транзакциям, сумма холдов - по холдам, активным в тот момент, статус - по журналу статусов
+func (r *PostgresRepos) GetBalanceAsOf(ctx context.Context, walletID uuid.UUID, asOf time.Time) (models.Wallet, error) {
+	const op = "storage.Postgres.GetBalanceAsOf"
+
+	wallet := models.Wallet{ID: walletID}
+
+	query := fmt.Sprintf(`SELECT w.currency,
+			COALESCE(s.balance, 0) + COALESCE((
+				SELECT SUM(CASE WHEN t.operation_type = ANY($3) THEN t.amount ELSE -t.amount END)
+				FROM %[2]s t
+				WHERE t.wallet_id = w.id AND t.created_at <= $2 AND (s.taken_at IS NULL OR t.created_at > s.taken_at)
+			), 0),
+			COALESCE((
+				SELECT SUM(h.amount) FROM %[3]s h
+				WHERE h.wallet_id = w.id AND h.created_at <= $2 AND (h.status = '%[5]s' OR h.updated_at > $2)
+			), 0),
+			COALESCE((
+				SELECT c.to_status FROM %[4]s c
+				WHERE c.wallet_id = w.id AND c.created_at <= $2
+				ORDER BY c.created_at DESC, c.id DESC LIMIT 1
+			), '%[6]s')
+		FROM %[1]s w
+		LEFT JOIN LATERAL (
+			SELECT taken_at, balance FROM %[7]s
+			WHERE wallet_id = w.id AND taken_at <= $2
+			ORDER BY taken_at DESC LIMIT 1
+		) s ON true
+		WHERE w.id = $1`,
+		tableWallets, tableTransaction, tableHolds, tableWalletStatusChanges, models.HOLD_ACTIVE, models.WALLET_ACTIVE,
+		tableBalanceSnapshots)
+
+	// Колонки TIMESTAMP хранят время сервера БД в UTC, а pgx отбрасывает зону при передаче параметра
+	row := r.db.QueryRowContext(ctx, query, walletID, asOf.UTC(), creditOperations)
+	if err := row.Scan(&wallet.Currency, &wallet.Balance, &wallet.Held, &wallet.Status); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = herrors.ErrNXUUID
+		}
+		return models.Wallet{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return wallet, nil
+}
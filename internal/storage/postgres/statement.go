@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"wallets/internal/herrors"
+	"wallets/internal/models"
+
+	"github.com/gofrs/uuid"
+)
+
+// StreamStatement отдает в w выписку кошелька за период [from, to): входящий остаток, операции
+// по порядку с балансом после каждой и исходящий остаток. Строки читаются курсором и не
+// накапливаются в памяти. Остаток и операции читаются из одного снимка базы.
+func (r *PostgresRepos) StreamStatement(ctx context.Context, walletID uuid.UUID, from, to time.Time, w models.StatementWriter) error {
+	const op = "storage.Postgres.StreamStatement"
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer tx.Rollback()
+
+	statement := models.Statement{WalletID: walletID, From: from, To: to}
+
+	query := fmt.Sprintf(`SELECT w.currency,
+			COALESCE(s.balance, 0) + COALESCE((
+				SELECT SUM(CASE WHEN t.operation_type = ANY($3) THEN t.amount ELSE -t.amount END)
+				FROM %[2]s t
+				WHERE t.wallet_id = w.id AND t.created_at < $2 AND (s.taken_at IS NULL OR t.created_at > s.taken_at)
+			), 0)
+		FROM %[1]s w
+		LEFT JOIN LATERAL (
+			SELECT taken_at, balance FROM %[3]s
+			WHERE wallet_id = w.id AND taken_at < $2
+			ORDER BY taken_at DESC LIMIT 1
+		) s ON true
+		WHERE w.id = $1`, tableWallets, tableTransaction, tableBalanceSnapshots)
+
+	row := tx.QueryRowContext(ctx, query, walletID, from.UTC(), creditOperations)
+	if err := row.Scan(&statement.Currency, &statement.OpeningBalance); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = herrors.ErrNXUUID
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	statement.Exponent = models.CurrencyExponent(statement.Currency)
+
+	if err := w.Begin(statement); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	query = fmt.Sprintf(`SELECT %s FROM %s
+		WHERE wallet_id = $1 AND created_at >= $2 AND created_at < $3
+		ORDER BY created_at, id`, transactionColumns, tableTransaction)
+
+	rows, err := tx.QueryContext(ctx, query, walletID, from.UTC(), to.UTC())
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer rows.Close()
+
+	balance := statement.OpeningBalance
+	for rows.Next() {
+		transaction, err := scanTransaction(rows)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		posting, ok := models.PostingFor(transaction.OperationType)
+		if !ok {
+			return fmt.Errorf("%s: %w", op, herrors.ErrNoLedgerPosting)
+		}
+
+		amount := transaction.Amount
+		if posting.WalletDebited {
+			amount = -amount
+		}
+		balance += amount
+
+		if err := w.Line(models.StatementLine{
+			ID:            transaction.ID,
+			OperationType: transaction.OperationType,
+			Amount:        amount,
+			Balance:       balance,
+			CreatedAt:     transaction.Created_at,
+		}); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := w.End(balance); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
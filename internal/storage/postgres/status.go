@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"wallets/internal/herrors"
+	"wallets/internal/models"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	tableWalletStatusChanges = "wallet_status_changes"
+
+	statusChangeColumns = "id, wallet_id, from_status, to_status, changed_by, reason, created_at"
+)
+
+// SetWalletStatus меняет статус кошелька и записывает изменение в журнал
+func (r *PostgresRepos) SetWalletStatus(ctx context.Context, walletID uuid.UUID, status models.WalletStatus, changedBy, reason string) (models.WalletStatusChange, error) {
+	const op = "storage.Postgres.SetWalletStatus"
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return models.WalletStatusChange{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer tx.Rollback()
+
+	wallet, err := lockWallet(ctx, tx, walletID)
+	if err != nil {
+		return models.WalletStatusChange{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if wallet.Status == status || wallet.Status == models.WALLET_CLOSED {
+		return models.WalletStatusChange{}, fmt.Errorf("%s: %w", op, herrors.ErrInvalidStatusTransition)
+	}
+
+	if status == models.WALLET_CLOSED && (wallet.Balance != 0 || wallet.Held != 0) {
+		return models.WalletStatusChange{}, fmt.Errorf("%s: %w", op, herrors.ErrWalletNotEmpty)
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET status = $1 WHERE id = $2", tableWallets)
+	if _, err := tx.ExecContext(ctx, query, status, walletID); err != nil {
+		return models.WalletStatusChange{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %s (wallet_id, from_status, to_status, changed_by, reason)
+		VALUES ($1, $2, $3, $4, $5) RETURNING %s`, tableWalletStatusChanges, statusChangeColumns)
+	row := tx.QueryRowContext(ctx, query, walletID, wallet.Status, status, changedBy, reason)
+
+	change, err := scanStatusChange(row)
+	if err != nil {
+		return models.WalletStatusChange{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := recordStatusChanged(ctx, tx, change); err != nil {
+		return models.WalletStatusChange{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.WalletStatusChange{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return change, nil
+}
+
+func (r *PostgresRepos) ListStatusChanges(ctx context.Context, walletID uuid.UUID) ([]models.WalletStatusChange, error) {
+	const op = "storage.Postgres.ListStatusChanges"
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE wallet_id = $1 ORDER BY created_at DESC, id DESC",
+		statusChangeColumns, tableWalletStatusChanges)
+
+	rows, err := r.db.QueryContext(ctx, query, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer rows.Close()
+
+	changes := []models.WalletStatusChange{}
+	for rows.Next() {
+		change, err := scanStatusChange(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		changes = append(changes, change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return changes, nil
+}
+
+// ensureCanDebit проверяет, что статус кошелька разрешает списания
+func ensureCanDebit(wallet models.Wallet) error {
+	switch wallet.Status {
+	case models.WALLET_FROZEN:
+		return herrors.ErrWalletFrozen
+	case models.WALLET_CLOSED:
+		return herrors.ErrWalletClosed
+	}
+
+	return nil
+}
+
+// ensureCanCredit проверяет, что статус кошелька разрешает зачисления
+func ensureCanCredit(wallet models.Wallet) error {
+	if wallet.Status == models.WALLET_CLOSED {
+		return herrors.ErrWalletClosed
+	}
+
+	return nil
+}
+
+func scanStatusChange(row scanner) (models.WalletStatusChange, error) {
+	change := models.WalletStatusChange{}
+
+	if err := row.Scan(&change.ID, &change.WalletID, &change.FromStatus, &change.ToStatus,
+		&change.ChangedBy, &change.Reason, &change.CreatedAt); err != nil {
+		return models.WalletStatusChange{}, err
+	}
+
+	return change, nil
+}
@@ -0,0 +1,262 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"wallets/internal/herrors"
+	"wallets/internal/models"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	tableWebhookSubscriptions = "webhook_subscriptions"
+	tableWebhookDeliveries    = "webhook_deliveries"
+
+	subscriptionColumns = "id, url, wallet_id, active, created_at"
+	deliveryColumns     = `id, subscription_id, event_id, event_type, status, attempts, next_attempt_at, last_status_code,
+		last_error, created_at, delivered_at`
+)
+
+// CreateWebhook регистрирует подписку. Подписка без walletID получает события всех кошельков.
+func (r *PostgresRepos) CreateWebhook(ctx context.Context, url string, walletID uuid.NullUUID, secret string) (models.WebhookSubscription, error) {
+	const op = "storage.Postgres.CreateWebhook"
+
+	query := fmt.Sprintf(`INSERT INTO %s (url, wallet_id, secret)
+		SELECT $1, $2::uuid, $3 WHERE $2::uuid IS NULL OR EXISTS (SELECT 1 FROM %s WHERE id = $2::uuid)
+		RETURNING %s`, tableWebhookSubscriptions, tableWallets, subscriptionColumns)
+	row := r.db.QueryRowContext(ctx, query, url, walletID, secret)
+
+	subscription, err := scanSubscription(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = herrors.ErrNXUUID
+		}
+		return models.WebhookSubscription{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	subscription.Secret = secret
+
+	return subscription, nil
+}
+
+// GetWebhook возвращает подписку, в том числе отключенную
+func (r *PostgresRepos) GetWebhook(ctx context.Context, id uuid.UUID) (models.WebhookSubscription, error) {
+	const op = "storage.Postgres.GetWebhook"
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = $1", subscriptionColumns, tableWebhookSubscriptions)
+	row := r.db.QueryRowContext(ctx, query, id)
+
+	subscription, err := scanSubscription(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = herrors.ErrWebhookNotFound
+		}
+		return models.WebhookSubscription{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return subscription, nil
+}
+
+// DeleteWebhook отключает подписку. Журнал ее доставок сохраняется.
+func (r *PostgresRepos) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	const op = "storage.Postgres.DeleteWebhook"
+
+	query := fmt.Sprintf("UPDATE %s SET active = false WHERE id = $1 AND active", tableWebhookSubscriptions)
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%s: %w", op, herrors.ErrWebhookNotFound)
+	}
+
+	return nil
+}
+
+// EnqueueWebhookDeliveries создает доставки события для всех подходящих активных подписок.
+// Повторный вызов для того же события новых доставок не создает.
+func (r *PostgresRepos) EnqueueWebhookDeliveries(ctx context.Context, event models.OutboxEvent) (int64, error) {
+	const op = "storage.Postgres.EnqueueWebhookDeliveries"
+
+	query := fmt.Sprintf(`INSERT INTO %s (subscription_id, event_id, event_type)
+		SELECT id, $1, $2 FROM %s WHERE active AND (wallet_id IS NULL OR wallet_id = $3)
+		ON CONFLICT (subscription_id, event_id) DO NOTHING`, tableWebhookDeliveries, tableWebhookSubscriptions)
+
+	res, err := r.db.ExecContext(ctx, query, event.ID, event.Type, event.WalletID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return n, nil
+}
+
+// ClaimWebhookDeliveries забирает доставки, время попытки которых наступило, и откладывает их на lease,
+// чтобы их не отправил параллельно другой экземпляр сервиса
+func (r *PostgresRepos) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookTask, error) {
+	const op = "storage.Postgres.ClaimWebhookDeliveries"
+
+	query := fmt.Sprintf(`WITH due AS (
+			SELECT id FROM %[1]s WHERE status = $1 AND next_attempt_at <= now()
+			ORDER BY next_attempt_at LIMIT $2 FOR UPDATE SKIP LOCKED
+		)
+		UPDATE %[1]s d SET next_attempt_at = now() + make_interval(secs => $3)
+		FROM due, %[2]s s, %[3]s o
+		WHERE d.id = due.id AND s.id = d.subscription_id AND o.id = d.event_id
+		RETURNING d.id, d.subscription_id, d.event_id, d.event_type, d.status, d.attempts, d.next_attempt_at,
+			d.last_status_code, d.last_error, d.created_at, d.delivered_at,
+			s.url, s.secret, o.wallet_id, o.payload, o.created_at`,
+		tableWebhookDeliveries, tableWebhookSubscriptions, tableOutbox)
+
+	rows, err := r.db.QueryContext(ctx, query, models.DELIVERY_PENDING, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer rows.Close()
+
+	tasks := []models.WebhookTask{}
+	for rows.Next() {
+		var (
+			task    models.WebhookTask
+			payload []byte
+		)
+
+		task.Delivery, err = scanDelivery(rows, &task.URL, &task.Secret, &task.Event.WalletID, &payload,
+			&task.Event.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		task.Event.ID = task.Delivery.EventID
+		task.Event.Payload = payload
+		task.Event.Type = task.Delivery.EventType
+
+		tasks = append(tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tasks, nil
+}
+
+// RecordDeliveryAttempt сохраняет результат попытки доставки.
+// При статусе DELIVERY_PENDING следующая попытка назначается через retryIn.
+func (r *PostgresRepos) RecordDeliveryAttempt(ctx context.Context, id uuid.UUID, result models.DeliveryResult) error {
+	const op = "storage.Postgres.RecordDeliveryAttempt"
+
+	query := fmt.Sprintf(`UPDATE %s SET
+			status = $2,
+			attempts = attempts + 1,
+			next_attempt_at = now() + make_interval(secs => $3),
+			last_status_code = $4,
+			last_error = NULLIF($5, ''),
+			delivered_at = CASE WHEN $2 = '%s' THEN now() END
+		WHERE id = $1`, tableWebhookDeliveries, models.DELIVERY_DELIVERED)
+
+	_, err := r.db.ExecContext(ctx, query, id, result.Status, result.RetryIn.Seconds(), result.StatusCode, result.Error)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ListWebhookDeliveries возвращает журнал доставок подписки, последние сначала
+func (r *PostgresRepos) ListWebhookDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]models.WebhookDelivery, error) {
+	const op = "storage.Postgres.ListWebhookDeliveries"
+
+	var exists bool
+
+	query := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE id = $1)", tableWebhookSubscriptions)
+	if err := r.db.QueryRowContext(ctx, query, subscriptionID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !exists {
+		return nil, fmt.Errorf("%s: %w", op, herrors.ErrWebhookNotFound)
+	}
+
+	query = fmt.Sprintf("SELECT %s FROM %s WHERE subscription_id = $1 ORDER BY created_at DESC, event_id DESC LIMIT $2",
+		deliveryColumns, tableWebhookDeliveries)
+
+	rows, err := r.db.QueryContext(ctx, query, subscriptionID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return deliveries, nil
+}
+
+// RedeliverWebhook ставит доставку в очередь заново с полным набором попыток,
+// в том числе уже доставленную или исчерпавшую попытки
+func (r *PostgresRepos) RedeliverWebhook(ctx context.Context, subscriptionID, deliveryID uuid.UUID) (models.WebhookDelivery, error) {
+	const op = "storage.Postgres.RedeliverWebhook"
+
+	query := fmt.Sprintf(`UPDATE %s SET status = $3, attempts = 0, next_attempt_at = now(), delivered_at = NULL
+		WHERE id = $1 AND subscription_id = $2
+		RETURNING %s`, tableWebhookDeliveries, deliveryColumns)
+	row := r.db.QueryRowContext(ctx, query, deliveryID, subscriptionID, models.DELIVERY_PENDING)
+
+	delivery, err := scanDelivery(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = herrors.ErrDeliveryNotFound
+		}
+		return models.WebhookDelivery{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return delivery, nil
+}
+
+func scanSubscription(row scanner) (models.WebhookSubscription, error) {
+	subscription := models.WebhookSubscription{}
+
+	err := row.Scan(&subscription.ID, &subscription.URL, &subscription.WalletID, &subscription.Active,
+		&subscription.CreatedAt)
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	return subscription, nil
+}
+
+func scanDelivery(row scanner, extra ...any) (models.WebhookDelivery, error) {
+	delivery := models.WebhookDelivery{}
+
+	dest := []any{&delivery.ID, &delivery.SubscriptionID, &delivery.EventID, &delivery.EventType, &delivery.Status,
+		&delivery.Attempts, &delivery.NextAttemptAt, &delivery.LastStatusCode, &delivery.LastError,
+		&delivery.CreatedAt, &delivery.DeliveredAt}
+
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	return delivery, nil
+}
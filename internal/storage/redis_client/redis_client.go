@@ -4,21 +4,31 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 	"wallets/internal/config"
+	"wallets/internal/metrics"
+	"wallets/internal/models"
 
 	"github.com/gofrs/uuid"
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 )
 
 const (
 	pong                 = "PONG"
-	expDuration          = 500 * time.Millisecond // TODO убрать в конфиг
-	cacheExpDuration     = 10 * time.Minute       // TODO убрать в конфиг
+	cacheExpDuration     = 10 * time.Minute // TODO убрать в конфиг
 	lockWalletKey        = "lock:wallet"
-	walletKey            = "wallet"
+	walletKey            = "wallet:v2"           // хэш; под прежним префиксом wallet лежали строки
 	maxLockWalletRetries = 20                    // TODO убрать в конфиг
 	lockWalletBaseDelay  = 50 * time.Millisecond // TODO убрать в конфиг
+	balanceField         = "balance"
+	heldField            = "held"
+	currencyField        = "currency"
+	statusField          = "status"
+	overdraftField       = "overdraft_limit"
+	ownerField           = "owner_id"
+	typeField            = "type"
 )
 
 type RedisClient struct {
@@ -33,6 +43,10 @@ func New(cfg config.Redis) (*RedisClient, error) {
 		DB:       cfg.DB,
 	})
 
+	if err := redisotel.InstrumentTracing(client); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
 	redis_pong, err := client.Ping(context.Background()).Result()
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
@@ -45,32 +59,90 @@ func New(cfg config.Redis) (*RedisClient, error) {
 	return &RedisClient{client: client}, nil
 }
 
-func (r *RedisClient) LockWallet(ctx context.Context, walletID uuid.UUID) (bool, error) {
+// Ping проверяет соединение с Redis для проверки готовности сервиса
+func (r *RedisClient) Ping(ctx context.Context) error {
+	const op = "storage.redis_client.Ping"
+
+	if err := r.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// unlockScript снимает блокировку, только если она все еще принадлежит владельцу токена ARGV[1].
+// Истекшую блокировку мог взять другой запрос, и удалять ее нельзя.
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// extendScript продлевает блокировку до ARGV[2] миллисекунд, только если она все еще принадлежит
+// владельцу токена ARGV[1]
+var extendScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+// LockWallet берет блокировку кошелька на ttl и возвращает токен владельца для UnlockWallet
+func (r *RedisClient) LockWallet(ctx context.Context, walletID uuid.UUID, ttl time.Duration) (string, bool, error) {
+	token, err := uuid.NewV4()
+	if err != nil {
+		return "", false, err
+	}
+
 	key := fmt.Sprintf("%s:%s", lockWalletKey, walletID.String())
-	locked, err := r.client.SetNX(ctx, key, 1, expDuration).Result()
+	locked, err := r.client.SetNX(ctx, key, token.String(), ttl).Result()
 	if err != nil {
-		return false, err
+		return "", false, err
+	}
+
+	if !locked {
+		return "", false, nil
 	}
 
-	return locked, nil
+	return token.String(), true, nil
 
 }
 
-func (r *RedisClient) UnlockWallet(ctx context.Context, walletID uuid.UUID) {
+// UnlockWallet снимает блокировку, взятую с токеном token
+func (r *RedisClient) UnlockWallet(ctx context.Context, walletID uuid.UUID, token string) {
 	key := fmt.Sprintf("%s:%s", lockWalletKey, walletID.String())
-	r.client.Del(ctx, key)
+	unlockScript.Run(ctx, r.client, []string{key}, token)
+
+}
+
+// ExtendWalletLock продлевает блокировку с токеном token на ttl. Возвращает false, если блокировка
+// уже истекла или принадлежит другому запросу.
+func (r *RedisClient) ExtendWalletLock(ctx context.Context, walletID uuid.UUID, token string, ttl time.Duration) (bool, error) {
+	key := fmt.Sprintf("%s:%s", lockWalletKey, walletID.String())
+	extended, err := extendScript.Run(ctx, r.client, []string{key}, token, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
 
+	return extended == 1, nil
 }
 
-func (r *RedisClient) TryLockWallet(ctx context.Context, walletID uuid.UUID) (bool, error) {
+func (r *RedisClient) TryLockWallet(ctx context.Context, walletID uuid.UUID, ttl time.Duration) (string, bool, error) {
+	start := time.Now()
+
 	for i := 0; i < maxLockWalletRetries; i++ {
-		locked, err := r.LockWallet(ctx, walletID)
+		if i > 0 {
+			metrics.LockRetries.Inc()
+		}
+
+		token, locked, err := r.LockWallet(ctx, walletID, ttl)
 		if err != nil {
-			return false, err
+			metrics.LockAcquireDuration.WithLabelValues(metrics.LockError).Observe(time.Since(start).Seconds())
+			return "", false, err
 		}
 
 		if locked {
-			return true, nil
+			metrics.LockAcquireDuration.WithLabelValues(metrics.LockAcquired).Observe(time.Since(start).Seconds())
+			return token, true, nil
 		}
 
 		delay := lockWalletBaseDelay * time.Duration(1<<i)
@@ -80,26 +152,75 @@ func (r *RedisClient) TryLockWallet(ctx context.Context, walletID uuid.UUID) (bo
 		time.Sleep(delay)
 	}
 
-	return false, nil
+	metrics.LockFailures.Inc()
+	metrics.LockAcquireDuration.WithLabelValues(metrics.LockBusy).Observe(time.Since(start).Seconds())
+
+	return "", false, nil
 }
 
-func (r *RedisClient) GetCachedBalance(ctx context.Context, walletID uuid.UUID) (int64, error) {
+func (r *RedisClient) GetCachedBalance(ctx context.Context, walletID uuid.UUID) (models.Wallet, error) {
 	key := fmt.Sprintf("%s:%s", walletKey, walletID.String())
-	balance, err := r.client.Get(ctx, key).Int64()
+	fields, err := r.client.HGetAll(ctx, key).Result()
 	if err != nil {
-		return 0, err
+		return models.Wallet{}, err
 	}
 
-	return balance, nil
+	if len(fields) == 0 {
+		return models.Wallet{}, redis.Nil
+	}
+
+	balance, err := strconv.ParseInt(fields[balanceField], 10, 64)
+	if err != nil {
+		return models.Wallet{}, err
+	}
+
+	held, err := strconv.ParseInt(fields[heldField], 10, 64)
+	if err != nil {
+		return models.Wallet{}, err
+	}
+
+	overdraft, err := strconv.ParseInt(fields[overdraftField], 10, 64)
+	if err != nil {
+		return models.Wallet{}, err
+	}
+
+	return models.Wallet{
+		ID:             walletID,
+		Balance:        balance,
+		Held:           held,
+		Currency:       fields[currencyField],
+		Status:         models.WalletStatus(fields[statusField]),
+		OverdraftLimit: overdraft,
+		OwnerID:        fields[ownerField],
+		Type:           fields[typeField],
+	}, nil
 
 }
 
-func (r *RedisClient) SetCachedBalance(ctx context.Context, walletID uuid.UUID, balance int64) error {
-	key := fmt.Sprintf("%s:%s", walletKey, walletID)
-	return r.client.Set(ctx, key, balance, cacheExpDuration).Err()
+func (r *RedisClient) SetCachedBalance(ctx context.Context, wallet models.Wallet) error {
+	key := fmt.Sprintf("%s:%s", walletKey, wallet.ID)
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, balanceField, wallet.Balance, heldField, wallet.Held, currencyField, wallet.Currency,
+			statusField, string(wallet.Status), overdraftField, wallet.OverdraftLimit, ownerField, wallet.OwnerID,
+			typeField, wallet.Type)
+		pipe.Expire(ctx, key, cacheExpDuration)
+		return nil
+	})
+
+	return err
 }
 
 func (r *RedisClient) InvalidateCache(ctx context.Context, walletID uuid.UUID) {
 	key := fmt.Sprintf("%s:%s", walletKey, walletID)
 	r.client.Del(ctx, key)
 }
+
+// AddToStream добавляет запись в Redis Stream, ограничивая его длину примерно maxLen записями
+func (r *RedisClient) AddToStream(ctx context.Context, stream string, maxLen int64, values map[string]any) error {
+	return r.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: stream,
+		MaxLen: maxLen,
+		Approx: true,
+		Values: values,
+	}).Err()
+}
@@ -2,30 +2,80 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 	"wallets/internal/herrors"
+	"wallets/internal/metrics"
 	"wallets/internal/models"
+	"wallets/internal/tracing"
 
 	"github.com/gofrs/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
 	DBRequestTimeout = 1 * time.Second // TODO убрать в конфиг
+
+	lockTTL          = 500 * time.Millisecond // TODO убрать в конфиг
+	lockTTLPerWallet = 20 * time.Millisecond  // запас на каждый кошелек операции, см. lockWalletsTTL
+
+	// holdOperation - метка холда в метриках отказов, у холда нет своего типа операции
+	holdOperation = "HOLD"
 )
 
 type DBRepos interface {
-	CreateWallet(ctx context.Context, balance int64) (uuid.UUID, error)
-	GetBalance(ctx context.Context, walletID uuid.UUID) (int64, error)
-	UpdateBalance(ctx context.Context, walletID uuid.UUID, operationType models.OperationType, amount int64) (models.Transactions, error)
+	CreateWallet(ctx context.Context, balance int64, currency, ownerID, walletType string) (uuid.UUID, error)
+	ListOwnerWallets(ctx context.Context, ownerID string) ([]models.Wallet, error)
+	GetBalance(ctx context.Context, walletID uuid.UUID) (models.Wallet, error)
+	UpdateBalance(ctx context.Context, walletID uuid.UUID, operationType models.OperationType, amount int64, opts models.TxOptions) (models.Transactions, error)
+	Transfer(ctx context.Context, fromID, toID uuid.UUID, amount int64) (models.Transfer, error)
+	ApplyBatch(ctx context.Context, items []models.BatchItem) ([]models.BatchItemResult, error)
+	FeeRevenueWallets(ctx context.Context, items []models.BatchItem) ([]uuid.UUID, error)
+	ListTransactions(ctx context.Context, filter models.TransactionFilter) ([]models.Transactions, error)
+	CreateHold(ctx context.Context, walletID uuid.UUID, amount int64, ttl time.Duration) (models.Hold, error)
+	GetHold(ctx context.Context, holdID uuid.UUID) (models.Hold, error)
+	CaptureHold(ctx context.Context, holdID uuid.UUID, amount int64) (models.Hold, models.Transactions, error)
+	VoidHold(ctx context.Context, holdID uuid.UUID) (models.Hold, error)
+	ListExpiredHoldWallets(ctx context.Context) ([]uuid.UUID, error)
+	ExpireWalletHolds(ctx context.Context, walletID uuid.UUID) (int, error)
+	GetTransaction(ctx context.Context, txID uuid.UUID) (models.Transactions, error)
+	ReverseTransaction(ctx context.Context, txID uuid.UUID, amount int64) (models.Transactions, error)
+	SetWalletStatus(ctx context.Context, walletID uuid.UUID, status models.WalletStatus, changedBy, reason string) (models.WalletStatusChange, error)
+	ListStatusChanges(ctx context.Context, walletID uuid.UUID) ([]models.WalletStatusChange, error)
+	GetLimits(ctx context.Context, walletID uuid.UUID) (models.WalletLimits, error)
+	SetLimits(ctx context.Context, walletID uuid.UUID, overrides models.LimitOverrides) (models.WalletLimits, error)
+	SetOverdraftLimit(ctx context.Context, walletID uuid.UUID, limit int64) (models.Wallet, error)
+	CreateWebhook(ctx context.Context, url string, walletID uuid.NullUUID, secret string) (models.WebhookSubscription, error)
+	DeleteWebhook(ctx context.Context, id uuid.UUID) error
+	ListWebhookDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]models.WebhookDelivery, error)
+	RedeliverWebhook(ctx context.Context, subscriptionID, deliveryID uuid.UUID) (models.WebhookDelivery, error)
+	CheckLedger(ctx context.Context) (models.LedgerReport, error)
+	GetBalanceAsOf(ctx context.Context, walletID uuid.UUID, asOf time.Time) (models.Wallet, error)
+	TakeBalanceSnapshots(ctx context.Context, lag time.Duration) (int64, error)
+	StreamStatement(ctx context.Context, walletID uuid.UUID, from, to time.Time, w models.StatementWriter) error
+	CreateAPIKey(ctx context.Context, name string, scopes []models.Scope, walletIDs []uuid.UUID, keyHash string) (models.APIKey, error)
+	RotateAPIKey(ctx context.Context, id uuid.UUID, keyHash string) (models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) error
+	FindAPIKey(ctx context.Context, keyHash string) (models.APIKey, error)
+	CreateSchedule(ctx context.Context, schedule models.Schedule) (models.Schedule, error)
+	GetSchedule(ctx context.Context, id uuid.UUID) (models.Schedule, error)
+	ListWalletSchedules(ctx context.Context, walletID uuid.UUID) ([]models.Schedule, error)
+	SetScheduleStatus(ctx context.Context, id uuid.UUID, status models.ScheduleStatus) (models.Schedule, error)
+	ListScheduleRuns(ctx context.Context, scheduleID uuid.UUID, limit int) ([]models.ScheduleRun, error)
+	PayInterest(ctx context.Context, walletID uuid.UUID, period time.Time) (models.InterestPayout, error)
+	ListInterestPayouts(ctx context.Context, walletID uuid.UUID, limit int) ([]models.InterestPayout, error)
+	ListInterestAccruals(ctx context.Context, filter models.InterestAccrualFilter) ([]models.InterestAccrual, error)
 }
 
 type CacheRepos interface {
-	LockWallet(ctx context.Context, walletID uuid.UUID) (bool, error)
-	UnlockWallet(ctx context.Context, walletID uuid.UUID)
-	TryLockWallet(ctx context.Context, walletID uuid.UUID) (bool, error)
-	GetCachedBalance(ctx context.Context, walletID uuid.UUID) (int64, error)
-	SetCachedBalance(ctx context.Context, walletID uuid.UUID, balance int64) error
+	LockWallet(ctx context.Context, walletID uuid.UUID, ttl time.Duration) (string, bool, error)
+	UnlockWallet(ctx context.Context, walletID uuid.UUID, token string)
+	TryLockWallet(ctx context.Context, walletID uuid.UUID, ttl time.Duration) (string, bool, error)
+	ExtendWalletLock(ctx context.Context, walletID uuid.UUID, token string, ttl time.Duration) (bool, error)
+	GetCachedBalance(ctx context.Context, walletID uuid.UUID) (models.Wallet, error)
+	SetCachedBalance(ctx context.Context, wallet models.Wallet) error
 	InvalidateCache(ctx context.Context, walletID uuid.UUID)
 }
 
@@ -41,59 +91,476 @@ func NewStorage(db DBRepos, cache CacheRepos) *Storage {
 	}
 }
 
-func (r *Storage) GetBalance(ctx context.Context, walletID uuid.UUID) (int64, error) {
+func (r *Storage) GetBalance(ctx context.Context, walletID uuid.UUID) (models.Wallet, error) {
+	ctx, span := tracing.Start(ctx, "Storage.GetBalance", attribute.String("wallet.id", walletID.String()))
+	defer span.End()
+
+	wallet, err := r.getBalance(ctx, walletID)
+	tracing.RecordError(span, err)
+
+	return wallet, err
+}
+
+func (r *Storage) getBalance(ctx context.Context, walletID uuid.UUID) (models.Wallet, error) {
 	const op = "storage.GetBalance"
 
 	if ctx.Err() != nil {
-		return 0, ctx.Err()
+		return models.Wallet{}, ctx.Err()
 	}
 
-	balance, err := r.Redis.GetCachedBalance(ctx, walletID)
+	wallet, err := r.Redis.GetCachedBalance(ctx, walletID)
 	if err == nil {
-		return balance, nil
+		metrics.BalanceCache.WithLabelValues(metrics.CacheHit).Inc()
+		trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("cache.hit", true))
+		return wallet, nil
 	}
 
-	locked, err := r.Redis.TryLockWallet(ctx, walletID)
+	metrics.BalanceCache.WithLabelValues(metrics.CacheMiss).Inc()
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("cache.hit", false))
+
+	token, locked, err := r.Redis.TryLockWallet(ctx, walletID, lockTTL)
 	if err != nil {
-		return 0, fmt.Errorf("%s: %w", op, err)
+		return models.Wallet{}, fmt.Errorf("%s: %w", op, err)
 	}
 
 	if !locked {
-		return 0, herrors.ErrLockedWallet
+		return models.Wallet{}, herrors.ErrLockedWallet
 	}
 
-	defer r.Redis.UnlockWallet(ctx, walletID)
+	defer r.Redis.UnlockWallet(ctx, walletID, token)
 
-	balance, err = r.DB.GetBalance(ctx, walletID)
+	wallet, err = r.DB.GetBalance(ctx, walletID)
 	if err != nil {
-		return 0, fmt.Errorf("%s: %w", op, err)
+		return models.Wallet{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	_ = r.Redis.SetCachedBalance(ctx, wallet) //TODO обработать ошибку выше, пока так
+
+	return wallet, nil
+}
+
+// GetBalanceAsOf читает исторический баланс напрямую из базы: он не меняется и в кэш не попадает
+func (r *Storage) GetBalanceAsOf(ctx context.Context, walletID uuid.UUID, asOf time.Time) (models.Wallet, error) {
+	const op = "storage.GetBalanceAsOf"
+
+	wallet, err := r.DB.GetBalanceAsOf(ctx, walletID, asOf)
+	if err != nil {
+		return models.Wallet{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	_ = r.Redis.SetCachedBalance(ctx, walletID, balance) //TODO обработать ошибку выше, пока так
+	return wallet, nil
+}
 
-	return balance, nil
+func (r *Storage) UpdateBalance(ctx context.Context, walletID uuid.UUID, operationType models.OperationType, amount int64, opts models.TxOptions) (models.Transactions, error) {
+	ctx, span := tracing.Start(ctx, "Storage.UpdateBalance",
+		attribute.String("wallet.id", walletID.String()),
+		attribute.String("operation.type", string(operationType)),
+		attribute.Int64("operation.amount", amount),
+	)
+	defer span.End()
+
+	tx, err := r.updateBalance(ctx, walletID, operationType, amount, opts)
+	tracing.RecordError(span, err)
+
+	return tx, err
 }
 
-func (r *Storage) UpdateBalance(ctx context.Context, walletID uuid.UUID, operationType models.OperationType, amount int64) (models.Transactions, error) {
+func (r *Storage) updateBalance(ctx context.Context, walletID uuid.UUID, operationType models.OperationType, amount int64, opts models.TxOptions) (models.Transactions, error) {
 	const op = "storage.UpdateBalance"
 
-	locked, err := r.Redis.TryLockWallet(ctx, walletID)
+	revenueIDs, err := r.DB.FeeRevenueWallets(ctx, []models.BatchItem{{WalletID: walletID, OperationType: operationType, Amount: amount}})
 	if err != nil {
 		return models.Transactions{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	if !locked {
-		return models.Transactions{}, herrors.ErrLockedWallet
+	unlock, err := r.lockWallets(ctx, append(revenueIDs, walletID)...)
+	if err != nil {
+		return models.Transactions{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	defer r.Redis.UnlockWallet(ctx, walletID)
+	defer unlock()
 
-	tx, err := r.DB.UpdateBalance(ctx, walletID, operationType, amount)
+	tx, err := r.DB.UpdateBalance(ctx, walletID, operationType, amount, opts)
 	if err != nil {
+		countRejection(string(operationType), err)
 		return models.Transactions{}, fmt.Errorf("%s: %w", op, err)
 	}
 
+	// Повтор по ключу идемпотентности баланс не меняет: ни метрики, ни кэш не трогаем
+	if tx.Replayed {
+		return tx, nil
+	}
+
+	countOperation(tx)
+
 	r.Redis.InvalidateCache(ctx, walletID)
+	r.invalidateRevenueCache(ctx, tx.Fee)
+
+	return tx, nil
+}
+
+func (r *Storage) Transfer(ctx context.Context, fromID, toID uuid.UUID, amount int64) (models.Transfer, error) {
+	const op = "storage.Transfer"
+
+	if fromID == toID {
+		return models.Transfer{}, fmt.Errorf("%s: %w", op, herrors.ErrSameWallet)
+	}
+
+	revenueIDs, err := r.DB.FeeRevenueWallets(ctx, []models.BatchItem{{WalletID: fromID, ToWalletID: toID, OperationType: models.TRANSFER, Amount: amount}})
+	if err != nil {
+		return models.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	unlock, err := r.lockWallets(ctx, append(revenueIDs, fromID, toID)...)
+	if err != nil {
+		return models.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer unlock()
+
+	transfer, err := r.DB.Transfer(ctx, fromID, toID, amount)
+	if err != nil {
+		countRejection(string(models.TRANSFER), err)
+		return models.Transfer{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	r.Redis.InvalidateCache(ctx, fromID)
+	r.Redis.InvalidateCache(ctx, toID)
+	r.invalidateRevenueCache(ctx, transfer.Fee)
+
+	return transfer, nil
+}
+
+// ApplyBatch держит блокировки всех кошельков пакета, пока он проводится одной транзакцией
+func (r *Storage) ApplyBatch(ctx context.Context, items []models.BatchItem) ([]models.BatchItemResult, error) {
+	const op = "storage.ApplyBatch"
+
+	revenueIDs, err := r.DB.FeeRevenueWallets(ctx, items)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	walletIDs := models.BatchWalletIDs(items)
+
+	unlock, err := r.lockWallets(ctx, append(revenueIDs, walletIDs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer unlock()
+
+	results, err := r.DB.ApplyBatch(ctx, items)
+	if err != nil {
+		var itemErr *herrors.BatchItemError
+		if errors.As(err, &itemErr) {
+			countRejection(string(items[itemErr.Index].OperationType), err)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, id := range walletIDs {
+		r.Redis.InvalidateCache(ctx, id)
+	}
+
+	for _, result := range results {
+		// Переводы пакета, как и одиночные, в метрики операций не входят
+		if items[result.Index].OperationType != models.TRANSFER {
+			countOperation(result.Transactions[0])
+		}
+
+		r.invalidateRevenueCache(ctx, result.Fee)
+	}
+
+	return results, nil
+}
+
+// invalidateRevenueCache сбрасывает кэш кошелька доходов, если операция взяла комиссию.
+// Кошелек доходов заблокирован вместе с кошельками операции, см. DB.FeeRevenueWallets
+func (r *Storage) invalidateRevenueCache(ctx context.Context, fee *models.Fee) {
+	if fee == nil {
+		return
+	}
+
+	r.Redis.InvalidateCache(ctx, fee.RevenueWalletID)
+}
+
+func (r *Storage) CreateHold(ctx context.Context, walletID uuid.UUID, amount int64, ttl time.Duration) (models.Hold, error) {
+	const op = "storage.CreateHold"
+
+	unlock, err := r.lockWallets(ctx, walletID)
+	if err != nil {
+		return models.Hold{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer unlock()
+
+	hold, err := r.DB.CreateHold(ctx, walletID, amount, ttl)
+	if err != nil {
+		countRejection(holdOperation, err)
+		return models.Hold{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	r.Redis.InvalidateCache(ctx, walletID)
+
+	return hold, nil
+}
+
+func (r *Storage) CaptureHold(ctx context.Context, holdID uuid.UUID, amount int64) (models.Hold, models.Transactions, error) {
+	const op = "storage.CaptureHold"
+
+	hold, err := r.DB.GetHold(ctx, holdID)
+	if err != nil {
+		return models.Hold{}, models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	unlock, err := r.lockWallets(ctx, hold.WalletID)
+	if err != nil {
+		return models.Hold{}, models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer unlock()
+
+	hold, tx, err := r.DB.CaptureHold(ctx, holdID, amount)
+	if err != nil {
+		countRejection(string(models.HOLD_CAPTURE), err)
+		return models.Hold{}, models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	countOperation(tx)
+
+	r.Redis.InvalidateCache(ctx, hold.WalletID)
+
+	return hold, tx, nil
+}
+
+func (r *Storage) VoidHold(ctx context.Context, holdID uuid.UUID) (models.Hold, error) {
+	const op = "storage.VoidHold"
+
+	hold, err := r.DB.GetHold(ctx, holdID)
+	if err != nil {
+		return models.Hold{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	unlock, err := r.lockWallets(ctx, hold.WalletID)
+	if err != nil {
+		return models.Hold{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer unlock()
+
+	hold, err = r.DB.VoidHold(ctx, holdID)
+	if err != nil {
+		return models.Hold{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	r.Redis.InvalidateCache(ctx, hold.WalletID)
+
+	return hold, nil
+}
+
+// ExpireHolds закрывает просроченные холды по одному кошельку за раз под его блокировкой,
+// как и остальные операции над балансом, и сбрасывает кэш затронутых кошельков.
+// Занятые кошельки пропускаются до следующего запуска. Возвращает число затронутых кошельков.
+func (r *Storage) ExpireHolds(ctx context.Context) (int, error) {
+	const op = "storage.ExpireHolds"
+
+	walletIDs, err := r.DB.ListExpiredHoldWallets(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	released := 0
+	for _, id := range walletIDs {
+		expired, err := r.expireWalletHolds(ctx, id)
+		if err != nil {
+			if errors.Is(err, herrors.ErrLockedWallet) {
+				continue
+			}
+			return released, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if expired > 0 {
+			released++
+		}
+	}
+
+	return released, nil
+}
+
+func (r *Storage) expireWalletHolds(ctx context.Context, walletID uuid.UUID) (int, error) {
+	unlock, err := r.lockWallets(ctx, walletID)
+	if err != nil {
+		return 0, err
+	}
+
+	defer unlock()
+
+	expired, err := r.DB.ExpireWalletHolds(ctx, walletID)
+	if err != nil {
+		return 0, err
+	}
+
+	if expired > 0 {
+		r.Redis.InvalidateCache(ctx, walletID)
+	}
+
+	return expired, nil
+}
+
+func (r *Storage) ReverseTransaction(ctx context.Context, txID uuid.UUID, amount int64) (models.Transactions, error) {
+	const op = "storage.ReverseTransaction"
+
+	original, err := r.DB.GetTransaction(ctx, txID)
+	if err != nil {
+		return models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	unlock, err := r.lockWallets(ctx, original.WalletID)
+	if err != nil {
+		return models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer unlock()
+
+	tx, err := r.DB.ReverseTransaction(ctx, txID, amount)
+	if err != nil {
+		return models.Transactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	r.Redis.InvalidateCache(ctx, original.WalletID)
 
 	return tx, nil
 }
+
+func (r *Storage) SetWalletStatus(ctx context.Context, walletID uuid.UUID, status models.WalletStatus, changedBy, reason string) (models.WalletStatusChange, error) {
+	const op = "storage.SetWalletStatus"
+
+	unlock, err := r.lockWallets(ctx, walletID)
+	if err != nil {
+		return models.WalletStatusChange{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer unlock()
+
+	change, err := r.DB.SetWalletStatus(ctx, walletID, status, changedBy, reason)
+	if err != nil {
+		return models.WalletStatusChange{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	r.Redis.InvalidateCache(ctx, walletID)
+
+	return change, nil
+}
+
+func (r *Storage) SetOverdraftLimit(ctx context.Context, walletID uuid.UUID, limit int64) (models.Wallet, error) {
+	const op = "storage.SetOverdraftLimit"
+
+	unlock, err := r.lockWallets(ctx, walletID)
+	if err != nil {
+		return models.Wallet{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer unlock()
+
+	wallet, err := r.DB.SetOverdraftLimit(ctx, walletID, limit)
+	if err != nil {
+		return models.Wallet{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	r.Redis.InvalidateCache(ctx, walletID)
+
+	return wallet, nil
+}
+
+func (r *Storage) PayInterest(ctx context.Context, walletID uuid.UUID, period time.Time) (models.InterestPayout, error) {
+	const op = "storage.PayInterest"
+
+	unlock, err := r.lockWallets(ctx, walletID)
+	if err != nil {
+		return models.InterestPayout{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer unlock()
+
+	payout, err := r.DB.PayInterest(ctx, walletID, period)
+	if err != nil {
+		return models.InterestPayout{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	r.Redis.InvalidateCache(ctx, walletID)
+
+	return payout, nil
+}
+
+// countOperation учитывает в метриках проведенную операцию и ее сумму
+func countOperation(tx models.Transactions) {
+	metrics.Operations.WithLabelValues(string(tx.OperationType), tx.Currency).Inc()
+	metrics.OperationAmount.WithLabelValues(string(tx.OperationType), tx.Currency).Add(float64(tx.Amount))
+}
+
+// countRejection учитывает в метриках отказ в операции из-за нехватки средств
+func countRejection(operation string, err error) {
+	if errors.Is(err, herrors.ErrInsufficientFunds) {
+		metrics.InsufficientFunds.WithLabelValues(operation).Inc()
+	}
+}
+
+// lockWalletsTTL - время жизни блокировок операции над count кошельками. Блокировки берутся
+// по одной, а транзакция блокирует каждую строку, поэтому пакету нужно больше времени, чем одной операции:
+// первые блокировки не должны истечь до коммита.
+func lockWalletsTTL(count int) time.Duration {
+	return lockTTL + time.Duration(count)*lockTTLPerWallet
+}
+
+// lockWallets берет блокировки на все кошельки в порядке models.SortWalletIDs.
+// Если хотя бы одну блокировку взять не удалось, уже взятые снимаются. Пока берутся следующие
+// блокировки, первые могут истечь в ожидании занятого кошелька, поэтому после взятия всех
+// они продлеваются: истекшая блокировка, которую мог взять другой запрос, отменяет операцию.
+func (r *Storage) lockWallets(ctx context.Context, walletIDs ...uuid.UUID) (func(), error) {
+	type lock struct {
+		walletID uuid.UUID
+		token    string
+	}
+
+	var locked []lock
+
+	unlock := func() {
+		for i := len(locked) - 1; i >= 0; i-- {
+			r.Redis.UnlockWallet(ctx, locked[i].walletID, locked[i].token)
+		}
+	}
+
+	ids := models.SortWalletIDs(walletIDs...)
+	ttl := lockWalletsTTL(len(ids))
+
+	for _, id := range ids {
+		token, ok, err := r.Redis.TryLockWallet(ctx, id, ttl)
+		if err != nil {
+			unlock()
+			return nil, err
+		}
+
+		if !ok {
+			unlock()
+			return nil, herrors.ErrLockedWallet
+		}
+
+		locked = append(locked, lock{walletID: id, token: token})
+	}
+
+	// Последняя блокировка только что взята с полным ttl
+	for _, l := range locked[:max(len(locked)-1, 0)] {
+		ok, err := r.Redis.ExtendWalletLock(ctx, l.walletID, l.token, ttl)
+		if err != nil {
+			unlock()
+			return nil, err
+		}
+
+		if !ok {
+			unlock()
+			return nil, herrors.ErrLockedWallet
+		}
+	}
+
+	return unlock, nil
+}
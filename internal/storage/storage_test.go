@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+	"wallets/internal/herrors"
+	"wallets/internal/models"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLock struct {
+	token   string
+	expires time.Time
+}
+
+// fakeCache держит блокировки в памяти с собственными часами: каждое взятие блокировки
+// сдвигает часы на step, а истекшую блокировку сразу забирает другой запрос
+type fakeCache struct {
+	now   time.Time
+	step  time.Duration
+	seq   int
+	locks map[uuid.UUID]fakeLock
+}
+
+func newFakeCache(step time.Duration) *fakeCache {
+	return &fakeCache{now: time.Unix(0, 0), step: step, locks: map[uuid.UUID]fakeLock{}}
+}
+
+func (f *fakeCache) tick() {
+	f.now = f.now.Add(f.step)
+
+	for id, lock := range f.locks {
+		if !f.now.Before(lock.expires) {
+			f.locks[id] = fakeLock{token: "other", expires: f.now.Add(time.Hour)}
+		}
+	}
+}
+
+func (f *fakeCache) LockWallet(ctx context.Context, walletID uuid.UUID, ttl time.Duration) (string, bool, error) {
+	if _, ok := f.locks[walletID]; ok {
+		return "", false, nil
+	}
+
+	f.seq++
+	token := strconv.Itoa(f.seq)
+	f.locks[walletID] = fakeLock{token: token, expires: f.now.Add(ttl)}
+
+	return token, true, nil
+}
+
+func (f *fakeCache) TryLockWallet(ctx context.Context, walletID uuid.UUID, ttl time.Duration) (string, bool, error) {
+	f.tick()
+	return f.LockWallet(ctx, walletID, ttl)
+}
+
+func (f *fakeCache) ExtendWalletLock(ctx context.Context, walletID uuid.UUID, token string, ttl time.Duration) (bool, error) {
+	lock, ok := f.locks[walletID]
+	if !ok || lock.token != token {
+		return false, nil
+	}
+
+	lock.expires = f.now.Add(ttl)
+	f.locks[walletID] = lock
+
+	return true, nil
+}
+
+func (f *fakeCache) UnlockWallet(ctx context.Context, walletID uuid.UUID, token string) {
+	if lock, ok := f.locks[walletID]; ok && lock.token == token {
+		delete(f.locks, walletID)
+	}
+}
+
+func (f *fakeCache) GetCachedBalance(ctx context.Context, walletID uuid.UUID) (models.Wallet, error) {
+	return models.Wallet{}, nil
+}
+
+func (f *fakeCache) SetCachedBalance(ctx context.Context, wallet models.Wallet) error {
+	return nil
+}
+
+func (f *fakeCache) InvalidateCache(ctx context.Context, walletID uuid.UUID) {}
+
+func walletIDs(t *testing.T, count int) []uuid.UUID {
+	ids := make([]uuid.UUID, count)
+	for i := range ids {
+		id, err := uuid.NewV4()
+		require.NoError(t, err)
+		ids[i] = id
+	}
+
+	return ids
+}
+
+func TestLockWalletsOutlivesLockTTL(t *testing.T) {
+	// Пакет из 1000 кошельков берет блокировки дольше lockTTL
+	cache := newFakeCache(time.Millisecond)
+	storage := NewStorage(nil, cache)
+
+	ids := walletIDs(t, 1000)
+
+	unlock, err := storage.lockWallets(context.Background(), ids...)
+	require.NoError(t, err)
+
+	for _, id := range ids {
+		lock := cache.locks[id]
+		assert.NotEqual(t, "other", lock.token)
+		assert.False(t, lock.expires.Before(cache.now.Add(lockTTL)), "lock expires before the batch transaction")
+	}
+
+	unlock()
+	assert.Empty(t, cache.locks)
+}
+
+func TestLockWalletsExpiredLockIsNotReleased(t *testing.T) {
+	ids := models.SortWalletIDs(walletIDs(t, 3)...)
+
+	// Первая блокировка истекает, пока берутся следующие, и ее забирает другой запрос
+	cache := newFakeCache(lockWalletsTTL(len(ids)) * 2 / 3)
+	storage := NewStorage(nil, cache)
+
+	_, err := storage.lockWallets(context.Background(), ids...)
+	require.ErrorIs(t, err, herrors.ErrLockedWallet)
+
+	require.Len(t, cache.locks, 1)
+	assert.Equal(t, "other", cache.locks[ids[0]].token)
+}
@@ -0,0 +1,120 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallets/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	parentTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	parentSpanID  = "00f067aa0ba902b7"
+	traceparent   = "00-" + parentTraceID + "-" + parentSpanID + "-01"
+)
+
+// setup ставит глобальный провайдер с экспортом в память и возвращает прежние настройки после теста
+func setup(t *testing.T) (*tracetest.InMemoryExporter, *sdktrace.TracerProvider) {
+	t.Helper()
+
+	provider, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+
+	_, err := New(context.Background(), config.Tracing{Exporter: ExporterNone})
+	require.NoError(t, err)
+
+	exporter := tracetest.NewInMemoryExporter()
+	sdkProvider := Install(exporter, "wallets-test", 1)
+
+	t.Cleanup(func() {
+		_ = sdkProvider.Shutdown(context.Background())
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	})
+
+	return exporter, sdkProvider
+}
+
+func TestNewUnknownExporter(t *testing.T) {
+	_, err := New(context.Background(), config.Tracing{Exporter: "jaeger"})
+	assert.Error(t, err)
+}
+
+func TestTraceparentPropagation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	exporter, provider := setup(t)
+
+	var buf bytes.Buffer
+	log := slog.New(NewLogHandler(slog.NewJSONHandler(&buf, nil)))
+
+	r := gin.New()
+	r.Use(otelgin.Middleware("wallets-test"))
+	r.GET("/wallets/:uuid", func(c *gin.Context) {
+		ctx, span := Start(c.Request.Context(), "Storage.GetBalance")
+		RecordError(span, assert.AnError)
+		span.End()
+
+		log.InfoContext(ctx, "balance requested")
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/wallets/1", nil)
+	req.Header.Set("traceparent", traceparent)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.NoError(t, provider.ForceFlush(context.Background()))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+
+	byName := map[string]tracetest.SpanStub{}
+	for _, span := range spans {
+		byName[span.Name] = span
+	}
+
+	server, ok := byName["/wallets/:uuid"]
+	require.True(t, ok)
+	assert.Equal(t, parentTraceID, server.SpanContext.TraceID().String())
+	assert.Equal(t, parentSpanID, server.Parent.SpanID().String())
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+
+	storage, ok := byName["Storage.GetBalance"]
+	require.True(t, ok)
+	assert.Equal(t, server.SpanContext.SpanID(), storage.Parent.SpanID())
+	assert.Equal(t, codes.Error, storage.Status.Code)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, parentTraceID, record["trace_id"])
+}
+
+func TestTraceID(t *testing.T) {
+	assert.Equal(t, slog.Attr{}, TraceID(context.Background()))
+
+	var buf bytes.Buffer
+	log := slog.New(NewLogHandler(slog.NewJSONHandler(&buf, nil)))
+
+	log.InfoContext(context.Background(), "no span")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.NotContains(t, record, "trace_id")
+}
@@ -0,0 +1,130 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+	"wallets/internal/models"
+)
+
+const (
+	HeaderSignature = "X-Wallets-Signature"
+	HeaderTimestamp = "X-Wallets-Timestamp"
+	HeaderEvent     = "X-Wallets-Event"
+	HeaderDelivery  = "X-Wallets-Delivery"
+
+	signaturePrefix = "sha256="
+	secretBytes     = 32
+)
+
+// NewSecret генерирует секрет подписи для новой подписки
+func NewSecret() (string, error) {
+	buf := make([]byte, secretBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
+// Sign считает подпись тела запроса: HMAC-SHA256 от "<timestamp>.<body>" в hex с префиксом sha256=.
+// Метка времени входит в подпись, чтобы получатель мог отбрасывать старые повторы.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify проверяет подпись, полученную в заголовке HeaderSignature
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// Backoff возвращает задержку перед повтором после attempt неудачных попыток: base, 2*base, 4*base... но не больше limit
+func Backoff(attempt int, base, limit time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= limit {
+			return limit
+		}
+	}
+
+	return min(delay, limit)
+}
+
+// StatusError - подписчик ответил кодом вне 2xx
+type StatusError struct {
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d", e.Code)
+}
+
+type Client struct {
+	http *http.Client
+}
+
+func NewClient(timeout time.Duration) *Client {
+	return &Client{http: &http.Client{Timeout: timeout}}
+}
+
+// Send отправляет подписанное событие. Возвращает код ответа, если ответ был получен.
+// Успешной считается доставка с кодом 2xx.
+func (c *Client) Send(ctx context.Context, task models.WebhookTask) (int, error) {
+	const op = "webhooks.Client.Send"
+
+	body, err := json.Marshal(models.WebhookPayload{
+		DeliveryID: task.Delivery.ID,
+		EventID:    task.Event.ID,
+		Type:       task.Event.Type,
+		WalletID:   task.Event.WalletID,
+		Data:       task.Event.Payload,
+		CreatedAt:  task.Event.CreatedAt,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, task.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	timestamp := time.Now().Unix()
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, task.Event.Type)
+	req.Header.Set(HeaderDelivery, task.Delivery.ID.String())
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(task.Secret, timestamp, body))
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer res.Body.Close()
+
+	// Дочитываем тело, чтобы соединение вернулось в пул
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, fmt.Errorf("%s: %w", op, &StatusError{Code: res.StatusCode})
+	}
+
+	return res.StatusCode, nil
+}
@@ -0,0 +1,106 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+	"wallets/internal/models"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientSend(t *testing.T) {
+	deliveryID, _ := uuid.NewV4()
+	walletID, _ := uuid.NewV4()
+
+	task := models.WebhookTask{
+		Delivery: models.WebhookDelivery{ID: deliveryID},
+		Secret:   "secret",
+		Event: models.OutboxEvent{
+			ID:       7,
+			Type:     models.EventBalanceChanged,
+			WalletID: walletID,
+			Payload:  json.RawMessage(`{"amount":100}`),
+		},
+	}
+
+	tests := []struct {
+		name           string
+		receiverStatus int
+		expectedErr    bool
+	}{
+		{
+			name:           "Success",
+			receiverStatus: http.StatusOK,
+		},
+		{
+			name:           "no content",
+			receiverStatus: http.StatusNoContent,
+		},
+		{
+			name:           "receiver error",
+			receiverStatus: http.StatusInternalServerError,
+			expectedErr:    true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var received *http.Request
+			var body []byte
+
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received = r
+				body, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tc.receiverStatus)
+			}))
+			defer receiver.Close()
+
+			task.URL = receiver.URL
+
+			code, err := NewClient(time.Second).Send(context.Background(), task)
+
+			assert.Equal(t, tc.receiverStatus, code)
+			if tc.expectedErr {
+				var statusErr *StatusError
+				require.True(t, errors.As(err, &statusErr))
+				assert.Equal(t, tc.receiverStatus, statusErr.Code)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			require.NotNil(t, received)
+			assert.Equal(t, models.EventBalanceChanged, received.Header.Get(HeaderEvent))
+			assert.Equal(t, deliveryID.String(), received.Header.Get(HeaderDelivery))
+
+			timestamp, err := strconv.ParseInt(received.Header.Get(HeaderTimestamp), 10, 64)
+			require.NoError(t, err)
+			assert.True(t, Verify("secret", timestamp, body, received.Header.Get(HeaderSignature)))
+			assert.False(t, Verify("other", timestamp, body, received.Header.Get(HeaderSignature)))
+
+			var payload models.WebhookPayload
+			require.NoError(t, json.Unmarshal(body, &payload))
+			assert.Equal(t, int64(7), payload.EventID)
+			assert.Equal(t, walletID, payload.WalletID)
+			assert.JSONEq(t, `{"amount":100}`, string(payload.Data))
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	base, limit := 10*time.Second, time.Minute
+
+	assert.Equal(t, 10*time.Second, Backoff(1, base, limit))
+	assert.Equal(t, 20*time.Second, Backoff(2, base, limit))
+	assert.Equal(t, 40*time.Second, Backoff(3, base, limit))
+	assert.Equal(t, time.Minute, Backoff(4, base, limit))
+	assert.Equal(t, time.Minute, Backoff(100, base, limit))
+}
@@ -0,0 +1,14 @@
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM transactions WHERE operation_type IN ('TRANSFER_IN', 'TRANSFER_OUT')) THEN
+        RAISE EXCEPTION 'cannot roll back: transactions contain TRANSFER_IN or TRANSFER_OUT operations';
+    END IF;
+END $$;
+
+DROP INDEX IF EXISTS transactions_transfer_id_idx;
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_operation_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_operation_type_check
+    CHECK (operation_type IN ('DEPOSIT', 'WITHDRAW'));
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS transfer_id;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS transactions_idempotency_key_idx;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS request_hash;
+ALTER TABLE transactions DROP COLUMN IF EXISTS idempotency_key;
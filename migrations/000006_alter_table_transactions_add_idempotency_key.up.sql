@@ -0,0 +1,4 @@
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS idempotency_key TEXT;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS request_hash TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS transactions_idempotency_key_idx ON transactions (idempotency_key) WHERE idempotency_key IS NOT NULL;
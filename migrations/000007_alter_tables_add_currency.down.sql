@@ -0,0 +1,2 @@
+ALTER TABLE transactions DROP COLUMN IF EXISTS currency;
+ALTER TABLE wallets DROP COLUMN IF EXISTS currency;
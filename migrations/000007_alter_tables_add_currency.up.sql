@@ -0,0 +1,5 @@
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'RUB';
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS currency CHAR(3);
+UPDATE transactions t SET currency = w.currency FROM wallets w WHERE w.id = t.wallet_id;
+ALTER TABLE transactions ALTER COLUMN currency SET NOT NULL;
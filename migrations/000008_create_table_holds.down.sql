@@ -0,0 +1,17 @@
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM transactions WHERE operation_type = 'HOLD_CAPTURE') THEN
+        RAISE EXCEPTION 'cannot roll back: transactions contain HOLD_CAPTURE operations';
+    END IF;
+END $$;
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_operation_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_operation_type_check
+    CHECK (operation_type IN ('DEPOSIT', 'WITHDRAW', 'TRANSFER_IN', 'TRANSFER_OUT'));
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS hold_id;
+
+DROP TABLE IF EXISTS holds;
+
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS wallets_available_check;
+ALTER TABLE wallets DROP COLUMN IF EXISTS held;
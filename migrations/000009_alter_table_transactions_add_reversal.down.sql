@@ -0,0 +1,13 @@
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM transactions WHERE operation_type IN ('REVERSAL_DEBIT', 'REVERSAL_CREDIT')) THEN
+        RAISE EXCEPTION 'cannot roll back: transactions contain REVERSAL_DEBIT or REVERSAL_CREDIT operations';
+    END IF;
+END $$;
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_operation_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_operation_type_check
+    CHECK (operation_type IN ('DEPOSIT', 'WITHDRAW', 'TRANSFER_IN', 'TRANSFER_OUT', 'HOLD_CAPTURE'));
+
+DROP INDEX IF EXISTS transactions_reversal_of_idx;
+ALTER TABLE transactions DROP COLUMN IF EXISTS reversal_of;
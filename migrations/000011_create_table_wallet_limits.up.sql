@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS wallet_limits (
+    wallet_id UUID PRIMARY KEY REFERENCES wallets(id) ON DELETE CASCADE,
+    max_withdrawal BIGINT CHECK (max_withdrawal >= 0),
+    daily_withdrawal BIGINT CHECK (daily_withdrawal >= 0),
+    monthly_withdrawal BIGINT CHECK (monthly_withdrawal >= 0),
+    max_balance BIGINT CHECK (max_balance >= 0),
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
@@ -0,0 +1,5 @@
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS wallets_available_check;
+ALTER TABLE wallets ADD CONSTRAINT wallets_available_check CHECK (balance - held >= 0);
+ALTER TABLE wallets ADD CONSTRAINT wallets_balance_check CHECK (balance >= 0);
+
+ALTER TABLE wallets DROP COLUMN IF EXISTS overdraft_limit;
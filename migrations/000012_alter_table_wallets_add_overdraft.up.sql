@@ -0,0 +1,5 @@
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS overdraft_limit BIGINT NOT NULL DEFAULT 0 CHECK (overdraft_limit >= 0);
+
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS wallets_balance_check;
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS wallets_available_check;
+ALTER TABLE wallets ADD CONSTRAINT wallets_available_check CHECK (balance - held + overdraft_limit >= 0);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS outbox_unpublished_idx;
+DROP TABLE IF EXISTS outbox;
@@ -0,0 +1,5 @@
+DELETE FROM transactions WHERE id IN (SELECT transaction_id FROM ledger_opening_transactions);
+
+DROP TABLE IF EXISTS ledger_opening_transactions;
+DROP TABLE IF EXISTS ledger_entries;
+DROP TABLE IF EXISTS ledger_accounts;
@@ -0,0 +1,81 @@
+CREATE TABLE IF NOT EXISTS ledger_accounts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    wallet_id UUID UNIQUE REFERENCES wallets(id) ON DELETE CASCADE,
+    code TEXT,
+    currency CHAR(3) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    CHECK ((wallet_id IS NULL) <> (code IS NULL)),
+    UNIQUE (code, currency)
+);
+
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id UUID NOT NULL UNIQUE REFERENCES transactions(id) ON DELETE CASCADE,
+    debit_account_id UUID NOT NULL REFERENCES ledger_accounts(id),
+    credit_account_id UUID NOT NULL REFERENCES ledger_accounts(id),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency CHAR(3) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    CHECK (debit_account_id <> credit_account_id)
+);
+
+-- Транзакции начального баланса, добавленные этой миграцией, а не клиентом
+CREATE TABLE IF NOT EXISTS ledger_opening_transactions (
+    transaction_id UUID PRIMARY KEY REFERENCES transactions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS ledger_entries_debit_account_idx ON ledger_entries (debit_account_id);
+CREATE INDEX IF NOT EXISTS ledger_entries_credit_account_idx ON ledger_entries (credit_account_id);
+
+-- Счет кошелька имеет тот же id, что и сам кошелек
+INSERT INTO ledger_accounts (id, wallet_id, currency)
+SELECT id, id, currency FROM wallets
+ON CONFLICT DO NOTHING;
+
+INSERT INTO ledger_accounts (code, currency)
+SELECT c.code, w.currency
+FROM (VALUES ('external_funding'), ('payouts'), ('transfers_clearing')) AS c(code)
+CROSS JOIN (SELECT DISTINCT currency FROM wallets) AS w
+ON CONFLICT DO NOTHING;
+
+-- Начальный баланс кошельков, созданных без транзакции пополнения, оформляем отдельной транзакцией
+-- перед первой операцией кошелька, чтобы баланс сходился с суммой транзакций. Такие транзакции
+-- помечаются в ledger_opening_transactions, откат миграции их удаляет
+WITH opening_transactions AS (
+    INSERT INTO transactions (wallet_id, operation_type, amount, currency, created_at)
+    SELECT id, CASE WHEN delta > 0 THEN 'DEPOSIT' ELSE 'WITHDRAW' END, abs(delta), currency, opened_at
+    FROM (
+        SELECT w.id, w.currency,
+            w.balance - COALESCE(SUM(CASE WHEN t.operation_type IN ('DEPOSIT', 'TRANSFER_IN', 'REVERSAL_CREDIT')
+                THEN t.amount ELSE -t.amount END), 0) AS delta,
+            COALESCE(MIN(t.created_at) - interval '1 microsecond', now()) AS opened_at
+        FROM wallets w
+        LEFT JOIN transactions t ON t.wallet_id = w.id
+        GROUP BY w.id, w.currency, w.balance
+    ) AS opening
+    WHERE delta <> 0
+    RETURNING id
+)
+INSERT INTO ledger_opening_transactions (transaction_id)
+SELECT id FROM opening_transactions;
+
+INSERT INTO ledger_entries (transaction_id, debit_account_id, credit_account_id, amount, currency, created_at)
+SELECT t.id,
+    CASE
+        WHEN t.operation_type = 'DEPOSIT' THEN ef.id
+        WHEN t.operation_type = 'REVERSAL_CREDIT' THEN po.id
+        WHEN t.operation_type = 'TRANSFER_IN' THEN tc.id
+        ELSE t.wallet_id
+    END,
+    CASE
+        WHEN t.operation_type IN ('WITHDRAW', 'HOLD_CAPTURE') THEN po.id
+        WHEN t.operation_type = 'REVERSAL_DEBIT' THEN ef.id
+        WHEN t.operation_type = 'TRANSFER_OUT' THEN tc.id
+        ELSE t.wallet_id
+    END,
+    t.amount, t.currency, COALESCE(t.created_at, now())
+FROM transactions t
+JOIN ledger_accounts ef ON ef.code = 'external_funding' AND ef.currency = t.currency
+JOIN ledger_accounts po ON po.code = 'payouts' AND po.currency = t.currency
+JOIN ledger_accounts tc ON tc.code = 'transfers_clearing' AND tc.currency = t.currency
+ON CONFLICT (transaction_id) DO NOTHING;
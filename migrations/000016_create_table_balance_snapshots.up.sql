@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS balance_snapshots (
+    wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    taken_at TIMESTAMP NOT NULL,
+    balance BIGINT NOT NULL,
+    PRIMARY KEY (wallet_id, taken_at)
+);
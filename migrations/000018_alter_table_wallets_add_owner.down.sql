@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS wallets_owner_id_idx;
+
+ALTER TABLE wallets DROP COLUMN IF EXISTS owner_id;
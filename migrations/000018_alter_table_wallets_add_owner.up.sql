@@ -0,0 +1,3 @@
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS owner_id TEXT;
+
+CREATE INDEX IF NOT EXISTS wallets_owner_id_idx ON wallets (owner_id) WHERE owner_id IS NOT NULL;
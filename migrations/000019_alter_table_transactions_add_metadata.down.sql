@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS transactions_wallet_external_reference_idx;
+
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS description,
+    DROP COLUMN IF EXISTS metadata,
+    DROP COLUMN IF EXISTS external_reference;
@@ -0,0 +1,6 @@
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS description TEXT,
+    ADD COLUMN IF NOT EXISTS metadata JSONB CHECK (jsonb_typeof(metadata) = 'object'),
+    ADD COLUMN IF NOT EXISTS external_reference TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS transactions_wallet_external_reference_idx ON transactions (wallet_id, external_reference) WHERE external_reference IS NOT NULL;
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS schedules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    to_wallet_id UUID REFERENCES wallets(id) ON DELETE CASCADE,
+    operation_type TEXT NOT NULL CHECK (operation_type IN ('DEPOSIT', 'WITHDRAW', 'TRANSFER')),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    description TEXT,
+    recurrence TEXT CHECK (recurrence IN ('DAILY', 'WEEKLY', 'MONTHLY')),
+    start_at TIMESTAMP NOT NULL,
+    next_run_at TIMESTAMP NOT NULL,
+    occurrence INT NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'ACTIVE' CHECK (status IN ('ACTIVE', 'PAUSED', 'CANCELLED', 'COMPLETED')),
+    locked_until TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    CHECK ((operation_type = 'TRANSFER') = (to_wallet_id IS NOT NULL))
+);
+
+CREATE INDEX IF NOT EXISTS schedules_due_idx ON schedules (next_run_at) WHERE status = 'ACTIVE';
+CREATE INDEX IF NOT EXISTS schedules_wallet_id_idx ON schedules (wallet_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS schedule_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    schedule_id UUID NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    scheduled_for TIMESTAMP NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('SUCCEEDED', 'FAILED')),
+    transaction_id UUID REFERENCES transactions(id),
+    transfer_id UUID,
+    error TEXT,
+    executed_at TIMESTAMP NOT NULL DEFAULT now(),
+    UNIQUE (schedule_id, scheduled_for)
+);
@@ -0,0 +1,16 @@
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM transactions WHERE operation_type IN ('FEE', 'FEE_INCOME')) THEN
+        RAISE EXCEPTION 'cannot roll back: transactions contain FEE or FEE_INCOME operations';
+    END IF;
+END $$;
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_operation_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_operation_type_check
+    CHECK (operation_type IN ('DEPOSIT', 'WITHDRAW', 'TRANSFER_IN', 'TRANSFER_OUT', 'HOLD_CAPTURE',
+        'REVERSAL_DEBIT', 'REVERSAL_CREDIT'));
+
+DROP INDEX IF EXISTS transactions_fee_of_idx;
+ALTER TABLE transactions DROP COLUMN IF EXISTS fee_of;
+
+ALTER TABLE wallets DROP COLUMN IF EXISTS type;
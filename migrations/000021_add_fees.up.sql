@@ -0,0 +1,10 @@
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS type TEXT NOT NULL DEFAULT 'standard';
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS fee_of UUID REFERENCES transactions(id);
+
+CREATE INDEX IF NOT EXISTS transactions_fee_of_idx ON transactions (fee_of) WHERE fee_of IS NOT NULL;
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_operation_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_operation_type_check
+    CHECK (operation_type IN ('DEPOSIT', 'WITHDRAW', 'TRANSFER_IN', 'TRANSFER_OUT', 'HOLD_CAPTURE',
+        'REVERSAL_DEBIT', 'REVERSAL_CREDIT', 'FEE', 'FEE_INCOME'));
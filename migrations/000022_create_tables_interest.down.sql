@@ -0,0 +1,14 @@
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM transactions WHERE operation_type = 'INTEREST') THEN
+        RAISE EXCEPTION 'cannot roll back: transactions contain INTEREST operations';
+    END IF;
+END $$;
+
+DROP TABLE IF EXISTS interest_accruals;
+DROP TABLE IF EXISTS interest_payouts;
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_operation_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_operation_type_check
+    CHECK (operation_type IN ('DEPOSIT', 'WITHDRAW', 'TRANSFER_IN', 'TRANSFER_OUT', 'HOLD_CAPTURE',
+        'REVERSAL_DEBIT', 'REVERSAL_CREDIT', 'FEE', 'FEE_INCOME'));
@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS interest_payouts (
+    id UUID PRIMARY KEY,
+    wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    period DATE NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount >= 0),
+    currency TEXT NOT NULL,
+    transaction_id UUID REFERENCES transactions(id),
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS interest_payouts_wallet_id_idx ON interest_payouts (wallet_id, period DESC);
+
+CREATE TABLE IF NOT EXISTS interest_accruals (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    accrual_date DATE NOT NULL,
+    balance BIGINT NOT NULL,
+    annual_rate_bps BIGINT NOT NULL,
+    amount_micros BIGINT NOT NULL CHECK (amount_micros >= 0),
+    payout_id UUID REFERENCES interest_payouts(id),
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    UNIQUE (wallet_id, accrual_date)
+);
+
+CREATE INDEX IF NOT EXISTS interest_accruals_unpaid_idx ON interest_accruals (accrual_date) WHERE payout_id IS NULL;
+CREATE INDEX IF NOT EXISTS interest_accruals_payout_id_idx ON interest_accruals (payout_id) WHERE payout_id IS NOT NULL;
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_operation_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_operation_type_check
+    CHECK (operation_type IN ('DEPOSIT', 'WITHDRAW', 'TRANSFER_IN', 'TRANSFER_OUT', 'HOLD_CAPTURE',
+        'REVERSAL_DEBIT', 'REVERSAL_CREDIT', 'FEE', 'FEE_INCOME', 'INTEREST'));
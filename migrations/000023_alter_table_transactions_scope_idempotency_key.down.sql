@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS transactions_wallet_idempotency_key_idx;
+
+CREATE UNIQUE INDEX IF NOT EXISTS transactions_idempotency_key_idx ON transactions (idempotency_key) WHERE idempotency_key IS NOT NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE interest_payouts DROP CONSTRAINT IF EXISTS interest_payouts_wallet_id_period_key;
+
+CREATE INDEX IF NOT EXISTS interest_payouts_wallet_id_idx ON interest_payouts (wallet_id, period DESC);
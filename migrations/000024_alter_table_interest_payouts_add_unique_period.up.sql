@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS interest_payouts_wallet_id_idx;
+
+ALTER TABLE interest_payouts ADD CONSTRAINT interest_payouts_wallet_id_period_key UNIQUE (wallet_id, period);
@@ -0,0 +1,2 @@
+ALTER TABLE interest_payouts DROP COLUMN IF EXISTS error;
+ALTER TABLE interest_payouts DROP COLUMN IF EXISTS status;
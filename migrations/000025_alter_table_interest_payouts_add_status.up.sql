@@ -0,0 +1,3 @@
+ALTER TABLE interest_payouts ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'PAID'
+    CHECK (status IN ('PAID', 'REJECTED'));
+ALTER TABLE interest_payouts ADD COLUMN IF NOT EXISTS error TEXT;
@@ -0,0 +1,2 @@
+UPDATE transactions SET description = 'opening balance (ledger migration)'
+WHERE id IN (SELECT transaction_id FROM ledger_opening_transactions) AND description IS NULL;